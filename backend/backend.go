@@ -0,0 +1,439 @@
+// Package backend implements a reference core.Backend. The messages and the committed seals
+// are signed with ed25519 keys, the proposals are hashed with Keccak-256,
+// and the finalized blocks are inserted into a pluggable block store.
+// Optionally, the seals are signed with BLS keys, so they can be aggregated
+// into the aggregated committed seals and the compact certificates
+package backend
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Hydra-Chain/go-ibft/backend/bls"
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+	"github.com/Hydra-Chain/go-ibft/proposer"
+)
+
+var (
+	// ErrInvalidKey is returned when the private key of the validator is not an ed25519 key
+	ErrInvalidKey = errors.New("invalid private key")
+
+	// ErrMissingValidators is returned when the validator backend is not set
+	ErrMissingValidators = errors.New("missing validator backend")
+
+	// ErrMissingStore is returned when the block store is not set
+	ErrMissingStore = errors.New("missing block store")
+
+	// ErrMissingBLSPublicKeys is returned when the BLS key is set without the BLS public keys of the validators
+	ErrMissingBLSPublicKeys = errors.New("missing BLS public keys")
+
+	// ErrInvalidProofOfPossession is returned when the proof of possession of a BLS public key
+	// is missing or invalid
+	ErrInvalidProofOfPossession = errors.New("invalid BLS proof of possession")
+
+	// ErrAggregationDisabled is returned when the seals are aggregated without the BLS key set
+	ErrAggregationDisabled = errors.New("seal aggregation is disabled")
+)
+
+// Config is the configuration of the backend
+type Config struct {
+	// Key is the private key of the validator. The validators
+	// are identified by their public keys
+	Key ed25519.PrivateKey
+
+	// Validators returns the voting powers of the validators at each height,
+	// keyed by their public keys
+	Validators core.ValidatorBackend
+
+	// Store is the block store the finalized blocks are inserted into
+	Store BlockStore
+
+	// Selector selects the proposer for each height and round.
+	// The validators are selected in turns if it is not set
+	Selector proposer.Selector
+
+	// PayloadFn builds the payload of the blocks proposed by the validator.
+	// The proposed blocks are empty if it is not set
+	PayloadFn func(height uint64) []byte
+
+	// EquivocationFn is called with the evidence of the equivocating validators, if set
+	EquivocationFn func(evidence *messages.Evidence)
+
+	// BLSKey is the BLS key of the validator. If it is set, the committed seals are BLS signatures,
+	// and the messages include the prepare and the round change seals, so the seals can be aggregated
+	BLSKey *bls.SecretKey
+
+	// BLSPublicKeys maps the public keys of the validators on their BLS public keys
+	BLSPublicKeys map[string]*bls.PublicKey
+
+	// BLSProofsOfPossession maps the public keys of the validators on the proofs of possession
+	// of their BLS keys. Each BLS public key must have a valid proof, which rules out
+	// the rogue key attacks on the aggregated seals
+	BLSProofsOfPossession map[string][]byte
+}
+
+// validate checks if the configuration is valid
+func (c Config) validate() error {
+	if len(c.Key) != ed25519.PrivateKeySize {
+		return ErrInvalidKey
+	}
+
+	if c.Validators == nil {
+		return ErrMissingValidators
+	}
+
+	if c.Store == nil {
+		return ErrMissingStore
+	}
+
+	if c.BLSKey != nil && len(c.BLSPublicKeys) == 0 {
+		return ErrMissingBLSPublicKeys
+	}
+
+	for id, key := range c.BLSPublicKeys {
+		proof, ok := c.BLSProofsOfPossession[id]
+		if !ok || key == nil || !bls.VerifyProofOfPossession(key, proof) {
+			return fmt.Errorf("%w: validator %x", ErrInvalidProofOfPossession, id)
+		}
+	}
+
+	return nil
+}
+
+// Backend is the reference core.Backend implementation
+type Backend struct {
+	*proposer.Adapter
+
+	config Config
+
+	// id is the public key of the validator
+	id []byte
+}
+
+var (
+	_ core.Backend                    = &Backend{}
+	_ proposer.ChainBackend           = &Backend{}
+	_ core.CompactCertificateVerifier = &Backend{}
+	_ core.AggregationSwitch          = &Backend{}
+)
+
+// New creates a new Backend instance of the validator with the configured key
+func New(config Config) (*Backend, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Selector == nil {
+		config.Selector = proposer.RoundRobin{}
+	}
+
+	publicKey, _ := config.Key.Public().(ed25519.PublicKey)
+
+	b := &Backend{
+		config: config,
+		id:     publicKey,
+	}
+
+	b.Adapter = proposer.NewAdapter(b, config.Selector)
+
+	return b, nil
+}
+
+// ID returns the public key of the validator
+func (b *Backend) ID() []byte {
+	return bytes.Clone(b.id)
+}
+
+// GetVotingPowers returns the voting powers of the validators at the height
+func (b *Backend) GetVotingPowers(height uint64) (map[string]*big.Int, error) {
+	return b.config.Validators.GetVotingPowers(height)
+}
+
+// StartRound is a no-op, since the backend has no round specific state
+func (b *Backend) StartRound(_ *proto.View) error {
+	return nil
+}
+
+// BuildProposal builds the block extending the head of the store.
+// Nil is returned if the store is not at the height preceding the view
+func (b *Backend) BuildProposal(view *proto.View) []byte {
+	height, parentHash, err := b.next()
+	if err != nil || height != view.Height {
+		return nil
+	}
+
+	block := &Block{
+		Height:     height,
+		ParentHash: parentHash,
+	}
+
+	if b.config.PayloadFn != nil {
+		block.Payload = b.config.PayloadFn(height)
+	}
+
+	return block.RawProposal()
+}
+
+// InsertProposal inserts the finalized block, with the round it was finalized in,
+// its committed seals and its finalization, into the store
+func (b *Backend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
+	block, err := DecodeBlock(proposal.RawProposal)
+	if err != nil {
+		return err
+	}
+
+	finalization, err := b.Adapter.Finalize(block.Height, proposal.Round)
+	if err != nil {
+		return err
+	}
+
+	block.Round = proposal.Round
+	block.CommittedSeals = committedSeals
+	block.Finalization = finalization
+
+	return b.config.Store.Insert(block)
+}
+
+// GetFinalization returns the finalization inserted with the block at the height,
+// which the proposers of the next height are selected by
+func (b *Backend) GetFinalization(height uint64) (proposer.Finalization, error) {
+	if height == 0 {
+		return proposer.Finalization{}, nil
+	}
+
+	block := b.config.Store.Block(height)
+	if block == nil {
+		return proposer.Finalization{}, fmt.Errorf("%w: height %d", ErrUnknownBlock, height)
+	}
+
+	return block.Finalization, nil
+}
+
+// ReportEquivocation passes the evidence to the equivocation callback, if set
+func (b *Backend) ReportEquivocation(evidence *messages.Evidence) {
+	if b.config.EquivocationFn != nil {
+		b.config.EquivocationFn(evidence)
+	}
+}
+
+// IsValidProposal checks if the proposed block extends the head of the store
+func (b *Backend) IsValidProposal(rawProposal []byte) bool {
+	block, err := DecodeBlock(rawProposal)
+	if err != nil {
+		return false
+	}
+
+	height, parentHash, err := b.next()
+	if err != nil {
+		return false
+	}
+
+	return block.Height == height && bytes.Equal(block.ParentHash, parentHash)
+}
+
+// IsValidValidator checks if the message is signed by the sender,
+// and if the sender is a validator at the height of the message
+func (b *Backend) IsValidValidator(msg *proto.Message) bool {
+	if len(msg.From) != ed25519.PublicKeySize || msg.View == nil {
+		return false
+	}
+
+	votingPowers, err := b.config.Validators.GetVotingPowers(msg.View.Height)
+	if err != nil {
+		return false
+	}
+
+	if votingPower, ok := votingPowers[string(msg.From)]; !ok || votingPower.Sign() <= 0 {
+		return false
+	}
+
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		return false
+	}
+
+	return ed25519.Verify(msg.From, payload, msg.Signature)
+}
+
+// IsValidProposalHash checks if the hash matches the raw proposal and the round of the proposal
+func (b *Backend) IsValidProposalHash(proposal *proto.Proposal, hash []byte) bool {
+	if proposal == nil {
+		return false
+	}
+
+	return bytes.Equal(ProposalHash(proposal.RawProposal, proposal.Round), hash)
+}
+
+// IsValidCommittedSeal checks if the seal is the signature of the signer over the proposal hash.
+// The callers check if the signer is a validator, since the height is not known here
+func (b *Backend) IsValidCommittedSeal(proposalHash []byte, committedSeal *messages.CommittedSeal) bool {
+	if len(committedSeal.Signer) != ed25519.PublicKeySize {
+		return false
+	}
+
+	if b.config.BLSKey != nil {
+		return b.IsValidAggregatedSeal(proposalHash, [][]byte{committedSeal.Signer}, committedSeal.Signature)
+	}
+
+	return ed25519.Verify(committedSeal.Signer, proposalHash, committedSeal.Signature)
+}
+
+// AggregationEnabled checks if the seals are signed with the BLS key, so they can be aggregated
+func (b *Backend) AggregationEnabled() bool {
+	return b.config.BLSKey != nil
+}
+
+// AggregateSeals aggregates the BLS seals into a single signature
+func (b *Backend) AggregateSeals(seals [][]byte) ([]byte, error) {
+	if b.config.BLSKey == nil {
+		return nil, ErrAggregationDisabled
+	}
+
+	return bls.Aggregate(seals)
+}
+
+// IsValidAggregatedSeal checks if the signature is aggregated from the BLS seals
+// of all the signers for the digest. The signers are identified by their ed25519 public keys
+func (b *Backend) IsValidAggregatedSeal(digest []byte, signers [][]byte, signature []byte) bool {
+	if b.config.BLSKey == nil {
+		return false
+	}
+
+	keys := make([]*bls.PublicKey, 0, len(signers))
+
+	for _, signer := range signers {
+		key, ok := b.config.BLSPublicKeys[string(signer)]
+		if !ok {
+			return false
+		}
+
+		keys = append(keys, key)
+	}
+
+	return bls.FastAggregateVerify(keys, digest, signature)
+}
+
+// BuildPrePrepareMessage builds a signed PREPREPARE message for the raw proposal
+func (b *Backend) BuildPrePrepareMessage(
+	rawProposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	proposal := &proto.Proposal{
+		RawProposal: rawProposal,
+		Round:       view.Round,
+	}
+
+	return b.sign(&proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     proposal,
+				ProposalHash: ProposalHash(rawProposal, view.Round),
+				Certificate:  certificate,
+				PrepareSeal:  b.seal(core.PrepareDigest(view, ProposalHash(rawProposal, view.Round))),
+			},
+		},
+	})
+}
+
+// BuildPrepareMessage builds a signed PREPARE message for the proposal hash
+func (b *Backend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return b.sign(&proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPARE,
+		Payload: &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: proposalHash,
+				PrepareSeal:  b.seal(core.PrepareDigest(view, proposalHash)),
+			},
+		},
+	})
+}
+
+// BuildCommitMessage builds a signed COMMIT message, with the committed seal
+// of the validator, which is its signature over the proposal hash
+func (b *Backend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	committedSeal := b.seal(proposalHash)
+	if committedSeal == nil {
+		committedSeal = ed25519.Sign(b.config.Key, proposalHash)
+	}
+
+	return b.sign(&proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_COMMIT,
+		Payload: &proto.Message_CommitData{
+			CommitData: &proto.CommitMessage{
+				ProposalHash:  proposalHash,
+				CommittedSeal: committedSeal,
+			},
+		},
+	})
+}
+
+// BuildRoundChangeMessage builds a signed ROUND_CHANGE message
+// with the latest prepared proposal and certificate
+func (b *Backend) BuildRoundChangeMessage(
+	proposal *proto.Proposal,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	return b.sign(&proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_ROUND_CHANGE,
+		Payload: &proto.Message_RoundChangeData{
+			RoundChangeData: &proto.RoundChangeMessage{
+				LastPreparedProposal:      proposal,
+				LatestPreparedCertificate: certificate,
+				RoundChangeSeal:           b.seal(core.RoundChangeDigest(view, certificate)),
+			},
+		},
+	})
+}
+
+// sign signs the payload of the message, without the signature, with the key of the validator.
+// Nil is returned if the message can't be encoded
+func (b *Backend) sign(msg *proto.Message) *proto.Message {
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		return nil
+	}
+
+	msg.Signature = ed25519.Sign(b.config.Key, payload)
+
+	return msg
+}
+
+// seal signs the digest with the BLS key of the validator.
+// Nil is returned if the BLS key is not set
+func (b *Backend) seal(digest []byte) []byte {
+	if b.config.BLSKey == nil {
+		return nil
+	}
+
+	return b.config.BLSKey.Sign(digest)
+}
+
+// next returns the height and the parent hash of the block extending the head of the store
+func (b *Backend) next() (uint64, []byte, error) {
+	head, err := b.config.Store.Head()
+	if errors.Is(err, ErrNoBlocks) {
+		return 1, make([]byte, HashLength), nil
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return head.Height + 1, head.Hash(), nil
+}
@@ -0,0 +1,546 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/Hydra-Chain/go-ibft/backend/bls"
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+	"github.com/Hydra-Chain/go-ibft/proposer"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// generateKeys generates the deterministic keys of the validators
+func generateKeys(count int) []ed25519.PrivateKey {
+	keys := make([]ed25519.PrivateKey, 0, count)
+
+	for index := 0; index < count; index++ {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(index + 1)
+
+		keys = append(keys, ed25519.NewKeyFromSeed(seed))
+	}
+
+	return keys
+}
+
+// publicKeys returns the public keys of the private keys
+func publicKeys(keys []ed25519.PrivateKey) []ed25519.PublicKey {
+	publicKeys := make([]ed25519.PublicKey, 0, len(keys))
+
+	for _, key := range keys {
+		publicKey, _ := key.Public().(ed25519.PublicKey)
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	return publicKeys
+}
+
+// newBackends creates the backends of the validators, with their own block stores
+func newBackends(t *testing.T, count int) []*Backend {
+	t.Helper()
+
+	var (
+		keys       = generateKeys(count)
+		validators = NewStaticValidators(publicKeys(keys)...)
+		backends   = make([]*Backend, 0, count)
+	)
+
+	for _, key := range keys {
+		b, err := New(Config{
+			Key:        key,
+			Validators: validators,
+			Store:      NewMemoryBlockStore(),
+		})
+		require.NoError(t, err)
+
+		backends = append(backends, b)
+	}
+
+	return backends
+}
+
+// newBLSBackends creates the backends of the validators, which sign the seals with their BLS keys
+func newBLSBackends(t *testing.T, count int) []*Backend {
+	t.Helper()
+
+	var (
+		keys          = generateKeys(count)
+		validators    = NewStaticValidators(publicKeys(keys)...)
+		blsKeys       = make([]*bls.SecretKey, 0, count)
+		blsPublicKeys = make(map[string]*bls.PublicKey, count)
+		blsProofs     = make(map[string][]byte, count)
+		backends      = make([]*Backend, 0, count)
+	)
+
+	for index, key := range publicKeys(keys) {
+		blsKey, err := bls.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{byte(index + 1)}, 64)))
+		require.NoError(t, err)
+
+		blsKeys = append(blsKeys, blsKey)
+		blsPublicKeys[string(key)] = blsKey.PublicKey()
+		blsProofs[string(key)] = blsKey.ProofOfPossession()
+	}
+
+	for index, key := range keys {
+		b, err := New(Config{
+			Key:                   key,
+			Validators:            validators,
+			Store:                 NewMemoryBlockStore(),
+			BLSKey:                blsKeys[index],
+			BLSPublicKeys:         blsPublicKeys,
+			BLSProofsOfPossession: blsProofs,
+		})
+		require.NoError(t, err)
+
+		backends = append(backends, b)
+	}
+
+	return backends
+}
+
+func TestNew_Invalid(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key        = generateKeys(1)[0]
+		validators = NewStaticValidators(publicKeys([]ed25519.PrivateKey{key})...)
+		store      = NewMemoryBlockStore()
+		id         = string(publicKeys([]ed25519.PrivateKey{key})[0])
+	)
+
+	blsKey, err := bls.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{1}, 64)))
+	require.NoError(t, err)
+
+	rogueKey, err := bls.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{2}, 64)))
+	require.NoError(t, err)
+
+	blsConfig := func(proofs map[string][]byte) Config {
+		return Config{
+			Key:                   key,
+			Validators:            validators,
+			Store:                 store,
+			BLSKey:                blsKey,
+			BLSPublicKeys:         map[string]*bls.PublicKey{id: blsKey.PublicKey()},
+			BLSProofsOfPossession: proofs,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		err    error
+	}{
+		{"missing key", Config{Validators: validators, Store: store}, ErrInvalidKey},
+		{"short key", Config{Key: key[:ed25519.SeedSize], Validators: validators, Store: store}, ErrInvalidKey},
+		{"missing validators", Config{Key: key, Store: store}, ErrMissingValidators},
+		{"missing store", Config{Key: key, Validators: validators}, ErrMissingStore},
+		{
+			"missing BLS public keys",
+			Config{Key: key, Validators: validators, Store: store, BLSKey: &bls.SecretKey{}},
+			ErrMissingBLSPublicKeys,
+		},
+		{"missing proof of possession", blsConfig(nil), ErrInvalidProofOfPossession},
+		{
+			"invalid proof of possession",
+			blsConfig(map[string][]byte{id: rogueKey.ProofOfPossession()}),
+			ErrInvalidProofOfPossession,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.config)
+
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestBackend_IsValidValidator(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backends = newBackends(t, 2)
+		outsider = newBackends(t, 5)[4]
+		view     = &proto.View{Height: 1, Round: 0}
+		hash     = ProposalHash([]byte("proposal"), 0)
+	)
+
+	built := []*proto.Message{
+		backends[0].BuildPrePrepareMessage([]byte("proposal"), nil, view),
+		backends[0].BuildPrepareMessage(hash, view),
+		backends[0].BuildCommitMessage(hash, view),
+		backends[0].BuildRoundChangeMessage(nil, nil, view),
+	}
+
+	for _, msg := range built {
+		assert.Equal(t, backends[0].ID(), msg.From)
+		assert.True(t, backends[1].IsValidValidator(msg))
+	}
+
+	// Make sure the tampered messages are rejected
+	tampered := backends[0].BuildPrepareMessage(hash, view)
+	tampered.View = &proto.View{Height: 1, Round: 1}
+
+	assert.False(t, backends[1].IsValidValidator(tampered))
+
+	impersonated := backends[0].BuildPrepareMessage(hash, view)
+	impersonated.From = backends[1].ID()
+
+	assert.False(t, backends[1].IsValidValidator(impersonated))
+
+	// Make sure the messages of the validators of the other validator sets are rejected
+	assert.False(t, backends[1].IsValidValidator(outsider.BuildPrepareMessage(hash, view)))
+}
+
+func TestBackend_IsValidCommittedSeal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backends = newBackends(t, 2)
+		hash     = ProposalHash([]byte("proposal"), 0)
+		seal     = messages.ExtractCommittedSeal(backends[0].BuildCommitMessage(hash, &proto.View{Height: 1}))
+	)
+
+	assert.True(t, backends[1].IsValidCommittedSeal(hash, seal))
+
+	// Make sure the seal is bound to the round of the proposal
+	assert.False(t, backends[1].IsValidCommittedSeal(ProposalHash([]byte("proposal"), 1), seal))
+
+	assert.False(t, backends[1].IsValidCommittedSeal(hash, &messages.CommittedSeal{
+		Signer:    backends[1].ID(),
+		Signature: seal.Signature,
+	}))
+}
+
+func TestBackend_AggregatedSeals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backends = newBLSBackends(t, 4)
+		hash     = ProposalHash([]byte("proposal"), 0)
+		view     = &proto.View{Height: 1, Round: 0}
+		seals    = make([]*messages.CommittedSeal, 0, len(backends))
+	)
+
+	votingPowers, err := backends[0].GetVotingPowers(1)
+	require.NoError(t, err)
+
+	for _, b := range backends {
+		seal := messages.ExtractCommittedSeal(b.BuildCommitMessage(hash, view))
+
+		assert.True(t, backends[0].IsValidCommittedSeal(hash, seal))
+
+		seals = append(seals, seal)
+	}
+
+	aggregated, err := core.AggregateCommittedSeals(seals, votingPowers, backends[0])
+	require.NoError(t, err)
+
+	assert.NoError(t, core.VerifyAggregatedSeal(hash, aggregated, votingPowers, backends[1]))
+	assert.ErrorIs(
+		t,
+		core.VerifyAggregatedSeal(ProposalHash([]byte("proposal"), 1), aggregated, votingPowers, backends[1]),
+		core.ErrInvalidSeal,
+	)
+
+	// Make sure the seals can't be aggregated without the BLS keys
+	_, err = newBackends(t, 1)[0].AggregateSeals([][]byte{aggregated.Signature})
+	assert.ErrorIs(t, err, ErrAggregationDisabled)
+}
+
+func TestBackend_CompactCertificates(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backends  = newBLSBackends(t, 4)
+		proposal  = &proto.Proposal{RawProposal: []byte("proposal"), Round: 0}
+		hash      = ProposalHash(proposal.RawProposal, 0)
+		prepared  = &proto.View{Height: 1, Round: 0}
+		nextRound = &proto.View{Height: 1, Round: 1}
+	)
+
+	// Move the proposer of the prepared round to the front
+	for index, b := range backends {
+		if b.IsProposer(b.ID(), 1, 0) {
+			backends[0], backends[index] = backends[index], backends[0]
+		}
+	}
+
+	proposer := backends[0]
+
+	votingPowers, err := proposer.GetVotingPowers(1)
+	require.NoError(t, err)
+
+	pc := &proto.PreparedCertificate{
+		ProposalMessage: proposer.BuildPrePrepareMessage(proposal.RawProposal, nil, prepared),
+	}
+
+	for _, b := range backends[1:] {
+		pc.PrepareMessages = append(pc.PrepareMessages, b.BuildPrepareMessage(hash, prepared))
+	}
+
+	compactPC, err := core.NewCompactPreparedCertificate(pc, votingPowers, proposer)
+	require.NoError(t, err)
+
+	assert.NoError(t, core.VerifyCompactPreparedCertificate(compactPC, 1, 1, votingPowers, backends[1]))
+
+	rcc := &proto.RoundChangeCertificate{}
+
+	for index, b := range backends {
+		// Only some of the validators prepared the proposal
+		if index%2 == 0 {
+			rcc.RoundChangeMessages = append(rcc.RoundChangeMessages, b.BuildRoundChangeMessage(proposal, pc, nextRound))
+
+			continue
+		}
+
+		rcc.RoundChangeMessages = append(rcc.RoundChangeMessages, b.BuildRoundChangeMessage(nil, nil, nextRound))
+	}
+
+	compactRCC, err := core.NewCompactRoundChangeCertificate(rcc, votingPowers, proposer)
+	require.NoError(t, err)
+
+	assert.Len(t, compactRCC.RoundChangeSeals, 2)
+	assert.Equal(t, compactPC, compactRCC.LatestPreparedCertificate)
+
+	assert.NoError(t, core.VerifyCompactRoundChangeCertificate(compactRCC, votingPowers, backends[1]))
+}
+
+func TestBackend_IsValidProposalHash(t *testing.T) {
+	t.Parallel()
+
+	var (
+		b        = newBackends(t, 1)[0]
+		proposal = &proto.Proposal{
+			RawProposal: []byte("proposal"),
+			Round:       2,
+		}
+	)
+
+	assert.True(t, b.IsValidProposalHash(proposal, ProposalHash(proposal.RawProposal, 2)))
+	assert.False(t, b.IsValidProposalHash(proposal, ProposalHash(proposal.RawProposal, 0)))
+	assert.False(t, b.IsValidProposalHash(nil, ProposalHash(proposal.RawProposal, 2)))
+}
+
+func TestBackend_Proposals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys  = generateKeys(1)
+		store = NewMemoryBlockStore()
+	)
+
+	b, err := New(Config{
+		Key:        keys[0],
+		Validators: NewStaticValidators(publicKeys(keys)...),
+		Store:      store,
+		PayloadFn: func(height uint64) []byte {
+			return []byte{byte(height)}
+		},
+	})
+	require.NoError(t, err)
+
+	// Make sure the proposals are only built for the next height
+	assert.Nil(t, b.BuildProposal(&proto.View{Height: 2}))
+
+	for height := uint64(1); height <= 3; height++ {
+		rawProposal := b.BuildProposal(&proto.View{Height: height})
+		require.NotNil(t, rawProposal)
+
+		assert.True(t, b.IsValidProposal(rawProposal))
+
+		require.NoError(t, b.InsertProposal(&proto.Proposal{RawProposal: rawProposal, Round: 1}, nil))
+
+		// Make sure the inserted proposal is no longer valid
+		assert.False(t, b.IsValidProposal(rawProposal))
+
+		block := store.Block(height)
+		require.NotNil(t, block)
+
+		assert.Equal(t, []byte{byte(height)}, block.Payload)
+		assert.Equal(t, uint64(1), block.Round)
+	}
+
+	assert.False(t, b.IsValidProposal([]byte("invalid")))
+}
+
+func TestBackend_Finalizations(t *testing.T) {
+	t.Parallel()
+
+	keys := generateKeys(3)
+
+	b, err := New(Config{
+		Key:        keys[0],
+		Validators: NewStaticValidators(publicKeys(keys)...),
+		Store:      NewMemoryBlockStore(),
+		Selector:   proposer.Sticky{},
+	})
+	require.NoError(t, err)
+
+	// Make sure there is no finalization before the first block
+	finalization, err := b.GetFinalization(0)
+	require.NoError(t, err)
+	assert.Equal(t, proposer.Finalization{}, finalization)
+
+	_, err = b.GetFinalization(1)
+	assert.ErrorIs(t, err, ErrUnknownBlock)
+
+	// The block is finalized in round 1
+	expected, err := b.Proposer(1, 1)
+	require.NoError(t, err)
+
+	rawProposal := b.BuildProposal(&proto.View{Height: 1})
+	require.NoError(t, b.InsertProposal(&proto.Proposal{RawProposal: rawProposal, Round: 1}, nil))
+
+	finalization, err = b.GetFinalization(1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, finalization.Proposer)
+
+	// Make sure the proposer of the inserted block is kept for the next height
+	next, err := b.Proposer(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, next)
+}
+
+func TestBackend_ReportEquivocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys     = generateKeys(1)
+		reported []*messages.Evidence
+	)
+
+	b, err := New(Config{
+		Key:        keys[0],
+		Validators: NewStaticValidators(publicKeys(keys)...),
+		Store:      NewMemoryBlockStore(),
+		EquivocationFn: func(evidence *messages.Evidence) {
+			reported = append(reported, evidence)
+		},
+	})
+	require.NoError(t, err)
+
+	evidence := &messages.Evidence{}
+	b.ReportEquivocation(evidence)
+
+	assert.Equal(t, []*messages.Evidence{evidence}, reported)
+}
+
+// nopLogger drops the log messages
+type nopLogger struct{}
+
+func (nopLogger) Info(_ string, _ ...any) {}
+
+func (nopLogger) Debug(_ string, _ ...any) {}
+
+func (nopLogger) Error(_ string, _ ...any) {}
+
+// clusterTransport delivers the multicasted messages to all nodes of the cluster
+type clusterTransport struct {
+	nodes *[]*core.IBFT
+}
+
+// Multicast adds the message to all nodes
+func (t clusterTransport) Multicast(message *proto.Message) {
+	for _, node := range *t.nodes {
+		node.AddMessage(message)
+	}
+}
+
+func TestBackend_Cluster(t *testing.T) {
+	t.Parallel()
+
+	const (
+		validators = 4
+		heights    = 3
+	)
+
+	var (
+		backends = newBackends(t, validators)
+		nodes    = make([]*core.IBFT, 0, validators)
+	)
+
+	for _, b := range backends {
+		node, err := core.NewIBFTWithOptions(
+			nopLogger{},
+			b,
+			clusterTransport{nodes: &nodes},
+			core.WithBaseRoundTimeout(time.Second),
+		)
+		require.NoError(t, err)
+
+		nodes = append(nodes, node)
+	}
+
+	defer func() {
+		for _, node := range nodes {
+			_ = node.Close()
+		}
+	}()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelFn()
+
+	for height := uint64(1); height <= heights; height++ {
+		var wg sync.WaitGroup
+
+		wg.Add(validators)
+
+		for _, node := range nodes {
+			go func(node *core.IBFT) {
+				defer wg.Done()
+
+				_, err := node.RunSequence(ctx, height)
+				assert.NoError(t, err)
+			}(node)
+		}
+
+		wg.Wait()
+	}
+
+	// Make sure all validators inserted the same chain, finalized by the quorum
+	votingPowers, err := backends[0].GetVotingPowers(heights)
+	require.NoError(t, err)
+
+	for height := uint64(1); height <= heights; height++ {
+		store, _ := backends[0].config.Store.(*MemoryBlockStore)
+
+		block := store.Block(height)
+		require.NotNil(t, block)
+
+		for _, b := range backends[1:] {
+			other, _ := b.config.Store.(*MemoryBlockStore)
+
+			assert.Equal(t, block.Hash(), other.Block(height).Hash())
+		}
+
+		certificate, err := core.NewFinalityCertificate(
+			&proto.View{Height: height, Round: block.Round},
+			ProposalHash(block.RawProposal(), block.Round),
+			block.CommittedSeals,
+			votingPowers,
+		)
+		require.NoError(t, err)
+
+		assert.NoError(t, core.VerifyFinalityCertificate(certificate, height, votingPowers, backends[0]))
+	}
+}
@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/proposer"
+)
+
+// HashLength is the length of the Keccak-256 hashes
+const HashLength = 32
+
+// headerLength is the length of the encoded height and parent hash,
+// which prefix the payload in the raw proposal
+const headerLength = 8 + HashLength
+
+var (
+	// ErrInvalidBlock is returned when the raw proposal can't be decoded into a block
+	ErrInvalidBlock = errors.New("invalid block")
+
+	// ErrNoBlocks is returned by the block store when no block is inserted yet
+	ErrNoBlocks = errors.New("no blocks")
+
+	// ErrUnexpectedHeight is returned when the inserted block doesn't extend the head of the store
+	ErrUnexpectedHeight = errors.New("unexpected block height")
+
+	// ErrUnknownBlock is returned when the block at the height is not inserted yet
+	ErrUnknownBlock = errors.New("unknown block")
+)
+
+// Block is the proposal finalized by the validators, along with the round it was finalized in,
+// the committed seals finalizing it, and the data the proposers of the next height are selected by
+type Block struct {
+	// Height is the height of the block, starting from 1
+	Height uint64
+
+	// ParentHash is the hash of the previous block, or the zero hash for the first block
+	ParentHash []byte
+
+	// Payload is the application data of the block
+	Payload []byte
+
+	// Round is the round the block was finalized in
+	Round uint64
+
+	// CommittedSeals are the seals of the validators which committed the block
+	CommittedSeals []*messages.CommittedSeal
+
+	// Finalization is the data the proposers of the next height are selected by
+	Finalization proposer.Finalization
+}
+
+// Keccak256 returns the Keccak-256 hash of the data
+func Keccak256(data ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+
+	for _, d := range data {
+		hash.Write(d)
+	}
+
+	return hash.Sum(nil)
+}
+
+// ProposalHash returns the hash of the raw proposal in the round. The round is included,
+// since the committed seals sign the (rawProposal, round) tuple
+func ProposalHash(rawProposal []byte, round uint64) []byte {
+	encodedRound := make([]byte, 8)
+	binary.BigEndian.PutUint64(encodedRound, round)
+
+	return Keccak256(rawProposal, encodedRound)
+}
+
+// RawProposal returns the encoding of the block proposed to the validators,
+// which is made of the height, the parent hash and the payload
+func (b *Block) RawProposal() []byte {
+	raw := make([]byte, headerLength, headerLength+len(b.Payload))
+
+	binary.BigEndian.PutUint64(raw, b.Height)
+	copy(raw[8:], b.ParentHash)
+
+	return append(raw, b.Payload...)
+}
+
+// Hash returns the hash of the block, which doesn't depend
+// on the round it was finalized in, nor on the committed seals
+func (b *Block) Hash() []byte {
+	return Keccak256(b.RawProposal())
+}
+
+// DecodeBlock decodes the raw proposal into the block. The round
+// and the committed seals are not set, since they are not proposed
+func DecodeBlock(rawProposal []byte) (*Block, error) {
+	if len(rawProposal) < headerLength {
+		return nil, fmt.Errorf("%w: raw proposal of %d bytes", ErrInvalidBlock, len(rawProposal))
+	}
+
+	return &Block{
+		Height:     binary.BigEndian.Uint64(rawProposal),
+		ParentHash: bytes.Clone(rawProposal[8:headerLength]),
+		Payload:    bytes.Clone(rawProposal[headerLength:]),
+	}, nil
+}
+
+// BlockStore persists the finalized blocks.
+// Implementations must be safe for concurrent use
+type BlockStore interface {
+	// Head returns the latest block, or ErrNoBlocks if no block is inserted yet
+	Head() (*Block, error)
+
+	// Insert appends the finalized block, which must extend the head
+	Insert(block *Block) error
+
+	// Block returns the block at the height, or nil if it is not inserted yet
+	Block(height uint64) *Block
+}
+
+var _ BlockStore = &MemoryBlockStore{}
+
+// MemoryBlockStore is the BlockStore keeping the blocks in memory
+type MemoryBlockStore struct {
+	lock sync.RWMutex
+
+	// blocks are the inserted blocks, where the block at the index is at the height index + 1
+	blocks []*Block
+}
+
+// NewMemoryBlockStore creates a new empty MemoryBlockStore instance
+func NewMemoryBlockStore() *MemoryBlockStore {
+	return &MemoryBlockStore{
+		blocks: make([]*Block, 0),
+	}
+}
+
+// Head returns the latest block, or ErrNoBlocks if no block is inserted yet
+func (s *MemoryBlockStore) Head() (*Block, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.blocks) == 0 {
+		return nil, ErrNoBlocks
+	}
+
+	return s.blocks[len(s.blocks)-1], nil
+}
+
+// Insert appends the block, if it extends the head
+func (s *MemoryBlockStore) Insert(block *Block) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	parentHash := make([]byte, HashLength)
+	if len(s.blocks) > 0 {
+		parentHash = s.blocks[len(s.blocks)-1].Hash()
+	}
+
+	if block.Height != uint64(len(s.blocks))+1 {
+		return fmt.Errorf("%w: %d, expected %d", ErrUnexpectedHeight, block.Height, len(s.blocks)+1)
+	}
+
+	if !bytes.Equal(block.ParentHash, parentHash) {
+		return fmt.Errorf("%w: parent hash %x doesn't match the head", ErrInvalidBlock, block.ParentHash)
+	}
+
+	s.blocks = append(s.blocks, block)
+
+	return nil
+}
+
+// Block returns the block at the height, or nil if it is not inserted yet
+func (s *MemoryBlockStore) Block(height uint64) *Block {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if height == 0 || height > uint64(len(s.blocks)) {
+		return nil
+	}
+
+	return s.blocks[height-1]
+}
@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildChain builds the chain of blocks up to the height, with the payloads set to the heights
+func buildChain(height uint64) []*Block {
+	var (
+		blocks     = make([]*Block, 0, height)
+		parentHash = make([]byte, HashLength)
+	)
+
+	for h := uint64(1); h <= height; h++ {
+		block := &Block{
+			Height:     h,
+			ParentHash: parentHash,
+			Payload:    []byte{byte(h)},
+		}
+
+		blocks = append(blocks, block)
+		parentHash = block.Hash()
+	}
+
+	return blocks
+}
+
+func TestBlock_RawProposal(t *testing.T) {
+	t.Parallel()
+
+	block := &Block{
+		Height:     7,
+		ParentHash: Keccak256([]byte("parent")),
+		Payload:    []byte("payload"),
+		Round:      3,
+	}
+
+	decoded, err := DecodeBlock(block.RawProposal())
+	require.NoError(t, err)
+
+	// Make sure the round is not part of the proposal
+	assert.Equal(t, block.Height, decoded.Height)
+	assert.Equal(t, block.ParentHash, decoded.ParentHash)
+	assert.Equal(t, block.Payload, decoded.Payload)
+	assert.Zero(t, decoded.Round)
+	assert.Equal(t, block.Hash(), decoded.Hash())
+
+	_, err = DecodeBlock(make([]byte, headerLength-1))
+	assert.ErrorIs(t, err, ErrInvalidBlock)
+}
+
+func TestProposalHash(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte("proposal")
+
+	assert.Len(t, ProposalHash(raw, 0), HashLength)
+	assert.Equal(t, ProposalHash(raw, 1), ProposalHash(raw, 1))
+	assert.NotEqual(t, ProposalHash(raw, 0), ProposalHash(raw, 1))
+
+	// Make sure the hash is the Keccak-256 hash, and not the SHA3-256 one
+	assert.Equal(
+		t,
+		"c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		hex.EncodeToString(Keccak256()),
+	)
+}
+
+func TestMemoryBlockStore(t *testing.T) {
+	t.Parallel()
+
+	var (
+		store  = NewMemoryBlockStore()
+		blocks = buildChain(3)
+	)
+
+	_, err := store.Head()
+	assert.ErrorIs(t, err, ErrNoBlocks)
+
+	// Make sure the blocks must extend the head
+	assert.ErrorIs(t, store.Insert(blocks[1]), ErrUnexpectedHeight)
+
+	for _, block := range blocks {
+		require.NoError(t, store.Insert(block))
+	}
+
+	head, err := store.Head()
+	require.NoError(t, err)
+
+	assert.Equal(t, blocks[2], head)
+	assert.Equal(t, blocks[1], store.Block(2))
+	assert.Nil(t, store.Block(0))
+	assert.Nil(t, store.Block(4))
+
+	forked := &Block{
+		Height:     4,
+		ParentHash: blocks[1].Hash(),
+	}
+
+	assert.ErrorIs(t, store.Insert(forked), ErrInvalidBlock)
+}
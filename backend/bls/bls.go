@@ -0,0 +1,241 @@
+// Package bls implements the BLS signatures over the BLS12-381 curve, with the public keys
+// in G1 and the signatures in G2. The signatures of the same digest can be aggregated
+// into a single signature, verified against the aggregated public key of the signers.
+// To prevent the rogue key attacks, the public keys must be registered along with
+// their proof of possession, which is checked with VerifyProofOfPossession
+package bls
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+const (
+	// PublicKeySize is the size of the compressed public keys
+	PublicKeySize = 48
+
+	// SignatureSize is the size of the compressed signatures
+	SignatureSize = 96
+
+	// SecretKeySize is the size of the encoded secret keys
+	SecretKeySize = 32
+)
+
+var (
+	// signatureDomain is the domain separation tag of the signatures
+	signatureDomain = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+	// possessionDomain is the domain separation tag of the proofs of possession
+	possessionDomain = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+)
+
+var (
+	// ErrInvalidSecretKey is returned when the secret key is not in the scalar field, or is zero
+	ErrInvalidSecretKey = errors.New("invalid secret key")
+
+	// ErrInvalidPublicKey is returned when the public key is not a valid G1 point, or is the identity
+	ErrInvalidPublicKey = errors.New("invalid public key")
+
+	// ErrInvalidSignature is returned when the signature is not a valid G2 point
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrNoSignatures is returned when there are no signatures to aggregate
+	ErrNoSignatures = errors.New("no signatures to aggregate")
+)
+
+// SecretKey is the BLS secret key, which is a scalar of the curve
+type SecretKey struct {
+	scalar *big.Int
+}
+
+// PublicKey is the BLS public key, which is a point of G1
+type PublicKey struct {
+	point *bls12381.PointG1
+}
+
+// GenerateKey generates a new secret key, reading the randomness from the reader,
+// or from crypto/rand if the reader is nil
+func GenerateKey(reader io.Reader) (*SecretKey, error) {
+	if reader == nil {
+		reader = rand.Reader
+	}
+
+	order := bls12381.NewG1().Q()
+
+	for {
+		scalar, err := rand.Int(reader, order)
+		if err != nil {
+			return nil, err
+		}
+
+		if scalar.Sign() > 0 {
+			return &SecretKey{scalar: scalar}, nil
+		}
+	}
+}
+
+// SecretKeyFromBytes decodes the big-endian encoded secret key
+func SecretKeyFromBytes(raw []byte) (*SecretKey, error) {
+	if len(raw) != SecretKeySize {
+		return nil, ErrInvalidSecretKey
+	}
+
+	scalar := new(big.Int).SetBytes(raw)
+	if scalar.Sign() == 0 || scalar.Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, ErrInvalidSecretKey
+	}
+
+	return &SecretKey{scalar: scalar}, nil
+}
+
+// Bytes returns the big-endian encoding of the secret key
+func (k *SecretKey) Bytes() []byte {
+	return k.scalar.FillBytes(make([]byte, SecretKeySize))
+}
+
+// PublicKey returns the public key of the secret key
+func (k *SecretKey) PublicKey() *PublicKey {
+	g1 := bls12381.NewG1()
+
+	return &PublicKey{
+		point: g1.MulScalarBig(g1.New(), g1.One(), k.scalar),
+	}
+}
+
+// Sign signs the digest
+func (k *SecretKey) Sign(digest []byte) []byte {
+	return k.sign(digest, signatureDomain)
+}
+
+// ProofOfPossession returns the proof of possession of the secret key,
+// which is the signature of the public key
+func (k *SecretKey) ProofOfPossession() []byte {
+	return k.sign(k.PublicKey().Bytes(), possessionDomain)
+}
+
+// sign signs the message hashed to G2 with the domain
+func (k *SecretKey) sign(message, domain []byte) []byte {
+	g2 := bls12381.NewG2()
+
+	point, err := g2.HashToCurve(message, domain)
+	if err != nil {
+		// The hashing only fails for the domains longer than 255 bytes
+		panic(err)
+	}
+
+	return g2.ToCompressed(g2.MulScalarBig(point, point, k.scalar))
+}
+
+// PublicKeyFromBytes decodes the compressed public key
+func PublicKeyFromBytes(raw []byte) (*PublicKey, error) {
+	g1 := bls12381.NewG1()
+
+	point, err := g1.FromCompressed(raw)
+	if err != nil || g1.IsZero(point) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return &PublicKey{point: point}, nil
+}
+
+// Bytes returns the compressed encoding of the public key
+func (k *PublicKey) Bytes() []byte {
+	g1 := bls12381.NewG1()
+
+	// The encoding normalizes the point in place, so the copy is encoded
+	// to keep the public key safe for concurrent use
+	return g1.ToCompressed(g1.New().Set(k.point))
+}
+
+// Verify checks if the signature is signed over the digest by the secret key of the public key
+func Verify(key *PublicKey, digest, signature []byte) bool {
+	return verify(key, digest, signature, signatureDomain)
+}
+
+// VerifyProofOfPossession checks if the proof of possession is signed by the secret key of the public key
+func VerifyProofOfPossession(key *PublicKey, proof []byte) bool {
+	return verify(key, key.Bytes(), proof, possessionDomain)
+}
+
+// Aggregate aggregates the signatures into a single signature
+func Aggregate(signatures [][]byte) ([]byte, error) {
+	if len(signatures) == 0 {
+		return nil, ErrNoSignatures
+	}
+
+	var (
+		g2        = bls12381.NewG2()
+		aggregate = g2.Zero()
+	)
+
+	for _, signature := range signatures {
+		point, err := g2.FromCompressed(signature)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+
+		g2.Add(aggregate, aggregate, point)
+	}
+
+	return g2.ToCompressed(aggregate), nil
+}
+
+// AggregatePublicKeys aggregates the public keys into the public key
+// verifying the aggregated signatures of the same digest
+func AggregatePublicKeys(keys []*PublicKey) *PublicKey {
+	var (
+		g1        = bls12381.NewG1()
+		aggregate = g1.Zero()
+	)
+
+	for _, key := range keys {
+		g1.Add(aggregate, aggregate, key.point)
+	}
+
+	return &PublicKey{point: aggregate}
+}
+
+// FastAggregateVerify checks if the aggregated signature is signed over the digest by all the public keys
+func FastAggregateVerify(keys []*PublicKey, digest, signature []byte) bool {
+	if len(keys) == 0 {
+		return false
+	}
+
+	return Verify(AggregatePublicKeys(keys), digest, signature)
+}
+
+// verify checks if the signature is signed over the message hashed to G2 with the domain,
+// by checking if e(pk, H(m)) == e(g1, signature)
+func verify(key *PublicKey, message, signature, domain []byte) bool {
+	var (
+		engine = bls12381.NewEngine()
+		g1     = engine.G1
+		g2     = engine.G2
+	)
+
+	// The identity points are skipped by the pairing engine,
+	// so they must be rejected before the check
+	if key == nil || g1.IsZero(key.point) {
+		return false
+	}
+
+	point, err := g2.FromCompressed(signature)
+	if err != nil || g2.IsZero(point) {
+		return false
+	}
+
+	hashed, err := g2.HashToCurve(message, domain)
+	if err != nil {
+		return false
+	}
+
+	// The pairing engine normalizes the points in place, like the encoding
+	engine.AddPair(g1.New().Set(key.point), hashed)
+	engine.AddPairInv(g1.One(), point)
+
+	return engine.Check()
+}
@@ -0,0 +1,120 @@
+package bls
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateKeys generates the deterministic secret keys
+func generateKeys(t *testing.T, count int) []*SecretKey {
+	t.Helper()
+
+	keys := make([]*SecretKey, 0, count)
+
+	for index := 0; index < count; index++ {
+		key, err := GenerateKey(bytes.NewReader(bytes.Repeat([]byte{byte(index + 1)}, 64)))
+		require.NoError(t, err)
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func TestSecretKey_Encoding(t *testing.T) {
+	t.Parallel()
+
+	key := generateKeys(t, 1)[0]
+
+	decoded, err := SecretKeyFromBytes(key.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, key.PublicKey().Bytes(), decoded.PublicKey().Bytes())
+
+	_, err = SecretKeyFromBytes(make([]byte, SecretKeySize))
+	assert.ErrorIs(t, err, ErrInvalidSecretKey)
+
+	_, err = SecretKeyFromBytes(bytes.Repeat([]byte{0xff}, SecretKeySize))
+	assert.ErrorIs(t, err, ErrInvalidSecretKey)
+}
+
+func TestPublicKey_Encoding(t *testing.T) {
+	t.Parallel()
+
+	key := generateKeys(t, 1)[0].PublicKey()
+
+	raw := key.Bytes()
+	require.Len(t, raw, PublicKeySize)
+
+	decoded, err := PublicKeyFromBytes(raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, raw, decoded.Bytes())
+
+	// Make sure the identity is rejected
+	identity := make([]byte, PublicKeySize)
+	identity[0] = 0xc0
+
+	_, err = PublicKeyFromBytes(identity)
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+}
+
+func TestSign_Verify(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys   = generateKeys(t, 2)
+		digest = []byte("digest")
+	)
+
+	signature := keys[0].Sign(digest)
+	require.Len(t, signature, SignatureSize)
+
+	assert.True(t, Verify(keys[0].PublicKey(), digest, signature))
+	assert.False(t, Verify(keys[0].PublicKey(), []byte("other digest"), signature))
+	assert.False(t, Verify(keys[1].PublicKey(), digest, signature))
+	assert.False(t, Verify(keys[0].PublicKey(), digest, signature[1:]))
+
+	// Make sure the proof of possession is not a valid signature of the public key
+	proof := keys[0].ProofOfPossession()
+
+	assert.True(t, VerifyProofOfPossession(keys[0].PublicKey(), proof))
+	assert.False(t, VerifyProofOfPossession(keys[1].PublicKey(), proof))
+	assert.False(t, Verify(keys[0].PublicKey(), keys[0].PublicKey().Bytes(), proof))
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys       = generateKeys(t, 3)
+		digest     = []byte("digest")
+		signatures = make([][]byte, 0, len(keys))
+		publicKeys = make([]*PublicKey, 0, len(keys))
+	)
+
+	for _, key := range keys {
+		signatures = append(signatures, key.Sign(digest))
+		publicKeys = append(publicKeys, key.PublicKey())
+	}
+
+	aggregated, err := Aggregate(signatures)
+	require.NoError(t, err)
+	require.Len(t, aggregated, SignatureSize)
+
+	assert.True(t, FastAggregateVerify(publicKeys, digest, aggregated))
+
+	// Make sure all signers are required
+	assert.False(t, FastAggregateVerify(publicKeys[:2], digest, aggregated))
+	assert.False(t, FastAggregateVerify(nil, digest, aggregated))
+	assert.False(t, FastAggregateVerify(publicKeys, []byte("other digest"), aggregated))
+
+	_, err = Aggregate(nil)
+	assert.ErrorIs(t, err, ErrNoSignatures)
+
+	_, err = Aggregate([][]byte{signatures[0], []byte("invalid")})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
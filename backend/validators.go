@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"crypto/ed25519"
+	"math/big"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+)
+
+var _ core.ValidatorBackend = StaticValidators{}
+
+// StaticValidators is the ValidatorBackend with the same validator set at every height.
+// It maps the public keys of the validators on their voting powers
+type StaticValidators map[string]*big.Int
+
+// NewStaticValidators creates the validator set of the public keys, with the same voting power
+func NewStaticValidators(keys ...ed25519.PublicKey) StaticValidators {
+	validators := make(StaticValidators, len(keys))
+
+	for _, key := range keys {
+		validators[string(key)] = big.NewInt(1)
+	}
+
+	return validators
+}
+
+// GetVotingPowers returns a copy of the voting powers of the validators
+func (v StaticValidators) GetVotingPowers(_ uint64) (map[string]*big.Int, error) {
+	votingPowers := make(map[string]*big.Int, len(v))
+
+	for address, votingPower := range v {
+		votingPowers[address] = new(big.Int).Set(votingPower)
+	}
+
+	return votingPowers, nil
+}
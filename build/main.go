@@ -11,6 +11,14 @@ import (
 func main() {
 	b := core.NewIBFT(nil, nil, nil)
 
+	if err := b.Start(); err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		_ = b.Close()
+	}()
+
 	// prevent golang compiler from removing the whole function
 	_, _ = fmt.Fprint(io.Discard, b)
 }
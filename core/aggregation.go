@@ -0,0 +1,708 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+var (
+	// ErrInvalidBitmap is returned when the signer bitmap of the aggregated seal
+	// marks no validators, or marks the validators out of the validator set
+	ErrInvalidBitmap = errors.New("invalid signer bitmap")
+
+	// ErrInvalidCompactCertificate is returned when the compact certificate is malformed,
+	// or when it can't be built from the passed in certificate
+	ErrInvalidCompactCertificate = errors.New("invalid compact certificate")
+)
+
+const (
+	// prepareDigestTag and roundChangeDigestTag separate the prepare
+	// and the round change digests, so the seals of one can't be used as the other
+	prepareDigestTag     = "prepare"
+	roundChangeDigestTag = "round change"
+)
+
+// CompactCertificateVerifier verifies the compact certificates,
+// which checks the proposers along with the aggregated seals
+type CompactCertificateVerifier interface {
+	Verifier
+	AggregateVerifier
+}
+
+// PrepareDigest returns the digest the prepare seals are signed for,
+// which binds the seal to the view and the proposal hash
+func PrepareDigest(view *proto.View, proposalHash []byte) []byte {
+	return sealDigest(prepareDigestTag, view, 0, proposalHash)
+}
+
+// RoundChangeDigest returns the digest the round change seals are signed for, which binds the seal
+// to the view, and to the round and the hash of the latest prepared proposal of the sender
+func RoundChangeDigest(view *proto.View, certificate *proto.PreparedCertificate) []byte {
+	preparedRound, preparedProposalHash := preparedProposal(certificate)
+
+	return sealDigest(roundChangeDigestTag, view, preparedRound, preparedProposalHash)
+}
+
+// sealDigest returns the hash of the tagged view, round and proposal hash.
+// The variable length fields are prefixed by their length, so different inputs can't have the same encoding
+func sealDigest(tag string, view *proto.View, round uint64, proposalHash []byte) []byte {
+	var (
+		hash   = sha256.New()
+		number = make([]byte, 8)
+	)
+
+	writeNumber := func(value uint64) {
+		binary.BigEndian.PutUint64(number, value)
+		hash.Write(number)
+	}
+
+	writeNumber(uint64(len(tag)))
+	hash.Write([]byte(tag))
+
+	writeNumber(view.Height)
+	writeNumber(view.Round)
+	writeNumber(round)
+
+	writeNumber(uint64(len(proposalHash)))
+	hash.Write(proposalHash)
+
+	return hash.Sum(nil)
+}
+
+// preparedProposal returns the round and the hash of the proposal prepared with the certificate.
+// The hash is nil if the certificate is not set
+func preparedProposal(certificate *proto.PreparedCertificate) (uint64, []byte) {
+	if certificate == nil || certificate.ProposalMessage == nil || certificate.ProposalMessage.View == nil {
+		return 0, nil
+	}
+
+	return certificate.ProposalMessage.View.Round, messages.ExtractProposalHash(certificate.ProposalMessage)
+}
+
+// sortedValidators returns the validator addresses, in the order of the signer bitmaps
+func sortedValidators(votingPowers map[string]*big.Int) []string {
+	validators := make([]string, 0, len(votingPowers))
+	for address := range votingPowers {
+		validators = append(validators, address)
+	}
+
+	sort.Strings(validators)
+
+	return validators
+}
+
+// newSignerBitmap returns the bitmap of the signers, where the bit i is set if the validator i signed
+func newSignerBitmap(validators []string, signers map[string][]byte) []byte {
+	bitmap := make([]byte, (len(validators)+7)/8)
+
+	for index, validator := range validators {
+		if _, ok := signers[validator]; ok {
+			bitmap[index/8] |= 1 << (index % 8)
+		}
+	}
+
+	return bitmap
+}
+
+// bitmapSigners returns the validators marked in the signer bitmap
+func bitmapSigners(validators []string, bitmap []byte) ([][]byte, error) {
+	if len(bitmap) != (len(validators)+7)/8 {
+		return nil, ErrInvalidBitmap
+	}
+
+	signers := make([][]byte, 0, len(validators))
+
+	for index := 0; index < len(bitmap)*8; index++ {
+		if bitmap[index/8]&(1<<(index%8)) == 0 {
+			continue
+		}
+
+		if index >= len(validators) {
+			return nil, ErrInvalidBitmap
+		}
+
+		signers = append(signers, []byte(validators[index]))
+	}
+
+	if len(signers) == 0 {
+		return nil, ErrInvalidBitmap
+	}
+
+	return signers, nil
+}
+
+// hasSealQuorum checks if the signers have the quorum voting power, as calculated by the ValidatorManager
+func hasSealQuorum(votingPowers map[string]*big.Int, signers [][]byte) bool {
+	totalVotingPower := calculateTotalVotingPower(votingPowers)
+	if totalVotingPower.Sign() <= 0 {
+		return false
+	}
+
+	signersVotingPower := big.NewInt(0)
+	for _, signer := range signers {
+		signersVotingPower.Add(signersVotingPower, votingPowers[string(signer)])
+	}
+
+	return signersVotingPower.Cmp(calculateQuorum(totalVotingPower)) >= 0
+}
+
+// aggregateSeals aggregates the seals of the signers, in the order of the validators
+func aggregateSeals(
+	validators []string,
+	seals map[string][]byte,
+	verifier AggregateVerifier,
+) (*proto.AggregatedSeal, error) {
+	ordered := make([][]byte, 0, len(seals))
+
+	for _, validator := range validators {
+		if seal, ok := seals[validator]; ok {
+			ordered = append(ordered, seal)
+		}
+	}
+
+	signature, err := verifier.AggregateSeals(ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.AggregatedSeal{
+		Bitmap:    newSignerBitmap(validators, seals),
+		Signature: signature,
+	}, nil
+}
+
+// verifySeal verifies the aggregated seal for the digest, and returns its signers
+func verifySeal(
+	digest []byte,
+	seal *proto.AggregatedSeal,
+	validators []string,
+	verifier AggregateVerifier,
+) ([][]byte, error) {
+	if seal == nil {
+		return nil, ErrInvalidSeal
+	}
+
+	signers, err := bitmapSigners(validators, seal.Bitmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifier.IsValidAggregatedSeal(digest, signers, seal.Signature) {
+		return nil, fmt.Errorf("%w: invalid aggregated signature", ErrInvalidSeal)
+	}
+
+	return signers, nil
+}
+
+// aggregateVerifier returns the backend as the verifier of the aggregated seals,
+// if it implements it, and the aggregation is not disabled
+//
+//nolint:ireturn
+func (i *IBFT) aggregateVerifier() (AggregateVerifier, bool) {
+	backend := i.backend
+	if traced, ok := backend.(*tracingBackend); ok {
+		backend = traced.Backend
+	}
+
+	verifier, ok := backend.(AggregateVerifier)
+	if !ok {
+		return nil, false
+	}
+
+	if toggle, ok := backend.(AggregationSwitch); ok && !toggle.AggregationEnabled() {
+		return nil, false
+	}
+
+	return verifier, true
+}
+
+// hasValidAggregatableSeal checks if the message carries a valid aggregatable seal, if the backend
+// aggregates the seals. The PCs and the RCCs are only built from, and only accepted with,
+// the messages with the valid seals, so they can always be turned into the compact certificates
+func (i *IBFT) hasValidAggregatableSeal(message *proto.Message) bool {
+	verifier, ok := i.aggregateVerifier()
+	if !ok {
+		return true
+	}
+
+	var (
+		digest []byte
+		seal   []byte
+	)
+
+	switch message.Type {
+	case proto.MessageType_PREPREPARE, proto.MessageType_PREPARE:
+		digest = PrepareDigest(message.View, messages.ExtractMessageHash(message))
+		seal = messages.ExtractPrepareSeal(message)
+	case proto.MessageType_ROUND_CHANGE:
+		digest = RoundChangeDigest(message.View, messages.ExtractLatestPC(message))
+		seal = messages.ExtractRoundChangeSeal(message)
+	case proto.MessageType_COMMIT:
+		// The committed seals are verified separately
+		return true
+	default:
+		return false
+	}
+
+	return len(seal) > 0 && verifier.IsValidAggregatedSeal(digest, [][]byte{message.From}, seal)
+}
+
+// AggregateCommittedSeals aggregates the committed seals of the validator set
+// into a single seal, marking the signers in its bitmap
+func AggregateCommittedSeals(
+	committedSeals []*messages.CommittedSeal,
+	votingPowers map[string]*big.Int,
+	verifier AggregateVerifier,
+) (*messages.AggregatedSeal, error) {
+	seals := make(map[string][]byte, len(committedSeals))
+
+	for _, seal := range committedSeals {
+		if _, ok := votingPowers[string(seal.Signer)]; !ok {
+			return nil, fmt.Errorf("%w: signer %x is not a validator", ErrInvalidSeal, seal.Signer)
+		}
+
+		if _, ok := seals[string(seal.Signer)]; ok {
+			return nil, fmt.Errorf("%w: duplicate seal of signer %x", ErrInvalidSeal, seal.Signer)
+		}
+
+		seals[string(seal.Signer)] = seal.Signature
+	}
+
+	aggregated, err := aggregateSeals(sortedValidators(votingPowers), seals, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages.AggregatedSeal{
+		Bitmap:    aggregated.Bitmap,
+		Signature: aggregated.Signature,
+	}, nil
+}
+
+// VerifyAggregatedSeal verifies the aggregated seal for the digest, like the proposal hash
+// of the aggregated committed seals, against the validator set. The signers must have the quorum voting power
+func VerifyAggregatedSeal(
+	digest []byte,
+	seal *messages.AggregatedSeal,
+	votingPowers map[string]*big.Int,
+	verifier AggregateVerifier,
+) error {
+	if seal == nil {
+		return ErrInvalidSeal
+	}
+
+	signers, err := verifySeal(
+		digest,
+		&proto.AggregatedSeal{
+			Bitmap:    seal.Bitmap,
+			Signature: seal.Signature,
+		},
+		sortedValidators(votingPowers),
+		verifier,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !hasSealQuorum(votingPowers, signers) {
+		return ErrNoQuorum
+	}
+
+	return nil
+}
+
+// NewCompactPreparedCertificate creates the compact PC, aggregating the prepare seals
+// of the PREPREPARE and the PREPARE messages of the certificate. The seals which are not valid
+// are left out, but the seal of the proposer is required, and the remaining seals must have the quorum
+func NewCompactPreparedCertificate(
+	certificate *proto.PreparedCertificate,
+	votingPowers map[string]*big.Int,
+	verifier AggregateVerifier,
+) (*proto.CompactPreparedCertificate, error) {
+	if certificate == nil || certificate.ProposalMessage == nil || certificate.ProposalMessage.View == nil ||
+		certificate.ProposalMessage.Type != proto.MessageType_PREPREPARE {
+		return nil, ErrInvalidCompactCertificate
+	}
+
+	var (
+		proposal     = certificate.ProposalMessage
+		view         = proposal.View
+		proposalHash = messages.ExtractProposalHash(proposal)
+		digest       = PrepareDigest(view, proposalHash)
+		seals        = make(map[string][]byte, len(certificate.PrepareMessages)+1)
+	)
+
+	for _, message := range append([]*proto.Message{proposal}, certificate.PrepareMessages...) {
+		if message.View == nil || message.View.Height != view.Height || message.View.Round != view.Round {
+			continue
+		}
+
+		if !bytes.Equal(messages.ExtractMessageHash(message), proposalHash) {
+			continue
+		}
+
+		if _, ok := votingPowers[string(message.From)]; !ok {
+			continue
+		}
+
+		seal := messages.ExtractPrepareSeal(message)
+		if len(seal) == 0 || !verifier.IsValidAggregatedSeal(digest, [][]byte{message.From}, seal) {
+			continue
+		}
+
+		seals[string(message.From)] = seal
+	}
+
+	if _, ok := seals[string(proposal.From)]; !ok {
+		return nil, fmt.Errorf("%w: missing prepare seal of the proposer", ErrInvalidCompactCertificate)
+	}
+
+	signers := make([][]byte, 0, len(seals))
+	for signer := range seals {
+		signers = append(signers, []byte(signer))
+	}
+
+	if !hasSealQuorum(votingPowers, signers) {
+		return nil, ErrNoQuorum
+	}
+
+	prepareSeal, err := aggregateSeals(sortedValidators(votingPowers), seals, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.CompactPreparedCertificate{
+		View: &proto.View{
+			Height: view.Height,
+			Round:  view.Round,
+		},
+		ProposalHash: proposalHash,
+		PrepareSeal:  prepareSeal,
+	}, nil
+}
+
+// VerifyCompactPreparedCertificate verifies the compact PC of a proposal prepared at the height,
+// in a round lower than the round limit. The prepare seal must be signed by the proposer
+// of the round, and by the validators which together have the quorum voting power
+func VerifyCompactPreparedCertificate(
+	certificate *proto.CompactPreparedCertificate,
+	height, roundLimit uint64,
+	votingPowers map[string]*big.Int,
+	verifier CompactCertificateVerifier,
+) error {
+	if certificate == nil || certificate.View == nil || len(certificate.ProposalHash) == 0 {
+		return ErrInvalidCompactCertificate
+	}
+
+	view := certificate.View
+	if view.Height != height || view.Round >= roundLimit {
+		return fmt.Errorf("%w: unexpected view %d/%d", ErrInvalidCompactCertificate, view.Height, view.Round)
+	}
+
+	signers, err := verifySeal(
+		PrepareDigest(view, certificate.ProposalHash),
+		certificate.PrepareSeal,
+		sortedValidators(votingPowers),
+		verifier,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !hasSealQuorum(votingPowers, signers) {
+		return ErrNoQuorum
+	}
+
+	// Make sure the proposal was proposed by the proposer for the round
+	for _, signer := range signers {
+		if verifier.IsProposer(signer, view.Height, view.Round) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: missing prepare seal of the proposer", ErrInvalidCompactCertificate)
+}
+
+// roundChangeClaim groups the round change seals
+// of the validators with the same latest prepared proposal
+type roundChangeClaim struct {
+	preparedRound        uint64
+	preparedProposalHash []byte
+
+	// seals are the round change seals of the validators
+	seals map[string][]byte
+
+	// certificates are the PCs the validators prepared the proposal with
+	certificates []*proto.PreparedCertificate
+}
+
+// NewCompactRoundChangeCertificate creates the compact RCC, aggregating the round change seals
+// of the ROUND CHANGE messages of the certificate, grouped by the latest prepared proposal
+// of the senders. Only the PC of the highest prepared proposal is included, in its compact form.
+// The seals which are not valid, and the seals of the prepared proposals higher than the highest
+// one with a valid compact PC are left out. The remaining seals must have the quorum
+func NewCompactRoundChangeCertificate(
+	certificate *proto.RoundChangeCertificate,
+	votingPowers map[string]*big.Int,
+	verifier AggregateVerifier,
+) (*proto.CompactRoundChangeCertificate, error) {
+	if certificate == nil || len(certificate.RoundChangeMessages) == 0 ||
+		certificate.RoundChangeMessages[0].View == nil {
+		return nil, ErrInvalidCompactCertificate
+	}
+
+	var (
+		view    = certificate.RoundChangeMessages[0].View
+		claims  = make(map[string]*roundChangeClaim)
+		senders = make(map[string]struct{}, len(certificate.RoundChangeMessages))
+	)
+
+	for _, message := range certificate.RoundChangeMessages {
+		if message.Type != proto.MessageType_ROUND_CHANGE || message.View == nil ||
+			message.View.Height != view.Height || message.View.Round != view.Round {
+			continue
+		}
+
+		if _, ok := votingPowers[string(message.From)]; !ok {
+			continue
+		}
+
+		if _, ok := senders[string(message.From)]; ok {
+			continue
+		}
+
+		var (
+			pc                          = messages.ExtractLatestPC(message)
+			preparedRound, preparedHash = preparedProposal(pc)
+			seal                        = messages.ExtractRoundChangeSeal(message)
+		)
+
+		if len(seal) == 0 || !verifier.IsValidAggregatedSeal(RoundChangeDigest(view, pc), [][]byte{message.From}, seal) {
+			continue
+		}
+
+		key := fmt.Sprintf("%d/%x", preparedRound, preparedHash)
+
+		claim, ok := claims[key]
+		if !ok {
+			claim = &roundChangeClaim{
+				preparedRound:        preparedRound,
+				preparedProposalHash: preparedHash,
+				seals:                make(map[string][]byte),
+			}
+
+			claims[key] = claim
+		}
+
+		senders[string(message.From)] = struct{}{}
+		claim.seals[string(message.From)] = seal
+
+		if pc != nil {
+			claim.certificates = append(claim.certificates, pc)
+		}
+	}
+
+	sorted := make([]*roundChangeClaim, 0, len(claims))
+	for _, claim := range claims {
+		sorted = append(sorted, claim)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].preparedRound != sorted[j].preparedRound {
+			return sorted[i].preparedRound < sorted[j].preparedRound
+		}
+
+		return bytes.Compare(sorted[i].preparedProposalHash, sorted[j].preparedProposalHash) < 0
+	})
+
+	latest, included := latestCompactPC(sorted, view, votingPowers, verifier)
+
+	signers := make([][]byte, 0, len(senders))
+
+	for _, claim := range included {
+		for signer := range claim.seals {
+			signers = append(signers, []byte(signer))
+		}
+	}
+
+	if !hasSealQuorum(votingPowers, signers) {
+		return nil, ErrNoQuorum
+	}
+
+	var (
+		validators       = sortedValidators(votingPowers)
+		roundChangeSeals = make([]*proto.RoundChangeSeal, 0, len(included))
+	)
+
+	for _, claim := range included {
+		seal, err := aggregateSeals(validators, claim.seals, verifier)
+		if err != nil {
+			return nil, err
+		}
+
+		roundChangeSeals = append(roundChangeSeals, &proto.RoundChangeSeal{
+			PreparedRound:        claim.preparedRound,
+			PreparedProposalHash: claim.preparedProposalHash,
+			Seal:                 seal,
+		})
+	}
+
+	return &proto.CompactRoundChangeCertificate{
+		View: &proto.View{
+			Height: view.Height,
+			Round:  view.Round,
+		},
+		RoundChangeSeals:          roundChangeSeals,
+		LatestPreparedCertificate: latest,
+	}, nil
+}
+
+// latestCompactPC returns the compact PC of the highest prepared proposal of the sorted claims
+// which can be proven, along with the claims which can be included in the compact RCC.
+// These are the claims of the proposals prepared before the highest one, and the claims of no prepared proposal
+func latestCompactPC(
+	sorted []*roundChangeClaim,
+	view *proto.View,
+	votingPowers map[string]*big.Int,
+	verifier AggregateVerifier,
+) (*proto.CompactPreparedCertificate, []*roundChangeClaim) {
+	for index := len(sorted) - 1; index >= 0; index-- {
+		claim := sorted[index]
+		if claim.preparedProposalHash == nil {
+			continue
+		}
+
+		for _, pc := range claim.certificates {
+			compact, err := NewCompactPreparedCertificate(pc, votingPowers, verifier)
+			if err != nil || compact.View.Height != view.Height || compact.View.Round >= view.Round {
+				continue
+			}
+
+			included := make([]*roundChangeClaim, 0, index+1)
+
+			// The other proposals prepared in the same round can't be proven
+			for _, other := range sorted[:index] {
+				if other.preparedRound < claim.preparedRound || other.preparedProposalHash == nil {
+					included = append(included, other)
+				}
+			}
+
+			return compact, append(included, claim)
+		}
+	}
+
+	// None of the prepared proposals can be proven
+	included := make([]*roundChangeClaim, 0, len(sorted))
+
+	for _, claim := range sorted {
+		if claim.preparedProposalHash == nil {
+			included = append(included, claim)
+		}
+	}
+
+	return nil, included
+}
+
+// VerifyCompactRoundChangeCertificate verifies the compact RCC against the validator set
+// of its height. The round change seals must be signed by distinct validators, which together
+// have the quorum voting power. If any of them prepared a proposal, the compact PC
+// of the highest prepared proposal must be included and valid
+func VerifyCompactRoundChangeCertificate(
+	certificate *proto.CompactRoundChangeCertificate,
+	votingPowers map[string]*big.Int,
+	verifier CompactCertificateVerifier,
+) error {
+	if certificate == nil || certificate.View == nil || len(certificate.RoundChangeSeals) == 0 {
+		return ErrInvalidCompactCertificate
+	}
+
+	var (
+		view       = certificate.View
+		validators = sortedValidators(votingPowers)
+		signers    = make(map[string]struct{})
+		allSigners = make([][]byte, 0, len(validators))
+
+		latest *proto.RoundChangeSeal
+	)
+
+	for _, roundChangeSeal := range certificate.RoundChangeSeals {
+		if roundChangeSeal == nil {
+			return ErrInvalidCompactCertificate
+		}
+
+		prepared := len(roundChangeSeal.PreparedProposalHash) > 0
+
+		if (!prepared && roundChangeSeal.PreparedRound != 0) || (prepared && roundChangeSeal.PreparedRound >= view.Round) {
+			return fmt.Errorf("%w: invalid prepared round %d", ErrInvalidCompactCertificate, roundChangeSeal.PreparedRound)
+		}
+
+		var hash []byte
+		if prepared {
+			hash = roundChangeSeal.PreparedProposalHash
+		}
+
+		sealSigners, err := verifySeal(
+			sealDigest(roundChangeDigestTag, view, roundChangeSeal.PreparedRound, hash),
+			roundChangeSeal.Seal,
+			validators,
+			verifier,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, signer := range sealSigners {
+			if _, ok := signers[string(signer)]; ok {
+				return fmt.Errorf("%w: duplicate seal of signer %x", ErrInvalidSeal, signer)
+			}
+
+			signers[string(signer)] = struct{}{}
+		}
+
+		allSigners = append(allSigners, sealSigners...)
+
+		if !prepared {
+			continue
+		}
+
+		if latest == nil || roundChangeSeal.PreparedRound > latest.PreparedRound {
+			latest = roundChangeSeal
+
+			continue
+		}
+
+		// Make sure there are no conflicting proposals prepared in the highest round
+		if roundChangeSeal.PreparedRound == latest.PreparedRound &&
+			!bytes.Equal(roundChangeSeal.PreparedProposalHash, latest.PreparedProposalHash) {
+			return fmt.Errorf("%w: conflicting prepared proposals", ErrInvalidCompactCertificate)
+		}
+	}
+
+	if !hasSealQuorum(votingPowers, allSigners) {
+		return ErrNoQuorum
+	}
+
+	if latest == nil {
+		if certificate.LatestPreparedCertificate != nil {
+			return fmt.Errorf("%w: unexpected prepared certificate", ErrInvalidCompactCertificate)
+		}
+
+		return nil
+	}
+
+	pc := certificate.LatestPreparedCertificate
+	if pc == nil || pc.View == nil || pc.View.Round != latest.PreparedRound ||
+		!bytes.Equal(pc.ProposalHash, latest.PreparedProposalHash) {
+		return fmt.Errorf("%w: missing prepared certificate of the highest proposal", ErrInvalidCompactCertificate)
+	}
+
+	return VerifyCompactPreparedCertificate(pc, view.Height, view.Round, votingPowers, verifier)
+}
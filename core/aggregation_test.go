@@ -0,0 +1,493 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// mockAggregateVerifier verifies the mock seals, which are the hashes of the signer and the digest.
+// The seals are aggregated by XOR, so the aggregated seals can be verified against the signers
+type mockAggregateVerifier struct {
+	mockBackend
+}
+
+// mockSeal returns the mock seal of the signer for the digest
+func mockSeal(signer string, digest []byte) []byte {
+	seal := sha256.Sum256(append([]byte(signer), digest...))
+
+	return seal[:]
+}
+
+// AggregateSeals XORs the seals
+func (m mockAggregateVerifier) AggregateSeals(seals [][]byte) ([]byte, error) {
+	if len(seals) == 0 {
+		return nil, errors.New("no seals")
+	}
+
+	aggregated := make([]byte, sha256.Size)
+
+	for _, seal := range seals {
+		if len(seal) != sha256.Size {
+			return nil, errors.New("invalid seal")
+		}
+
+		for index := range aggregated {
+			aggregated[index] ^= seal[index]
+		}
+	}
+
+	return aggregated, nil
+}
+
+// IsValidAggregatedSeal checks if the signature is the XOR of the seals of the signers
+func (m mockAggregateVerifier) IsValidAggregatedSeal(digest []byte, signers [][]byte, signature []byte) bool {
+	seals := make([][]byte, 0, len(signers))
+	for _, signer := range signers {
+		seals = append(seals, mockSeal(string(signer), digest))
+	}
+
+	aggregated, err := m.AggregateSeals(seals)
+
+	return err == nil && bytes.Equal(aggregated, signature)
+}
+
+// aggregationVotingPowers returns the validator set of the aggregation tests.
+// The quorum of the total voting power 10 is 7
+func aggregationVotingPowers() map[string]*big.Int {
+	return map[string]*big.Int{
+		"A": big.NewInt(4),
+		"B": big.NewInt(3),
+		"C": big.NewInt(2),
+		"D": big.NewInt(1),
+	}
+}
+
+// newAggregateVerifier returns the mock verifier, where A proposes in every round
+func newAggregateVerifier() mockAggregateVerifier {
+	return mockAggregateVerifier{
+		mockBackend: mockBackend{
+			isProposerFn: func(from []byte, _, _ uint64) bool {
+				return bytes.Equal(from, []byte("A"))
+			},
+		},
+	}
+}
+
+func TestAggregateCommittedSeals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		votingPowers = aggregationVotingPowers()
+		verifier     = newAggregateVerifier()
+	)
+
+	seals := func(signers ...string) []*messages.CommittedSeal {
+		committedSeals := make([]*messages.CommittedSeal, 0, len(signers))
+		for _, signer := range signers {
+			committedSeals = append(committedSeals, &messages.CommittedSeal{
+				Signer:    []byte(signer),
+				Signature: mockSeal(signer, proposalHash),
+			})
+		}
+
+		return committedSeals
+	}
+
+	t.Run("valid seal", func(t *testing.T) {
+		t.Parallel()
+
+		seal, err := AggregateCommittedSeals(seals("B", "A"), votingPowers, verifier)
+		require.NoError(t, err)
+
+		// The validators are ordered by their addresses
+		assert.Equal(t, []byte{0b0011}, seal.Bitmap)
+
+		assert.NoError(t, VerifyAggregatedSeal(proposalHash, seal, votingPowers, verifier))
+		assert.ErrorIs(t, VerifyAggregatedSeal([]byte("other hash"), seal, votingPowers, verifier), ErrInvalidSeal)
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		t.Parallel()
+
+		seal, err := AggregateCommittedSeals(seals("A", "C"), votingPowers, verifier)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, VerifyAggregatedSeal(proposalHash, seal, votingPowers, verifier), ErrNoQuorum)
+	})
+
+	t.Run("invalid seals", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AggregateCommittedSeals(seals("A", "B", "B"), votingPowers, verifier)
+		assert.ErrorIs(t, err, ErrInvalidSeal)
+
+		_, err = AggregateCommittedSeals(seals("A", "B", "E"), votingPowers, verifier)
+		assert.ErrorIs(t, err, ErrInvalidSeal)
+	})
+
+	t.Run("invalid bitmaps", func(t *testing.T) {
+		t.Parallel()
+
+		seal, err := AggregateCommittedSeals(seals("A", "B"), votingPowers, verifier)
+		require.NoError(t, err)
+
+		for _, bitmap := range [][]byte{nil, {0}, {0b10011}, {0b0011, 0}} {
+			assert.ErrorIs(
+				t,
+				VerifyAggregatedSeal(proposalHash, &messages.AggregatedSeal{
+					Bitmap:    bitmap,
+					Signature: seal.Signature,
+				}, votingPowers, verifier),
+				ErrInvalidBitmap,
+			)
+		}
+
+		assert.ErrorIs(t, VerifyAggregatedSeal(proposalHash, nil, votingPowers, verifier), ErrInvalidSeal)
+	})
+}
+
+// newSealedPC returns the PC of the proposal prepared in the view,
+// with the prepare seals of the proposer and the preparers
+func newSealedPC(view *proto.View, proposalHash []byte, proposer string, preparers ...string) *proto.PreparedCertificate {
+	digest := PrepareDigest(view, proposalHash)
+
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: &proto.Message{
+			View: view,
+			From: []byte(proposer),
+			Type: proto.MessageType_PREPREPARE,
+			Payload: &proto.Message_PreprepareData{
+				PreprepareData: &proto.PrePrepareMessage{
+					ProposalHash: proposalHash,
+					PrepareSeal:  mockSeal(proposer, digest),
+				},
+			},
+		},
+	}
+
+	for _, preparer := range preparers {
+		certificate.PrepareMessages = append(certificate.PrepareMessages, &proto.Message{
+			View: view,
+			From: []byte(preparer),
+			Type: proto.MessageType_PREPARE,
+			Payload: &proto.Message_PrepareData{
+				PrepareData: &proto.PrepareMessage{
+					ProposalHash: proposalHash,
+					PrepareSeal:  mockSeal(preparer, digest),
+				},
+			},
+		})
+	}
+
+	return certificate
+}
+
+func TestCompactPreparedCertificate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		view         = &proto.View{Height: 10, Round: 1}
+		votingPowers = aggregationVotingPowers()
+		verifier     = newAggregateVerifier()
+	)
+
+	t.Run("valid certificate", func(t *testing.T) {
+		t.Parallel()
+
+		compact, err := NewCompactPreparedCertificate(
+			newSealedPC(view, proposalHash, "A", "B", "C"),
+			votingPowers,
+			verifier,
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, proposalHash, compact.ProposalHash)
+		assert.Equal(t, []byte{0b0111}, compact.PrepareSeal.Bitmap)
+
+		assert.NoError(t, VerifyCompactPreparedCertificate(compact, 10, 2, votingPowers, verifier))
+
+		// Make sure the certificate is bound to the height and the rounds before the limit
+		assert.ErrorIs(
+			t,
+			VerifyCompactPreparedCertificate(compact, 11, 2, votingPowers, verifier),
+			ErrInvalidCompactCertificate,
+		)
+		assert.ErrorIs(
+			t,
+			VerifyCompactPreparedCertificate(compact, 10, 1, votingPowers, verifier),
+			ErrInvalidCompactCertificate,
+		)
+	})
+
+	t.Run("invalid seals are left out", func(t *testing.T) {
+		t.Parallel()
+
+		certificate := newSealedPC(view, proposalHash, "A", "B", "C")
+		certificate.PrepareMessages[0].GetPrepareData().PrepareSeal = mockSeal("B", []byte("other digest"))
+
+		_, err := NewCompactPreparedCertificate(certificate, votingPowers, verifier)
+		assert.ErrorIs(t, err, ErrNoQuorum)
+	})
+
+	t.Run("missing proposer seal", func(t *testing.T) {
+		t.Parallel()
+
+		certificate := newSealedPC(view, proposalHash, "A", "B", "C", "D")
+		certificate.ProposalMessage.GetPreprepareData().PrepareSeal = nil
+
+		_, err := NewCompactPreparedCertificate(certificate, votingPowers, verifier)
+		assert.ErrorIs(t, err, ErrInvalidCompactCertificate)
+
+		// Make sure the certificates without the seal of the proposer of the round are rejected
+		compact, err := NewCompactPreparedCertificate(
+			newSealedPC(view, proposalHash, "A", "B", "C"),
+			votingPowers,
+			verifier,
+		)
+		require.NoError(t, err)
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactPreparedCertificate(compact, 10, 2, votingPowers, mockAggregateVerifier{
+				mockBackend: mockBackend{
+					isProposerFn: func(from []byte, _, _ uint64) bool {
+						return bytes.Equal(from, []byte("D"))
+					},
+				},
+			}),
+			ErrInvalidCompactCertificate,
+		)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewCompactPreparedCertificate(nil, votingPowers, verifier)
+		assert.ErrorIs(t, err, ErrInvalidCompactCertificate)
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactPreparedCertificate(nil, 10, 2, votingPowers, verifier),
+			ErrInvalidCompactCertificate,
+		)
+	})
+}
+
+// newSealedRoundChange returns the ROUND_CHANGE message of the sender
+// with the latest PC, and the round change seal of the sender
+func newSealedRoundChange(view *proto.View, sender string, certificate *proto.PreparedCertificate) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: []byte(sender),
+		Type: proto.MessageType_ROUND_CHANGE,
+		Payload: &proto.Message_RoundChangeData{
+			RoundChangeData: &proto.RoundChangeMessage{
+				LatestPreparedCertificate: certificate,
+				RoundChangeSeal:           mockSeal(sender, RoundChangeDigest(view, certificate)),
+			},
+		},
+	}
+}
+
+func TestCompactRoundChangeCertificate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view         = &proto.View{Height: 10, Round: 3}
+		votingPowers = aggregationVotingPowers()
+		verifier     = newAggregateVerifier()
+
+		firstPC  = newSealedPC(&proto.View{Height: 10, Round: 0}, []byte("first hash"), "A", "B", "C")
+		secondPC = newSealedPC(&proto.View{Height: 10, Round: 1}, []byte("second hash"), "A", "B", "C")
+
+		// The PC of D is not proposed by the proposer, so its claim is left out
+		invalidPC = newSealedPC(&proto.View{Height: 10, Round: 2}, []byte("third hash"), "D", "A", "B", "C")
+	)
+
+	certificate := &proto.RoundChangeCertificate{
+		RoundChangeMessages: []*proto.Message{
+			newSealedRoundChange(view, "A", secondPC),
+			newSealedRoundChange(view, "B", firstPC),
+			newSealedRoundChange(view, "C", nil),
+			newSealedRoundChange(view, "D", invalidPC),
+		},
+	}
+
+	// The proposer seal is required by the builder, which can't check the proposer
+	invalidPC.ProposalMessage.GetPreprepareData().PrepareSeal = nil
+
+	compact, err := NewCompactRoundChangeCertificate(certificate, votingPowers, verifier)
+	require.NoError(t, err)
+
+	// The round change seals are ordered by the prepared proposals of C, B and A
+	require.Len(t, compact.RoundChangeSeals, 3)
+	require.NotNil(t, compact.LatestPreparedCertificate)
+
+	assert.Equal(t, uint64(1), compact.LatestPreparedCertificate.View.Round)
+	assert.Equal(t, []byte("second hash"), compact.LatestPreparedCertificate.ProposalHash)
+
+	assert.NoError(t, VerifyCompactRoundChangeCertificate(compact, votingPowers, verifier))
+
+	t.Run("missing prepared certificate", func(t *testing.T) {
+		t.Parallel()
+
+		tampered := &proto.CompactRoundChangeCertificate{
+			View:             compact.View,
+			RoundChangeSeals: compact.RoundChangeSeals,
+		}
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactRoundChangeCertificate(tampered, votingPowers, verifier),
+			ErrInvalidCompactCertificate,
+		)
+	})
+
+	t.Run("duplicate signers", func(t *testing.T) {
+		t.Parallel()
+
+		tampered := &proto.CompactRoundChangeCertificate{
+			View:                      compact.View,
+			RoundChangeSeals:          append(compact.RoundChangeSeals, compact.RoundChangeSeals[0]),
+			LatestPreparedCertificate: compact.LatestPreparedCertificate,
+		}
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactRoundChangeCertificate(tampered, votingPowers, verifier),
+			ErrInvalidSeal,
+		)
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewCompactRoundChangeCertificate(
+			&proto.RoundChangeCertificate{
+				RoundChangeMessages: certificate.RoundChangeMessages[1:],
+			},
+			votingPowers,
+			verifier,
+		)
+		assert.ErrorIs(t, err, ErrNoQuorum)
+
+		tampered := &proto.CompactRoundChangeCertificate{
+			View:                      compact.View,
+			RoundChangeSeals:          []*proto.RoundChangeSeal{compact.RoundChangeSeals[0], compact.RoundChangeSeals[2]},
+			LatestPreparedCertificate: compact.LatestPreparedCertificate,
+		}
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactRoundChangeCertificate(tampered, votingPowers, verifier),
+			ErrNoQuorum,
+		)
+	})
+
+	t.Run("no prepared proposals", func(t *testing.T) {
+		t.Parallel()
+
+		unprepared, err := NewCompactRoundChangeCertificate(
+			&proto.RoundChangeCertificate{
+				RoundChangeMessages: []*proto.Message{
+					newSealedRoundChange(view, "A", nil),
+					newSealedRoundChange(view, "B", nil),
+				},
+			},
+			votingPowers,
+			verifier,
+		)
+		require.NoError(t, err)
+
+		assert.Nil(t, unprepared.LatestPreparedCertificate)
+		assert.NoError(t, VerifyCompactRoundChangeCertificate(unprepared, votingPowers, verifier))
+
+		unprepared.LatestPreparedCertificate = compact.LatestPreparedCertificate
+
+		assert.ErrorIs(
+			t,
+			VerifyCompactRoundChangeCertificate(unprepared, votingPowers, verifier),
+			ErrInvalidCompactCertificate,
+		)
+	})
+}
+
+// TestIBFT_AggregatableSeals makes sure the PCs and the RCCs are only built from,
+// and only accepted with, the messages with the valid seals, if the backend aggregates them
+func TestIBFT_AggregatableSeals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view     = &proto.View{Height: 10, Round: 1}
+		rcView   = &proto.View{Height: 10, Round: 2}
+		verifier = newAggregateVerifier()
+	)
+
+	verifier.getVotingPowerFn = func(_ uint64) (map[string]*big.Int, error) {
+		return aggregationVotingPowers(), nil
+	}
+	verifier.IsValidValidatorFn = func(_ *proto.Message) bool {
+		return true
+	}
+
+	// tamperedPC returns the PC where the prepare seal of B is signed for another digest
+	tamperedPC := func() *proto.PreparedCertificate {
+		certificate := newSealedPC(view, []byte("proposal hash"), "A", "B", "C")
+		certificate.PrepareMessages[0].GetPrepareData().PrepareSeal = mockSeal("B", []byte("other digest"))
+
+		return certificate
+	}
+
+	t.Run("aggregating backend", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, verifier, mockTransport{})
+		require.NoError(t, i.validatorManager.Init(view.Height))
+
+		assert.True(t, i.validPC(newSealedPC(view, []byte("proposal hash"), "A", "B", "C"), 2, 10))
+		assert.False(t, i.validPC(tamperedPC(), 2, 10))
+
+		roundChange := newSealedRoundChange(rcView, "B", nil)
+		assert.True(t, i.hasValidAggregatableSeal(roundChange))
+
+		roundChange.GetRoundChangeData().RoundChangeSeal = nil
+		assert.False(t, i.hasValidAggregatableSeal(roundChange))
+	})
+
+	t.Run("traced aggregating backend", func(t *testing.T) {
+		t.Parallel()
+
+		i, err := NewIBFTWithOptions(
+			mockLogger{},
+			verifier,
+			mockTransport{},
+			WithTraceRecorder(NewMemoryTraceRecorder()),
+		)
+		require.NoError(t, err)
+
+		_, ok := i.aggregateVerifier()
+		assert.True(t, ok)
+		assert.False(t, i.hasValidAggregatableSeal(tamperedPC().PrepareMessages[0]))
+	})
+
+	t.Run("backend without aggregation", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, verifier.mockBackend, mockTransport{})
+		require.NoError(t, i.validatorManager.Init(view.Height))
+
+		// Make sure the seals are ignored
+		assert.True(t, i.validPC(tamperedPC(), 2, 10))
+	})
+}
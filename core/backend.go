@@ -11,6 +11,7 @@ import (
 // All constructed messages must be signed by a validator for the whole message
 type MessageConstructor interface {
 	// BuildPrePrepareMessage builds a PREPREPARE message based on the passed in view and proposal
+	// If the seals are aggregated, must include the prepare seal for the PrepareDigest
 	BuildPrePrepareMessage(
 		rawProposal []byte,
 		certificate *proto.RoundChangeCertificate,
@@ -18,6 +19,7 @@ type MessageConstructor interface {
 	) *proto.Message
 
 	// BuildPrepareMessage builds a PREPARE message based on the passed in view and proposal hash
+	// If the seals are aggregated, must include the prepare seal for the PrepareDigest
 	BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message
 
 	// BuildCommitMessage builds a COMMIT message based on the passed in view and proposal hash
@@ -26,6 +28,7 @@ type MessageConstructor interface {
 
 	// BuildRoundChangeMessage builds a ROUND_CHANGE message based on the passed in view,
 	// latest prepared proposal, and latest prepared certificate
+	// If the seals are aggregated, must include the round change seal for the RoundChangeDigest
 	BuildRoundChangeMessage(
 		proposal *proto.Proposal,
 		certificate *proto.PreparedCertificate,
@@ -55,6 +58,26 @@ type Verifier interface {
 	IsValidCommittedSeal(proposalHash []byte, committedSeal *messages.CommittedSeal) bool
 }
 
+// AggregateVerifier defines the verifier of the aggregated seals. It is optionally implemented,
+// along with the Verifier, by the backends signing the seals with an aggregatable signature scheme,
+// like BLS, which enables the aggregated committed seals and the compact certificates
+type AggregateVerifier interface {
+	// AggregateSeals aggregates the seals of the validators for the same digest into a single signature
+	AggregateSeals(seals [][]byte) ([]byte, error)
+
+	// IsValidAggregatedSeal checks if the signature is aggregated
+	// from the seals of all the signers for the digest
+	IsValidAggregatedSeal(digest []byte, signers [][]byte, signature []byte) bool
+}
+
+// AggregationSwitch is optionally implemented, along with the AggregateVerifier, by the backends
+// which only aggregate the seals when they are configured to. While the aggregation is disabled,
+// the messages are not required to carry the aggregatable seals
+type AggregationSwitch interface {
+	// AggregationEnabled checks if the seals are aggregated
+	AggregationEnabled() bool
+}
+
 // Backend defines an interface all backend implementations
 // need to implement
 type Backend interface {
@@ -71,7 +94,11 @@ type Backend interface {
 	// InsertProposal inserts a proposal with the specified committed seals
 	// the reason why we are including round here is because a single committedSeal
 	// has signed the tuple of (rawProposal, round)
-	InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal)
+	InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error
+
+	// ReportEquivocation notifies the backend implementation whenever a validator
+	// is caught signing conflicting messages for the same view, so it can be punished
+	ReportEquivocation(evidence *messages.Evidence)
 
 	// ID returns the validator's ID
 	ID() []byte
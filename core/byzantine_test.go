@@ -38,12 +38,15 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+		cluster.stalled = stalledOnByzantineProposer(cluster)
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(20*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -71,12 +74,14 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(10*time.Second, 1)
+		cluster.useFakeClock()
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(10*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -105,13 +110,16 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+		cluster.stalled = stalledOnByzantineProposer(cluster)
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		// Max tolerant byzantine
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(40*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -140,12 +148,14 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(30*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -175,12 +185,15 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+		cluster.stalled = stalledOnByzantineProposer(cluster)
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(30*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -210,12 +223,15 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+		cluster.stalled = stalledOnByzantineProposer(cluster)
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(30*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -245,12 +261,14 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(30*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 
@@ -280,12 +298,14 @@ func TestByzantineBehaviour(t *testing.T) {
 			},
 		)
 
-		err := cluster.progressToHeight(20*time.Second, 1)
+		cluster.useFakeClock()
+
+		err := cluster.progressToHeight(5*time.Second, 1)
 		assert.NoError(t, err, "unable to reach height: %w", err)
 		assert.Equal(t, uint64(1), cluster.latestHeight)
 
 		cluster.makeNByzantine(int(cluster.maxFaulty()))
-		assert.NoError(t, cluster.progressToHeight(30*time.Second, 2))
+		assert.NoError(t, cluster.progressToHeight(5*time.Second, 2))
 		assert.Equal(t, uint64(2), cluster.latestHeight)
 	})
 }
@@ -361,6 +381,16 @@ func createBadHashPrepareMessageFn(node *node) buildPrepareMessageDelegate {
 	}
 }
 
+// stalledOnByzantineProposer stalls the rounds without a proposer and the rounds of the byzantine
+// proposers, since the honest nodes reject their proposals
+func stalledOnByzantineProposer(c *cluster) func(height, round uint64) bool {
+	return func(height, round uint64) bool {
+		proposer := c.proposer(height, round)
+
+		return proposer == nil || proposer.byzantine
+	}
+}
+
 func createForcedRCProposerFn(c *cluster) isProposerDelegate {
 	return func(from []byte, height uint64, round uint64) bool {
 		if round == 0 {
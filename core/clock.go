@@ -0,0 +1,79 @@
+package core
+
+import "time"
+
+// Clock is the source of time used by the IBFT state machine
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+
+	// NewTimer creates a timer that fires once after the duration d
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a ticker that fires every period d
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer represents a single event timer
+type Timer interface {
+	// C returns the channel the time is delivered on
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing
+	Stop() bool
+}
+
+// Ticker represents a periodic event timer
+type Ticker interface {
+	// C returns the channel the ticks are delivered on
+	C() <-chan time.Time
+
+	// Stop turns off the ticker
+	Stop()
+}
+
+// realClock is the Clock backed by the wall clock
+type realClock struct{}
+
+// Now returns the current wall clock time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the wall clock time elapsed since t
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// NewTimer creates a wall clock timer
+//
+//nolint:ireturn
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// NewTicker creates a wall clock ticker
+//
+//nolint:ireturn
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
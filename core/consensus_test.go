@@ -2,12 +2,14 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/Hydra-Chain/go-ibft/messages"
 	"github.com/Hydra-Chain/go-ibft/messages/proto"
@@ -211,8 +213,10 @@ func TestConsensus_ValidFlow(t *testing.T) {
 		}
 
 		// Make sure the inserted proposal is noted
-		backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+		backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 			insertedBlocks[nodeIndex] = proposal.RawProposal
+
+			return nil
 		}
 
 		// Set the proposal creation method
@@ -353,8 +357,10 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 		}
 
 		// Make sure the inserted proposal is noted
-		backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+		backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 			insertedBlocks[nodeIndex] = proposal.RawProposal
+
+			return nil
 		}
 
 		// Build proposal function
@@ -371,8 +377,8 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 		commonTransportCallback,
 	)
 
-	// Set the base timeout to be lower than usual
-	cluster.setBaseTimeout(2 * time.Second)
+	// Make sure the round timeouts expire only when the invalid block is rejected
+	cluster.useFakeClock()
 
 	// Set the multicast callback to relay the message
 	// to the entire cluster
@@ -381,6 +387,15 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 	// Start the main run loops
 	cluster.runSequence(1)
 
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	// Expire the round with the invalid block
+	if !cluster.expireRound(ctx, 0) {
+		cluster.forceShutdown()
+		t.Fatal("round 0 not expired")
+	}
+
 	// Wait until the main run loops finish
 	cluster.awaitCompletion()
 
@@ -392,3 +407,167 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 		assert.True(t, bytes.Equal(block, proposals[1]))
 	}
 }
+
+// TestConsensus_RoundSkip tests the following scenario:
+// N = 4
+//
+// - Node 0 is the proposer for height 1, round 0, but it never proposes
+// - Nodes 0 and 1 have a short round timeout, while nodes 2 and 3
+// have a round timeout longer than the test duration
+// - Nodes 0 and 1 move to round 1, and send out ROUND_CHANGE messages
+// - Nodes 2 and 3 notice the voting power above the faulty threshold is on round 1,
+// and move to round 1 without waiting for their round timeout to expire
+// - Node 1 is the proposer for height 1, round 1
+// - Node 1 proposes a valid block B
+// - All nodes go through the consensus states to insert the valid block B
+func TestConsensus_RoundSkip(t *testing.T) {
+	t.Parallel()
+
+	var (
+		multicastFn func(message *proto.Message)
+
+		proposal       = []byte("proposal")
+		proposalHash   = []byte("proposal hash")
+		committedSeal  = []byte("seal")
+		numNodes       = uint64(4)
+		nodes          = generateNodeAddresses(numNodes)
+		insertedBlocks = make([][]byte, numNodes)
+	)
+
+	// commonTransportCallback is the common method modification
+	// required for Transport, for all nodes
+	commonTransportCallback := func(transport *mockTransport, _ int) {
+		transport.multicastFn = func(message *proto.Message) {
+			multicastFn(message)
+		}
+	}
+
+	// commonBackendCallback is the common method modification required
+	// for the Backend, for all nodes
+	commonBackendCallback := func(backend *mockBackend, nodeIndex int) {
+		backend.getVotingPowerFn = testCommonGetVotingPowertFn(nodes)
+
+		// Make sure the node ID is properly relayed
+		backend.idFn = func() []byte {
+			return nodes[nodeIndex]
+		}
+
+		// Node 0 is the proposer for round 0,
+		// node 1 is the proposer for round 1
+		backend.isProposerFn = func(from []byte, _ uint64, round uint64) bool {
+			return bytes.Equal(from, nodes[round%numNodes])
+		}
+
+		backend.isValidProposalFn = func(newProposal []byte) bool {
+			return bytes.Equal(newProposal, proposal)
+		}
+
+		backend.isValidProposalHashFn = func(_ *proto.Proposal, hash []byte) bool {
+			return bytes.Equal(hash, proposalHash)
+		}
+
+		// Make sure the preprepare message is built correctly
+		backend.buildPrePrepareMessageFn = func(
+			rawProposal []byte,
+			certificate *proto.RoundChangeCertificate,
+			view *proto.View,
+		) *proto.Message {
+			return buildBasicPreprepareMessage(
+				rawProposal,
+				proposalHash,
+				certificate,
+				nodes[nodeIndex],
+				view,
+			)
+		}
+
+		// Make sure the prepare message is built correctly
+		backend.buildPrepareMessageFn = func(_ []byte, view *proto.View) *proto.Message {
+			return buildBasicPrepareMessage(proposalHash, nodes[nodeIndex], view)
+		}
+
+		// Make sure the commit message is built correctly
+		backend.buildCommitMessageFn = func(_ []byte, view *proto.View) *proto.Message {
+			return buildBasicCommitMessage(proposalHash, committedSeal, nodes[nodeIndex], view)
+		}
+
+		// Make sure the round change message is built correctly
+		backend.buildRoundChangeMessageFn = func(
+			proposal *proto.Proposal,
+			certificate *proto.PreparedCertificate,
+			view *proto.View,
+		) *proto.Message {
+			return buildBasicRoundChangeMessage(proposal, certificate, view, nodes[nodeIndex])
+		}
+
+		// Make sure the inserted proposal is noted
+		backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
+			insertedBlocks[nodeIndex] = proposal.RawProposal
+
+			return nil
+		}
+
+		// Node 0 is unable to build a proposal
+		backend.buildProposalFn = func(_ uint64) []byte {
+			if nodeIndex == 0 {
+				return nil
+			}
+
+			return proposal
+		}
+	}
+
+	// Create the mock cluster
+	cluster := newMockCluster(
+		numNodes,
+		commonBackendCallback,
+		nil,
+		commonTransportCallback,
+	)
+
+	cluster.useFakeClock()
+
+	// Nodes 2 and 3 would never time out during the test
+	for index, node := range cluster.nodes {
+		if index < 2 {
+			node.config.baseRoundTimeout = time.Second
+		} else {
+			node.config.baseRoundTimeout = time.Hour
+		}
+	}
+
+	// Set the multicast callback to relay the message
+	// to the entire cluster
+	multicastFn = cluster.pushMessage
+
+	// Start the main run loops
+	cluster.runSequence(1)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	// Expire the short round timeouts of nodes 0 and 1 only
+	timeout := cluster.nodes[0].getConfig().roundTimeout(0)
+	if !awaitTimers(ctx, cluster.clock, timeout, 2) {
+		cluster.forceShutdown()
+		t.Fatal("round 0 timers not started")
+	}
+
+	cluster.clock.Advance(timeout)
+
+	// Make sure all nodes finish the sequence without waiting for the long timeouts
+	err := cluster.awaitNCompletions(ctx, int64(numNodes))
+	if err != nil {
+		cluster.forceShutdown()
+	}
+
+	require.NoError(t, err)
+
+	// Make sure the nodes switched to the new round
+	assert.True(t, cluster.areAllNodesOnRound(1))
+
+	// Make sure the inserted blocks match what node 1 proposed
+	for _, block := range insertedBlocks {
+		assert.True(t, bytes.Equal(block, proposal))
+	}
+}
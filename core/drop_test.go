@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,8 +45,10 @@ func TestDropAllAndRecover(t *testing.T) {
 						buildCommitMessageFn:      node.buildCommit,
 						buildRoundChangeMessageFn: node.buildRoundChange,
 
-						insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+						insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 							insertedBlocks[i] = proposal.RawProposal
+
+							return nil
 						},
 						getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
 					},
@@ -61,6 +64,8 @@ func TestDropAllAndRecover(t *testing.T) {
 		},
 	)
 
+	cluster.useFakeClock()
+
 	// Progress the chain to claim it works ok by default
 	err := cluster.progressToHeight(5*time.Second, 1)
 	assert.NoError(t, err, "unable to reach height: %w", err)
@@ -121,8 +126,10 @@ func TestRecoverFromDifferentRounds(t *testing.T) {
 					buildCommitMessageFn:      node.buildCommit,
 					buildRoundChangeMessageFn: node.buildRoundChange,
 
-					insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+					insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 						insertedBlocks[i] = proposal.RawProposal
+
+						return nil
 					},
 					getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
 				}
@@ -141,6 +148,8 @@ func TestRecoverFromDifferentRounds(t *testing.T) {
 					roundExpired:     make(chan struct{}),
 					newProposal:      make(chan newProposalEvent),
 					roundCertificate: make(chan uint64),
+					roundSkip:        make(chan uint64),
+					futureHeight:     make(chan uint64, 1),
 					state: &mockState{
 						state: &state{
 							view: &proto.View{
@@ -153,13 +162,15 @@ func TestRecoverFromDifferentRounds(t *testing.T) {
 						},
 						desiredStartRound: desiredRounds[nodeIndex],
 					},
-					baseRoundTimeout: DefaultBaseRoundTimeout,
+					config:           defaultConfig(),
 					validatorManager: NewValidatorManager(backend, mockLogger{}),
 				}
 			}
 		},
 	)
 
+	cluster.useFakeClock()
+
 	// Progress the chain to claim it works ok
 	err := cluster.progressToHeight(180*time.Second, 1)
 	assert.NoError(t, err, "unable to reach height: %w", err)
@@ -192,6 +203,28 @@ func assertValidInsertedBlocks(t *testing.T, blocks [][]byte) {
 func TestMaxFaultyDroppingMessages(t *testing.T) {
 	t.Parallel()
 
+	var (
+		dropsLock sync.Mutex
+		drops     = make(map[[2]uint64]bool)
+	)
+
+	// dropsProposal decides once if the faulty proposer drops its proposal for the view,
+	// so the round is known to be stalled, even if the proposal is re-sent
+	dropsProposal := func(height, round uint64) bool {
+		dropsLock.Lock()
+		defer dropsLock.Unlock()
+
+		view := [2]uint64{height, round}
+
+		drop, ok := drops[view]
+		if !ok {
+			drop = rand.Intn(100) < 50
+			drops[view] = drop
+		}
+
+		return drop
+	}
+
 	cluster := newCluster(
 		6,
 		func(c *cluster) {
@@ -218,8 +251,15 @@ func TestMaxFaultyDroppingMessages(t *testing.T) {
 						getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
 					},
 					&mockTransport{multicastFn: func(message *proto.Message) {
-						if currentNode.faulty && rand.Intn(100) < 50 {
-							return
+						if currentNode.faulty {
+							drop := rand.Intn(100) < 50
+							if message.Type == proto.MessageType_PREPREPARE {
+								drop = dropsProposal(message.View.Height, message.View.Round)
+							}
+
+							if drop {
+								return
+							}
 						}
 
 						c.gossip(message)
@@ -229,6 +269,16 @@ func TestMaxFaultyDroppingMessages(t *testing.T) {
 		},
 	)
 
+	cluster.useFakeClock()
+
+	// The other messages of the faulty nodes are not needed for the quorum,
+	// so only the rounds of the dropped proposals are stalled
+	cluster.stalled = func(height, round uint64) bool {
+		proposer := cluster.proposer(height, round)
+
+		return proposer.faulty && dropsProposal(height, round)
+	}
+
 	cluster.makeNFaulty(int(cluster.maxFaulty()))
 	assert.NoError(t, cluster.progressToHeight(40*time.Second, 5))
 	assert.Equal(t, uint64(5), cluster.latestHeight)
@@ -265,16 +315,16 @@ func TestAllFailAndGraduallyRecover(t *testing.T) {
 						buildCommitMessageFn:      node.buildCommit,
 						buildRoundChangeMessageFn: node.buildRoundChange,
 
-						insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+						insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 							insertedBlocks[nodeIndex] = proposal.RawProposal
+
+							return nil
 						},
 						getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
 					},
 					&mockTransport{multicastFn: func(msg *proto.Message) {
 						if !currentNode.offline {
-							for _, node := range c.nodes {
-								node.core.AddMessage(msg)
-							}
+							c.gossip(msg)
 						}
 					}},
 				)
@@ -282,6 +332,8 @@ func TestAllFailAndGraduallyRecover(t *testing.T) {
 		},
 	)
 
+	cluster.useFakeClock()
+
 	// Start the main run loops
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 
@@ -291,6 +343,14 @@ func TestAllFailAndGraduallyRecover(t *testing.T) {
 
 	cluster.runGradualSequence(ctx, 1)
 
+	// Start the nodes one by one, by moving the fake clock past the start delays.
+	// The round timeouts are longer than all the delays, so none of the rounds expire
+	cluster.clock.BlockUntil(int(numNodes))
+
+	for elapsed := time.Duration(0); elapsed < time.Duration(numNodes)*time.Second; elapsed += 100 * time.Millisecond {
+		cluster.clock.Advance(100 * time.Millisecond)
+	}
+
 	// Wait until the main run loops finish
 	cluster.wg.Wait()
 
@@ -342,6 +402,8 @@ func TestDropMaxFaultyPlusOne(t *testing.T) {
 		},
 	)
 
+	cluster.useFakeClock()
+
 	err := cluster.progressToHeight(5*time.Second, 5)
 	assert.NoError(t, err, "unable to reach height: %w", err)
 
@@ -351,11 +413,19 @@ func TestDropMaxFaultyPlusOne(t *testing.T) {
 
 	cluster.stopN(offline)
 
+	// The online nodes don't have the quorum, so none of the rounds can finish.
+	// Only the first rounds are expired, the nodes wait in the last one until the deadline
+	cluster.stalled = func(_, round uint64) bool {
+		return round < 3
+	}
+
 	assert.Error(t, cluster.progressToHeight(2*time.Second, 10))
 	assert.Equal(t, uint64(5), cluster.latestHeight)
 
 	cluster.startN(offline)
 
+	cluster.stalled = nil
+
 	assert.NoError(t, cluster.progressToHeight(5*time.Second, 10))
 	assert.Equal(t, uint64(10), cluster.latestHeight)
 }
@@ -400,6 +470,8 @@ func TestDropMaxFaulty(t *testing.T) {
 		},
 	)
 
+	cluster.useFakeClock()
+
 	err := cluster.progressToHeight(5*time.Second, 5)
 	assert.NoError(t, err, "unable to reach height: %w", err)
 
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is the Clock which only moves forward when advanced manually,
+// so the timing of the IBFT state machine can be controlled in tests
+type FakeClock struct {
+	lock sync.Mutex
+
+	now time.Time
+
+	// waiters are the active timers and tickers
+	waiters map[*fakeWaiter]struct{}
+
+	// waitersChanged is closed, and replaced, when a new waiter is added
+	waitersChanged chan struct{}
+}
+
+// fakeWaiter is a timer or ticker of the fake clock
+type fakeWaiter struct {
+	clock *FakeClock
+
+	ch       chan time.Time
+	deadline time.Time
+
+	// period is the ticker period, which is zero for timers
+	period time.Duration
+}
+
+// NewFakeClock creates a new fake clock, set to the passed in time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:            now,
+		waiters:        make(map[*fakeWaiter]struct{}),
+		waitersChanged: make(chan struct{}),
+	}
+}
+
+// Now returns the current time of the fake clock
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// Since returns the fake clock time elapsed since t
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// NewTimer creates a timer that fires once the fake clock is advanced by d
+//
+//nolint:ireturn
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.addWaiter(d, 0)
+}
+
+// NewTicker creates a ticker that fires each time the fake clock is advanced by d
+//
+//nolint:ireturn
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+
+	return fakeTicker{c.addWaiter(d, d)}
+}
+
+// Advance moves the fake clock forward by d, and fires the timers
+// and tickers which are due, in the order of their deadlines
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	end := c.now.Add(d)
+
+	for {
+		due := c.dueWaiters(end)
+		if len(due) == 0 {
+			break
+		}
+
+		w := due[0]
+		c.now = w.deadline
+
+		// Like the wall clock timers, the ticks are dropped
+		// if the previous ones were not received yet
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+
+		if w.period == 0 {
+			delete(c.waiters, w)
+
+			continue
+		}
+
+		w.deadline = w.deadline.Add(w.period)
+	}
+
+	c.now = end
+}
+
+// BlockUntil blocks until at least n timers and tickers are active.
+// It is used for making sure the state machine is waiting
+// on the fake clock before advancing it
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.lock.Lock()
+		active, changed := len(c.waiters), c.waitersChanged
+		c.lock.Unlock()
+
+		if active >= n {
+			return
+		}
+
+		<-changed
+	}
+}
+
+// Waiters returns the number of active timers and tickers
+func (c *FakeClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.waiters)
+}
+
+// addWaiter adds a new timer, or a ticker if the period is set
+func (c *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	w := &fakeWaiter{
+		clock:    c,
+		ch:       make(chan time.Time, 1),
+		deadline: c.now.Add(d),
+		period:   period,
+	}
+
+	c.waiters[w] = struct{}{}
+
+	close(c.waitersChanged)
+	c.waitersChanged = make(chan struct{})
+
+	return w
+}
+
+// dueWaiters returns the waiters with deadlines up to the end time,
+// sorted by their deadlines. The caller must hold the lock
+func (c *FakeClock) dueWaiters(end time.Time) []*fakeWaiter {
+	due := make([]*fakeWaiter, 0, len(c.waiters))
+
+	for w := range c.waiters {
+		if !w.deadline.After(end) {
+			due = append(due, w)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+
+	return due
+}
+
+// C returns the channel the time is delivered on
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop removes the waiter from the fake clock,
+// and returns true if it was active
+func (w *fakeWaiter) Stop() bool {
+	w.clock.lock.Lock()
+	defer w.clock.lock.Unlock()
+
+	_, active := w.clock.waiters[w]
+	delete(w.clock.waiters, w)
+
+	return active
+}
+
+// fakeTicker is the ticker of the fake clock
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+// Stop turns off the ticker
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// fired checks if the channel received a value
+func fired(ch <-chan time.Time) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeClock_Timer(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+
+	timer := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+
+	assert.Equal(t, 2, clock.Waiters())
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	// Make sure the timer does not fire before its deadline
+	clock.Advance(500 * time.Millisecond)
+	assert.False(t, fired(timer.C()))
+	assert.Equal(t, 500*time.Millisecond, clock.Since(start))
+
+	// Make sure the timer fires once the deadline is reached
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.False(t, fired(stopped.C()))
+
+	// Make sure the timer fires only once
+	clock.Advance(time.Hour)
+	assert.False(t, fired(timer.C()))
+	assert.False(t, timer.Stop())
+	assert.Equal(t, 0, clock.Waiters())
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+
+	// Make sure the ticks that are not received are dropped
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+	assert.False(t, fired(ticker.C()))
+
+	ticker.Stop()
+	clock.Advance(time.Second)
+	assert.False(t, fired(ticker.C()))
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		clock.BlockUntil(2)
+	}()
+
+	clock.NewTimer(time.Second)
+	clock.NewTimer(2 * time.Second)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not unblocked")
+	}
+}
+
+// TestIBFT_FakeClock makes sure the round timeouts are driven by the clock,
+// by running a sequence which requires a round change without waiting
+// for the round timeout on the wall clock
+func TestIBFT_FakeClock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		numNodes       = uint64(4)
+		insertedBlocks = make([][]byte, numNodes)
+		clock          = NewFakeClock(time.Unix(0, 0))
+	)
+
+	cluster := newCluster(
+		numNodes,
+		func(c *cluster) {
+			for nodeIndex, node := range c.nodes {
+				var (
+					i           = nodeIndex
+					currentNode = node
+					err         error
+				)
+
+				node.core, err = NewIBFTWithOptions(
+					mockLogger{},
+					&mockBackend{
+						isValidProposalFn:     isValidProposal,
+						isValidProposalHashFn: isValidProposalHash,
+						isProposerFn: func(from []byte, _, round uint64) bool {
+							return bytes.Equal(from, c.nodes[round%numNodes].address)
+						},
+
+						idFn: node.addr,
+
+						buildProposalFn:           buildValidEthereumBlock,
+						buildPrePrepareMessageFn:  node.buildPrePrepare,
+						buildPrepareMessageFn:     node.buildPrepare,
+						buildCommitMessageFn:      node.buildCommit,
+						buildRoundChangeMessageFn: node.buildRoundChange,
+
+						insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
+							insertedBlocks[i] = proposal.RawProposal
+
+							return nil
+						},
+						getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
+					},
+					&mockTransport{multicastFn: func(message *proto.Message) {
+						if currentNode.offline {
+							return
+						}
+
+						c.gossip(message)
+					}},
+					WithClock(clock),
+					WithBaseRoundTimeout(time.Hour),
+					WithCommitGracePeriod(0),
+				)
+				require.NoError(t, err)
+			}
+		},
+	)
+
+	// Node 0 is the proposer for round 0, but it is offline
+	cluster.stopN(1)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	done := cluster.runSequence(ctx, 1)
+
+	// Wait for the online nodes to start their round timers, and expire them
+	clock.BlockUntil(int(numNodes) - 1)
+	clock.Advance(time.Hour)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		<-done
+		t.Fatal("sequence not finished")
+	}
+
+	// Make sure the online nodes inserted the block proposed in round 1
+	for _, block := range insertedBlocks[1:] {
+		assert.Equal(t, validEthereumBlock, block)
+	}
+}
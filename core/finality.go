@@ -0,0 +1,189 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+var (
+	// ErrInvalidCertificate is returned when the finality certificate is malformed
+	ErrInvalidCertificate = errors.New("invalid finality certificate")
+
+	// ErrValidatorSetMismatch is returned when the finality certificate
+	// references a different validator set than the one it is verified against
+	ErrValidatorSetMismatch = errors.New("validator set mismatch")
+
+	// ErrHeightMismatch is returned when the finality certificate
+	// is for a different height than the one it is verified for
+	ErrHeightMismatch = errors.New("height mismatch")
+
+	// ErrInvalidSeal is returned when a seal of the finality certificate
+	// is not signed by a validator, or is included more than once
+	ErrInvalidSeal = errors.New("invalid committed seal")
+
+	// ErrNoQuorum is returned when the seals of the finality certificate
+	// don't have the quorum voting power
+	ErrNoQuorum = errors.New("quorum not reached")
+
+	// ErrInvalidVotingPower is returned when the voting power
+	// of a validator is not set, or is negative
+	ErrInvalidVotingPower = errors.New("invalid voting power")
+)
+
+// CommittedSealVerifier verifies the signatures of the committed seals
+type CommittedSealVerifier interface {
+	// IsValidCommittedSeal checks
+	// if signature for proposal hash in committed seal is signed by a validator
+	IsValidCommittedSeal(proposalHash []byte, committedSeal *messages.CommittedSeal) bool
+}
+
+// ValidatorSetHash returns the hash of the validator set, which is
+// referenced by the finality certificates finalized with it.
+// The hash does not depend on the order of the map.
+// An error is returned if a voting power is not set, or is negative
+func ValidatorSetHash(votingPowers map[string]*big.Int) ([]byte, error) {
+	addresses := make([]string, 0, len(votingPowers))
+	for address, votingPower := range votingPowers {
+		if votingPower == nil || votingPower.Sign() < 0 {
+			return nil, fmt.Errorf("%w: validator %x", ErrInvalidVotingPower, address)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	sort.Strings(addresses)
+
+	var (
+		hash   = sha256.New()
+		length = make([]byte, 8)
+	)
+
+	// Each address and voting power is prefixed by its length,
+	// so different validator sets can't have the same encoding
+	for _, address := range addresses {
+		votingPower := votingPowers[address].Bytes()
+
+		binary.BigEndian.PutUint64(length, uint64(len(address)))
+		hash.Write(length)
+		hash.Write([]byte(address))
+
+		binary.BigEndian.PutUint64(length, uint64(len(votingPower)))
+		hash.Write(length)
+		hash.Write(votingPower)
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// NewFinalityCertificate creates the finality certificate of the proposal
+// finalized in the view with the committed seals of the validator set
+func NewFinalityCertificate(
+	view *proto.View,
+	proposalHash []byte,
+	committedSeals []*messages.CommittedSeal,
+	votingPowers map[string]*big.Int,
+) (*proto.FinalityCertificate, error) {
+	validatorSetHash, err := ValidatorSetHash(votingPowers)
+	if err != nil {
+		return nil, err
+	}
+
+	seals := make([]*proto.CommittedSeal, 0, len(committedSeals))
+	for _, seal := range committedSeals {
+		seals = append(seals, &proto.CommittedSeal{
+			Signer:    seal.Signer,
+			Signature: seal.Signature,
+		})
+	}
+
+	return &proto.FinalityCertificate{
+		View: &proto.View{
+			Height: view.Height,
+			Round:  view.Round,
+		},
+		ProposalHash:     proposalHash,
+		Seals:            seals,
+		ValidatorSetHash: validatorSetHash,
+	}, nil
+}
+
+// VerifyFinalityCertificate verifies the finality certificate of the height against
+// the validator set of the height. The seals must be signed by distinct validators, which together
+// have the quorum voting power, as calculated by the ValidatorManager
+func VerifyFinalityCertificate(
+	certificate *proto.FinalityCertificate,
+	height uint64,
+	votingPowers map[string]*big.Int,
+	verifier CommittedSealVerifier,
+) error {
+	if certificate == nil || certificate.View == nil || len(certificate.ProposalHash) == 0 {
+		return ErrInvalidCertificate
+	}
+
+	// The seals don't sign the height, so the certificate
+	// of one height must not be accepted for another
+	if certificate.View.Height != height {
+		return fmt.Errorf("%w: certificate height %d, expected %d", ErrHeightMismatch, certificate.View.Height, height)
+	}
+
+	validatorSetHash, err := ValidatorSetHash(votingPowers)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(certificate.ValidatorSetHash, validatorSetHash) {
+		return ErrValidatorSetMismatch
+	}
+
+	totalVotingPower := calculateTotalVotingPower(votingPowers)
+	if totalVotingPower.Sign() <= 0 {
+		return errVotingPowerNotCorrect
+	}
+
+	var (
+		signers         = make(map[string]struct{}, len(certificate.Seals))
+		sealVotingPower = big.NewInt(0)
+	)
+
+	for _, seal := range certificate.Seals {
+		if seal == nil {
+			return ErrInvalidCertificate
+		}
+
+		votingPower, ok := votingPowers[string(seal.Signer)]
+		if !ok {
+			return fmt.Errorf("%w: signer %x is not a validator", ErrInvalidSeal, seal.Signer)
+		}
+
+		if _, ok := signers[string(seal.Signer)]; ok {
+			return fmt.Errorf("%w: duplicate seal of signer %x", ErrInvalidSeal, seal.Signer)
+		}
+
+		committedSeal := &messages.CommittedSeal{
+			Signer:    seal.Signer,
+			Signature: seal.Signature,
+		}
+
+		if !verifier.IsValidCommittedSeal(certificate.ProposalHash, committedSeal) {
+			return fmt.Errorf("%w: invalid signature of signer %x", ErrInvalidSeal, seal.Signer)
+		}
+
+		signers[string(seal.Signer)] = struct{}{}
+
+		sealVotingPower.Add(sealVotingPower, votingPower)
+	}
+
+	if sealVotingPower.Cmp(calculateQuorum(totalVotingPower)) < 0 {
+		return ErrNoQuorum
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestValidatorSetHash(t *testing.T) {
+	t.Parallel()
+
+	votingPowers := map[string]*big.Int{
+		"A": big.NewInt(1),
+		"B": big.NewInt(2),
+	}
+
+	hash := func(votingPowers map[string]*big.Int) []byte {
+		validatorSetHash, err := ValidatorSetHash(votingPowers)
+		require.NoError(t, err)
+
+		return validatorSetHash
+	}
+
+	// Make sure the hash is deterministic
+	assert.Equal(t, hash(votingPowers), hash(map[string]*big.Int{
+		"B": big.NewInt(2),
+		"A": big.NewInt(1),
+	}))
+
+	// Make sure the hash depends on the addresses and voting powers
+	assert.NotEqual(t, hash(votingPowers), hash(map[string]*big.Int{
+		"A": big.NewInt(2),
+		"B": big.NewInt(1),
+	}))
+	assert.NotEqual(t, hash(votingPowers), hash(map[string]*big.Int{
+		"A":  big.NewInt(1),
+		"B":  big.NewInt(2),
+		"AB": big.NewInt(1),
+	}))
+
+	// Make sure the invalid voting powers are rejected
+	_, err := ValidatorSetHash(map[string]*big.Int{"A": big.NewInt(1), "B": nil})
+	assert.ErrorIs(t, err, ErrInvalidVotingPower)
+
+	_, err = ValidatorSetHash(map[string]*big.Int{"A": big.NewInt(1), "B": big.NewInt(-2)})
+	assert.ErrorIs(t, err, ErrInvalidVotingPower)
+}
+
+func TestVerifyFinalityCertificate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		view         = &proto.View{Height: 10, Round: 2}
+
+		// The quorum of the total voting power 10 is 7
+		votingPowers = map[string]*big.Int{
+			"A": big.NewInt(4),
+			"B": big.NewInt(3),
+			"C": big.NewInt(2),
+			"D": big.NewInt(1),
+		}
+
+		verifier = mockBackend{
+			isValidCommittedSealFn: func(hash []byte, seal *messages.CommittedSeal) bool {
+				return bytes.Equal(hash, proposalHash) && bytes.Equal(seal.Signature, seal.Signer)
+			},
+		}
+	)
+
+	seals := func(signers ...string) []*messages.CommittedSeal {
+		committedSeals := make([]*messages.CommittedSeal, 0, len(signers))
+		for _, signer := range signers {
+			committedSeals = append(committedSeals, &messages.CommittedSeal{
+				Signer:    []byte(signer),
+				Signature: []byte(signer),
+			})
+		}
+
+		return committedSeals
+	}
+
+	certificate := func(
+		view *proto.View,
+		proposalHash []byte,
+		committedSeals []*messages.CommittedSeal,
+		votingPowers map[string]*big.Int,
+	) *proto.FinalityCertificate {
+		finalityCertificate, err := NewFinalityCertificate(view, proposalHash, committedSeals, votingPowers)
+		require.NoError(t, err)
+
+		return finalityCertificate
+	}
+
+	testTable := []struct {
+		name        string
+		certificate *proto.FinalityCertificate
+		expectedErr error
+	}{
+		{
+			"valid certificate",
+			certificate(view, proposalHash, seals("A", "B"), votingPowers),
+			nil,
+		},
+		{
+			"no quorum",
+			certificate(view, proposalHash, seals("A", "C"), votingPowers),
+			ErrNoQuorum,
+		},
+		{
+			"duplicate seals",
+			certificate(view, proposalHash, seals("A", "C", "C", "D"), votingPowers),
+			ErrInvalidSeal,
+		},
+		{
+			"signer not a validator",
+			certificate(view, proposalHash, seals("A", "B", "E"), votingPowers),
+			ErrInvalidSeal,
+		},
+		{
+			"invalid signature",
+			certificate(view, []byte("other hash"), seals("A", "B"), votingPowers),
+			ErrInvalidSeal,
+		},
+		{
+			"different validator set",
+			certificate(view, proposalHash, seals("A", "B"), map[string]*big.Int{
+				"A": big.NewInt(4),
+				"B": big.NewInt(3),
+			}),
+			ErrValidatorSetMismatch,
+		},
+		{
+			"different height",
+			certificate(
+				&proto.View{Height: view.Height + 1, Round: view.Round},
+				proposalHash,
+				seals("A", "B"),
+				votingPowers,
+			),
+			ErrHeightMismatch,
+		},
+		{
+			"missing view",
+			&proto.FinalityCertificate{ProposalHash: proposalHash},
+			ErrInvalidCertificate,
+		},
+		{
+			"missing certificate",
+			nil,
+			ErrInvalidCertificate,
+		},
+	}
+
+	t.Run("invalid voting power", func(t *testing.T) {
+		t.Parallel()
+
+		err := VerifyFinalityCertificate(
+			certificate(view, proposalHash, seals("A", "B"), votingPowers),
+			view.Height,
+			map[string]*big.Int{"A": big.NewInt(4), "B": nil},
+			verifier,
+		)
+
+		assert.ErrorIs(t, err, ErrInvalidVotingPower)
+	})
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyFinalityCertificate(testCase.certificate, view.Height, votingPowers, verifier)
+			if testCase.expectedErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, testCase.expectedErr)
+		})
+	}
+}
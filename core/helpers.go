@@ -6,18 +6,24 @@ import (
 	"time"
 )
 
-func runTaskPeriodically(ctx context.Context, wg *sync.WaitGroup, task func(), interval time.Duration) {
+func runTaskPeriodically(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	task func(),
+	clock Clock,
+	interval time.Duration,
+) {
 	wg.Add(1)
 	task()
 
 	defer wg.Done()
 
-	ticker := time.NewTicker(interval)
+	ticker := clock.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			task()
 		case <-ctx.Done():
 			return
@@ -110,7 +110,47 @@ func (n *node) runSequence(ctx context.Context, height uint64) {
 	ctxSequence, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	n.core.RunSequence(ctxSequence, height)
+	_, _ = n.core.RunSequence(ctxSequence, height)
+}
+
+// countTimers returns the number of the active timers of the fake clock, which expire after d.
+// The tickers are not counted
+func countTimers(clock *FakeClock, d time.Duration) int {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+
+	var (
+		deadline = clock.now.Add(d)
+		count    = 0
+	)
+
+	for w := range clock.waiters {
+		if w.period == 0 && w.deadline.Equal(deadline) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// awaitTimers blocks until at least n timers of the fake clock, which expire after d,
+// are active. It returns false if the context is done first
+func awaitTimers(ctx context.Context, clock *FakeClock, d time.Duration, n int) bool {
+	for {
+		clock.lock.Lock()
+		changed := clock.waitersChanged
+		clock.lock.Unlock()
+
+		if countTimers(clock, d) >= n {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-changed:
+		}
+	}
 }
 
 type cluster struct {
@@ -118,6 +158,14 @@ type cluster struct {
 	wg    sync.WaitGroup
 
 	latestHeight uint64
+
+	// clock is the fake clock of the nodes, if they use one
+	clock *FakeClock
+
+	// stalled checks if the round of the height can't finish, so the round
+	// has to be expired by moving the fake clock forward. If it is not set,
+	// the rounds without an online proposer are stalled
+	stalled func(height, round uint64) bool
 }
 
 func newCluster(num uint64, init func(*cluster)) *cluster {
@@ -136,18 +184,39 @@ func newCluster(num uint64, init func(*cluster)) *cluster {
 	return c
 }
 
+// useFakeClock switches the nodes to a fake clock. The clock is only moved forward
+// to expire the stalled rounds (see expireStalledRounds), so the nodes never time out
+// while they can still finish the round. The commit grace period is turned off,
+// since the fake clock is not moved forward while the nodes wait for more commit messages
+func (c *cluster) useFakeClock() {
+	c.clock = NewFakeClock(time.Unix(0, 0))
+	c.setClock(c.clock)
+
+	for _, n := range c.nodes {
+		n.core.config.commitGracePeriod = 0
+	}
+}
+
+// setClock sets the source of time for the timers of the nodes
+func (c *cluster) setClock(clock Clock) {
+	for _, n := range c.nodes {
+		n.core.config.clock = clock
+	}
+}
+
 func (c *cluster) runGradualSequence(ctx context.Context, height uint64) {
 	for nodeIndex, n := range c.nodes {
 		c.wg.Add(1)
 
 		go func(ctx context.Context, ordinal int, node *node) {
 			// Start the main run loop for the node
-			runDelay := ordinal * rand.Intn(1000)
+			runDelay := node.core.getConfig().clock.NewTimer(time.Duration(ordinal*rand.Intn(1000)) * time.Millisecond)
+			defer runDelay.Stop()
 
 			select {
 			case <-ctx.Done():
-			case <-time.After(time.Duration(runDelay) * time.Millisecond):
-				node.core.RunSequence(ctx, height)
+			case <-runDelay.C():
+				_, _ = node.core.RunSequence(ctx, height)
 			}
 
 			c.wg.Done()
@@ -181,6 +250,10 @@ func (c *cluster) runSequences(ctx context.Context, height uint64) error {
 	for current := c.latestHeight + 1; current <= height; current++ {
 		sequenceDone := c.runSequence(ctx, current)
 
+		if c.clock != nil {
+			c.expireStalledRounds(ctx, current, sequenceDone)
+		}
+
 		select {
 		case <-sequenceDone:
 			c.latestHeight = current
@@ -195,6 +268,88 @@ func (c *cluster) runSequences(ctx context.Context, height uint64) error {
 	return nil
 }
 
+// expireStalledRounds moves the fake clock forward by the round timeout each time the running nodes
+// wait for a round which can't finish, until the sequence is done, or the context is done.
+// The clock is moved only after all the running nodes started their round timers,
+// so they all move on to the next round together
+func (c *cluster) expireStalledRounds(ctx context.Context, height uint64, done <-chan struct{}) {
+	for {
+		c.clock.lock.Lock()
+		changed := c.clock.waitersChanged
+		c.clock.lock.Unlock()
+
+		if round, ok := c.stalledRound(height); ok {
+			c.clock.Advance(c.nodes[0].core.getConfig().roundTimeout(round))
+
+			continue
+		}
+
+		// Each round started by a node starts a new round timer,
+		// so the round is checked again once the timers change
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// stalledRound returns the round the honest running nodes wait for, if it can't finish,
+// and all of them started their round timers. The byzantine nodes are not waited for,
+// since they don't need to follow the rounds of the others
+func (c *cluster) stalledRound(height uint64) (uint64, bool) {
+	var (
+		round   uint64
+		running = 0
+	)
+
+	for _, n := range c.nodes {
+		if n.offline || n.byzantine {
+			continue
+		}
+
+		view := n.core.state.getView()
+		if view.Height != height || (running > 0 && view.Round != round) {
+			return 0, false
+		}
+
+		round = view.Round
+		running++
+	}
+
+	if running == 0 || !c.isStalled(height, round) {
+		return 0, false
+	}
+
+	timeout := c.nodes[0].core.getConfig().roundTimeout(round)
+
+	return round, countTimers(c.clock, timeout) >= running
+}
+
+// isStalled checks if the round of the height can't finish
+func (c *cluster) isStalled(height, round uint64) bool {
+	if c.stalled != nil {
+		return c.stalled(height, round)
+	}
+
+	proposer := c.proposer(height, round)
+
+	return proposer == nil || proposer.offline
+}
+
+// proposer returns the proposer of the round of the height, if any
+func (c *cluster) proposer(height, round uint64) *node {
+	for _, n := range c.nodes {
+		if n.core.backend.IsProposer(n.address, height, round) {
+			return n
+		}
+	}
+
+	return nil
+}
+
 func (c *cluster) progressToHeight(timeout time.Duration, height uint64) error {
 	if c.latestHeight >= height {
 		panic("height already reached")
@@ -234,14 +389,18 @@ func (c *cluster) gossip(msg *proto.Message) {
 	}
 }
 
+// maxFaulty returns the most nodes which can be faulty, without the others losing the quorum
 func (c *cluster) maxFaulty() uint64 {
+	return uint64(len(c.nodes)) - c.minQuorumNodes()
+}
+
+// minQuorumNodes returns the least number of nodes which have the quorum
+func (c *cluster) minQuorumNodes() uint64 {
 	totalVotingPower := big.NewInt(int64(len(c.nodes)) * testVotingPower)
 	quorum := calculateQuorum(totalVotingPower)
 	one := big.NewInt(1)
-	minQuorumNodes := quorum.Sub(quorum, one).Div(quorum, big.NewInt(testVotingPower)).Add(quorum, one).Uint64()
 
-	// - 1 because for loops for changing nodes behavior start from 0
-	return uint64(len(c.nodes)) - minQuorumNodes - 1
+	return quorum.Sub(quorum, one).Div(quorum, big.NewInt(testVotingPower)).Add(quorum, one).Uint64()
 }
 
 func (c *cluster) makeNByzantine(num int) {
@@ -3,7 +3,7 @@ package core
 import (
 	"context"
 	"errors"
-	"math"
+	"fmt"
 	"sync"
 	"time"
 
@@ -22,9 +22,11 @@ type Logger interface {
 // Messages represents the message managing behaviour
 type Messages interface {
 	// Messages modifiers //
-	AddMessage(message *proto.Message)
+	AddMessage(message *proto.Message) *messages.Evidence
 	PruneByHeight(height uint64)
 
+	// SignalEvent notifies the subscribers of the message type and view, if the stored
+	// messages reach their thresholds. The subscribers are notified by AddMessage already
 	SignalEvent(messageType proto.MessageType, view *proto.View)
 
 	// Messages fetchers //
@@ -39,10 +41,33 @@ type Messages interface {
 		isValidRCC func(round uint64, msgs []*proto.Message) bool,
 	) []*proto.Message
 	GetMostRoundChangeMessages(minRound, height uint64) []*proto.Message
+	GetRoundChangeMessages(minRound, height uint64) []*proto.Message
 
 	// Messages subscription handlers //
 	Subscribe(details messages.SubscriptionDetails) *messages.Subscription
 	Unsubscribe(id messages.SubscriptionID)
+
+	Close()
+}
+
+// ViewTracker is implemented by the message storage layers
+// which need to know the current view of the state machine,
+// for instance for bounding the stored messages
+type ViewTracker interface {
+	// SetView is called whenever the state machine moves to a new view
+	SetView(view *proto.View)
+}
+
+// ValidationCacher is implemented by the message storage layers
+// which cache the message validation results, so the stored messages
+// are not validated again on each new message arrival
+type ValidationCacher interface {
+	// CachedValidation wraps the validation function with the cache
+	// for the specified validation context
+	CachedValidation(
+		context messages.ValidationContext,
+		isValid func(message *proto.Message) bool,
+	) func(message *proto.Message) bool
 }
 
 // State represents the IBFT state
@@ -72,9 +97,16 @@ type State interface {
 const (
 	// DefaultBaseRoundTimeout is the default base round (round 0) timeout
 	DefaultBaseRoundTimeout = 10 * time.Second
-	roundFactorBase         = float64(2)
-	longRoundThreshold      = 5 // Rounds above that are considered too long, so additional sync mechanisms are applied
-	periodicTaskInteval     = 30 * time.Second
+	// DefaultLongRoundThreshold is the default round above which rounds are considered too long,
+	// so additional sync mechanisms are applied
+	DefaultLongRoundThreshold = 5
+	// DefaultPeriodicTaskInterval is the default interval on which messages are re-sent in long rounds
+	DefaultPeriodicTaskInterval = 30 * time.Second
+	// DefaultCommitGracePeriod is the default time waited for more commit messages after the commit quorum
+	DefaultCommitGracePeriod = 200 * time.Millisecond
+	// DefaultSealCollectionTarget is the default voting power percentage of the commit messages
+	// which ends the commit grace period early, meaning all the validators
+	DefaultSealCollectionTarget = 100
 )
 
 var (
@@ -118,11 +150,20 @@ type IBFT struct {
 	// one is present
 	roundCertificate chan uint64
 
-	//	User configured additional timeout for each round of consensus
-	additionalTimeout time.Duration
+	// roundSkip is the channel used for signalizing
+	// when validators with voting power above the faulty
+	// threshold moved to a round greater than the current one
+	roundSkip chan uint64
 
-	// baseRoundTimeout is the base round timeout for each round of consensus
-	baseRoundTimeout time.Duration
+	// futureHeight is the channel used for signalizing
+	// when validators with quorum voting power are
+	// observed at a height greater than the current one
+	futureHeight chan uint64
+
+	// config holds the tunables, which can be
+	// changed in between sequences
+	config     config
+	configLock sync.RWMutex
 
 	// wg is a simple barrier used for synchronizing
 	// state modification routines
@@ -130,6 +171,38 @@ type IBFT struct {
 
 	// validatorManager keeps quorumSize and voting power information
 	validatorManager *ValidatorManager
+
+	// ingress is the asynchronous pipeline of the
+	// incoming messages, if enabled
+	ingress *ingress
+
+	// validatorSets is the epoch-aware source of the validator sets,
+	// used for the messages of the future heights, if set
+	validatorSets *ValidatorSets
+
+	// wal is the write-ahead log used for persisting
+	// the state transitions, if any
+	wal WAL
+
+	// tracer records the inputs and the outputs of
+	// the state machine, if the instance is traced
+	tracer *tracer
+
+	// signedMessages are the messages signed by the node
+	// for the current height
+	signedMessages signedMessages
+
+	// syncNotifier is alerted when the node is lagging
+	// behind the other validators, if set
+	syncNotifier SyncNotifier
+
+	// futureHeights keeps track of the heights
+	// the other validators are observed at
+	futureHeights futureHeights
+
+	// lifecycle keeps track of the instance state
+	// and of the running sequences
+	lifecycle lifecycle
 }
 
 // NewIBFT creates a new instance of the IBFT consensus protocol
@@ -147,6 +220,8 @@ func NewIBFT(
 		roundExpired:     make(chan struct{}),
 		newProposal:      make(chan newProposalEvent),
 		roundCertificate: make(chan uint64),
+		roundSkip:        make(chan uint64),
+		futureHeight:     make(chan uint64, 1),
 		state: &state{
 			view: &proto.View{
 				Height: 0,
@@ -156,7 +231,7 @@ func NewIBFT(
 			roundStarted: false,
 			name:         newRound,
 		},
-		baseRoundTimeout: DefaultBaseRoundTimeout,
+		config:           defaultConfig(),
 		validatorManager: NewValidatorManager(backend, log),
 	}
 }
@@ -166,24 +241,53 @@ func SetMeasurementTime(prefix string, startTime time.Time) {
 	metrics.SetGauge([]string{"go-ibft", prefix, "duration"}, float32(time.Since(startTime).Seconds()))
 }
 
+// setMeasurementTime sets the duration to the gauge, and returns it
+func (i *IBFT) setMeasurementTime(prefix string, startTime time.Time) time.Duration {
+	duration := i.getConfig().clock.Since(startTime)
+
+	i.setGauge([]string{"go-ibft", prefix, "duration"}, float32(duration.Seconds()))
+
+	return duration
+}
+
+// observeLatency passes the measured duration to the round timeout policy, if it observes the measurements
+func (i *IBFT) observeLatency(prefix string, duration time.Duration) {
+	if observer, ok := i.getConfig().roundTimeoutPolicy.(LatencyObserver); ok {
+		observer.ObserveLatency(prefix, duration)
+	}
+}
+
+// setGauge sets the value to the gauge of the configured
+// metrics sink, or of the global one if not set
+func (i *IBFT) setGauge(key []string, value float32) {
+	if sink := i.getConfig().metricsSink; sink != nil {
+		sink.SetGauge(key, value)
+
+		return
+	}
+
+	metrics.SetGauge(key, value)
+}
+
 // startRoundTimer starts the exponential round timer, based on the
 // passed in round number
 func (i *IBFT) startRoundTimer(ctx context.Context, round uint64) {
 	defer i.wg.Done()
 
-	startTime := time.Now()
+	cfg := i.getConfig()
+	startTime := cfg.clock.Now()
 
-	roundTimeout := getRoundTimeout(i.baseRoundTimeout, i.additionalTimeout, round)
+	roundTimeout := cfg.roundTimeout(round)
 
 	//	Create a new timer instance
-	timer := time.NewTimer(roundTimeout)
+	timer := cfg.clock.NewTimer(roundTimeout)
 
 	select {
 	case <-ctx.Done():
-		SetMeasurementTime("round", startTime)
+		i.observeLatency(MeasurementRound, i.setMeasurementTime(MeasurementRound, startTime))
 		// Stop signal received, stop the timer
 		timer.Stop()
-	case <-timer.C:
+	case <-timer.C():
 		// Timer expired, alert the round change channel to move
 		// to the next round
 		i.signalRoundExpired(ctx)
@@ -218,6 +322,15 @@ func (i *IBFT) signalNewRCC(ctx context.Context, round uint64) {
 	}
 }
 
+// signalRoundSkip notifies the sequence routine (RunSequence) that
+// enough validators moved to a higher round to justify following them
+func (i *IBFT) signalRoundSkip(ctx context.Context, round uint64) {
+	select {
+	case i.roundSkip <- round:
+	case <-ctx.Done():
+	}
+}
+
 type newProposalEvent struct {
 	proposalMessage *proto.Message
 	round           uint64
@@ -279,8 +392,8 @@ func (i *IBFT) watchForFutureProposal(ctx context.Context) {
 }
 
 // watchForRoundChangeCertificates is a routine that waits
-// for future valid Round Change Certificates that could
-// trigger a round hop
+// for future valid Round Change Certificates, or for enough
+// future Round Change messages, that could trigger a round hop
 func (i *IBFT) watchForRoundChangeCertificates(ctx context.Context) {
 	defer i.wg.Done()
 
@@ -312,39 +425,99 @@ func (i *IBFT) watchForRoundChangeCertificates(ctx context.Context) {
 					Round:  round,
 				},
 			)
-			if rcc == nil {
-				continue
-			}
+			if rcc != nil && rcc.RoundChangeMessages[0].View.Round > round {
+				//	we received a valid RCC for a higher round
+				i.signalNewRCC(ctx, rcc.RoundChangeMessages[0].View.Round)
 
-			newRound := rcc.RoundChangeMessages[0].View.Round
+				return
+			}
 
-			//	we received a valid RCC for a higher round
-			i.signalNewRCC(ctx, newRound)
+			// Check if the node is lagging behind the other validators
+			if skipRound, ok := i.getRoundSkip(view); ok {
+				i.signalRoundSkip(ctx, skipRound)
 
-			return
+				return
+			}
 		}
 	}
 }
 
-// RunSequence runs the IBFT sequence for the specified height
-func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
-	startTime := time.Now()
+// RunSequence runs the IBFT sequence for the specified height, and returns
+// the finalized proposal. The sequence is cancelled when the context is done,
+// or when the instance is stopped, in which case ErrSequenceCancelled is returned
+func (i *IBFT) RunSequence(ctx context.Context, h uint64) (*SequenceResult, error) {
+	ctx, release, err := i.enterSequence(ctx)
+	if err != nil {
+		i.log.Error("failed to run sequence", "height", h, "err", err)
+
+		return nil, err
+	}
+
+	defer release()
+
+	i.traceSequence(h)
+	defer i.traceSequenceDone(h)
+
+	var (
+		startTime = i.getConfig().clock.Now()
+		rounds    uint64
+	)
 
 	// Set the starting state data
 	i.state.reset(h)
+	i.signedMessages.reset()
 
 	if err := i.validatorManager.Init(h); err != nil {
 		i.log.Error("failed to run sequence - validator manager init", "height", h, "error", err)
 
-		return
+		return nil, fmt.Errorf("%w: %w", ErrValidatorSet, err)
 	}
 
 	// Prune messages for older heights
 	i.messages.PruneByHeight(h)
 
+	if i.validatorSets != nil {
+		i.validatorSets.Prune(h)
+	}
+
+	i.pruneWAL(h)
+
+	// Resume the height in case the node was restarted in the middle of it.
+	// The height is not run without the restored state, so no conflicting messages are signed
+	if err := i.restoreState(h); err != nil {
+		i.log.Error("failed to restore state from WAL", "height", h, "err", err)
+
+		return nil, fmt.Errorf("%w: %w", ErrWAL, err)
+	}
+
+	// Join the validators which already moved to a higher round of the height right away.
+	// Otherwise the node could vote in the round it starts with, before skipping it,
+	// and be left behind by the validators which finalize the round with its votes
+	if round, ok := i.getRoundSkip(i.state.getView()); ok {
+		i.log.Info("validators moved to a higher round", "round", round)
+
+		i.moveToNewRound(round)
+		i.sendRoundChangeMessage(h, round)
+	}
+
+	i.trackView(i.state.getView())
+
+	// Drop the sync signals meant for older sequences,
+	// and check if the node is already lagging behind
+	i.futureHeights.reset(h)
+
+	select {
+	case <-i.futureHeight:
+	default:
+	}
+
+	i.checkFutureHeight()
+
 	i.log.Info("sequence started", "height", h)
 	defer i.log.Info("sequence done", "height", h)
-	defer SetMeasurementTime("sequence", startTime)
+	defer func() {
+		_ = i.setMeasurementTime(MeasurementSequence, startTime)
+	}()
 
 	for {
 		view := i.state.getView()
@@ -355,6 +528,8 @@ func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
 
 		i.log.Info("round started", "round", view.Round)
 
+		rounds++
+
 		currentRound := view.Round
 		ctxRound, cancelRound := context.WithCancel(ctx)
 
@@ -374,49 +549,122 @@ func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
 
 		i.notifyRoundChange(ctxRound, view)
 
-		teardown := func() {
+		// teardown stops the round workers, and reports if the round was finalized meanwhile.
+		// The commit quorum may be reached while the round is cancelled, during the commit grace period
+		teardown := func() bool {
 			cancelRound()
 			i.wg.Wait()
+
+			return i.state.getStateName() == fin
 		}
 
 		select {
 		case ev := <-i.newProposal:
-			teardown()
+			if teardown() {
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
 			i.log.Info("received future proposal", "round", ev.round)
 
 			i.moveToNewRound(ev.round)
 			i.acceptProposal(ev.proposalMessage)
 			i.state.setRoundStarted(true)
-			i.sendPrepareMessage(view)
+			i.sendPrepareMessage(i.state.getView())
 		case round := <-i.roundCertificate:
-			teardown()
+			if teardown() {
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
 			i.log.Info("received future RCC", "round", round)
 
 			i.moveToNewRound(round)
+		case round := <-i.roundSkip:
+			if teardown() {
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
+			i.log.Info("validators moved to a higher round", "round", round)
+
+			i.moveToNewRound(round)
+
+			i.sendRoundChangeMessage(h, round)
 		case <-i.roundExpired:
-			teardown()
+			if teardown() {
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
 			i.log.Info("round timeout expired", "round", currentRound)
 
 			newRound := currentRound + 1
 			i.moveToNewRound(newRound)
 
 			i.sendRoundChangeMessage(h, newRound)
+		case height := <-i.futureHeight:
+			// The node is lagging behind, the sequence is aborted
+			// so the node can sync up to the newer height
+			if teardown() {
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
+			i.log.Info("sequence aborted for sync", "height", height)
+
+			return nil, fmt.Errorf("%w: height %d", ErrSyncRequired, height)
 		case <-i.roundDone:
 			// The consensus cycle for the block height is finished.
 			// Stop all running worker threads
 			teardown()
-			i.insertBlock()
 
-			return
+			return i.finalizeSequence(h, rounds, startTime)
 		case <-ctxRound.Done():
-			teardown()
+			if teardown() {
+				// The commit quorum was verified, so the proposal is final
+				return i.finalizeSequence(h, rounds, startTime)
+			}
+
 			i.log.Debug("sequence cancelled")
 
-			return
+			return nil, fmt.Errorf("%w: %w", ErrSequenceCancelled, ctx.Err())
 		}
 	}
 }
 
+// finalizeSequence inserts the finalized proposal, and returns the sequence result
+func (i *IBFT) finalizeSequence(h, rounds uint64, startTime time.Time) (*SequenceResult, error) {
+	proposal, committedSeals := i.getFinalizedProposal()
+
+	certificate, err := NewFinalityCertificate(
+		&proto.View{Height: h, Round: proposal.Round},
+		i.state.getProposalHash(),
+		committedSeals,
+		i.validatorManager.getVotingPowers(),
+	)
+	if err != nil {
+		i.log.Error("failed to create finality certificate", "height", h, "err", err)
+
+		return nil, fmt.Errorf("%w: %w", ErrBackend, err)
+	}
+
+	if err := i.insertBlock(proposal, committedSeals); err != nil {
+		i.log.Error("failed to insert proposal", "height", h, "err", err)
+
+		return nil, fmt.Errorf("%w: %w", ErrBackend, err)
+	}
+
+	// Only the finalized sequences are observed, the cancelled or aborted ones
+	// don't reflect how long it takes the validators to reach the consensus
+	duration := i.getConfig().clock.Since(startTime)
+	i.observeLatency(MeasurementSequence, duration)
+
+	return &SequenceResult{
+		Proposal:       proposal,
+		Round:          proposal.Round,
+		CommittedSeals: committedSeals,
+		Certificate:    certificate,
+		Rounds:         rounds,
+		Duration:       duration,
+	}, nil
+}
+
 // startRound runs the state machine loop for the current round
 func (i *IBFT) startRound(ctx context.Context) {
 	// Register this worker thread with the barrier
@@ -424,24 +672,29 @@ func (i *IBFT) startRound(ctx context.Context) {
 
 	i.state.newRound()
 
-	var (
-		id   = i.backend.ID()
-		view = i.state.getView()
-	)
+	view := i.state.getView()
 
-	// Check if any block needs to be proposed
-	if i.backend.IsProposer(id, view.Height, view.Round) {
+	// Check if any block needs to be proposed, observers never propose
+	if !i.IsObserver() && i.backend.IsProposer(i.backend.ID(), view.Height, view.Round) {
 		i.log.Info("we are the proposer")
 
-		proposalMessage := i.buildProposal(ctx, view)
+		// Never build a different proposal for the view, if one was already signed
+		proposalMessage := i.signedMessages.get(proto.MessageType_PREPREPARE, view)
+		if proposalMessage == nil {
+			proposalMessage = i.buildProposal(ctx, view)
+		}
+
 		if proposalMessage == nil {
 			i.log.Error("unable to build proposal")
 
 			return
 		}
 
-		i.acceptProposal(proposalMessage)
-		i.log.Debug("block proposal accepted")
+		// The proposal is already accepted, if the round was restored from the WAL
+		if i.state.getProposalMessage() == nil {
+			i.acceptProposal(proposalMessage)
+			i.log.Debug("block proposal accepted")
+		}
 
 		i.notifyPreprepare(ctx, view.Round, proposalMessage)
 
@@ -454,28 +707,46 @@ func (i *IBFT) startRound(ctx context.Context) {
 // notifyRoundChange multicast the round change message on a given interval in case round is above longRoundThreshold
 // That way new nodes can collect info about the round of the others to restore faster on halting
 func (i *IBFT) notifyRoundChange(ctx context.Context, view *proto.View) {
-	if view.Round > longRoundThreshold {
+	if cfg := i.getConfig(); view.Round > cfg.longRoundThreshold {
 		go runTaskPeriodically(ctx, &i.wg, func() {
 			i.sendRoundChangeMessage(view.Height, view.Round)
-		}, periodicTaskInteval)
+		}, cfg.clock, cfg.periodicTaskInterval)
 	}
 }
 
 // notifyPreprepare multicast the preprepare message.
-// Continue sending the preprepare message in case round is above longRoundThreshold.
-// That way new nodes can enter the prepare phase
+// Continue sending the preprepare message in case round is above longRoundThreshold,
+// unless the transport can reach the peers directly. That way new nodes can enter the prepare phase.
+// With the direct transport the nodes request the proposal from the proposer instead (see pollProposal)
 func (i *IBFT) notifyPreprepare(ctx context.Context, currentRound uint64, message *proto.Message) {
-	if currentRound > longRoundThreshold {
-		go runTaskPeriodically(ctx, &i.wg, func() {
-			i.sendPreprepareMessage(message)
-		}, periodicTaskInteval)
+	if _, direct := i.directTransport(); !direct {
+		if cfg := i.getConfig(); currentRound > cfg.longRoundThreshold {
+			go runTaskPeriodically(ctx, &i.wg, func() {
+				i.sendPreprepareMessage(message)
+			}, cfg.clock, cfg.periodicTaskInterval)
 
-		return
+			return
+		}
 	}
 
 	i.sendPreprepareMessage(message)
 }
 
+// pollProposal requests the proposal from the proposer on a given interval in case round is above
+// longRoundThreshold, until the context is done. That way new nodes can enter the prepare phase,
+// without the proposer re-multicasting the preprepare message to all the peers
+func (i *IBFT) pollProposal(ctx context.Context, view *proto.View) {
+	if _, direct := i.directTransport(); !direct {
+		return
+	}
+
+	if cfg := i.getConfig(); view.Round > cfg.longRoundThreshold {
+		go runTaskPeriodically(ctx, &i.wg, func() {
+			i.requestProposal(view)
+		}, cfg.clock, cfg.periodicTaskInterval)
+	}
+}
+
 // waitForRCC waits for valid RCC for the specified height and round
 func (i *IBFT) waitForRCC(
 	ctx context.Context,
@@ -508,6 +779,13 @@ func (i *IBFT) waitForRCC(
 				continue
 			}
 
+			// The certificate can be for a higher round, if the other validators
+			// already moved there. Such a certificate doesn't justify a proposal
+			// for this round, and the round hop is up to the RCC watcher
+			if rcc.RoundChangeMessages[0].View.Round != round {
+				continue
+			}
+
 			return rcc
 		}
 	}
@@ -525,6 +803,11 @@ func (i *IBFT) handleRoundChangeMessage(view *proto.View) *proto.RoundChangeCert
 		proposal := messages.ExtractLastPreparedProposal(msg)
 		certificate := messages.ExtractLatestPC(msg)
 
+		// Make sure the RCC can be compacted
+		if !i.hasValidAggregatableSeal(msg) {
+			return false
+		}
+
 		// Check if the prepared certificate is valid
 		if !i.validPC(certificate, msg.View.Round, height) {
 			return false
@@ -544,6 +827,14 @@ func (i *IBFT) handleRoundChangeMessage(view *proto.View) *proto.RoundChangeCert
 		return i.hasQuorumByMsgType(msgs, proto.MessageType_ROUND_CHANGE)
 	}
 
+	isValidMsgFn = i.cachedValidation(
+		messages.ValidationContext{
+			MessageType: proto.MessageType_ROUND_CHANGE,
+			Round:       view.Round,
+		},
+		isValidMsgFn,
+	)
+
 	extendedRCC := i.messages.GetExtendedRCC(
 		height,
 		isValidMsgFn,
@@ -559,6 +850,37 @@ func (i *IBFT) handleRoundChangeMessage(view *proto.View) *proto.RoundChangeCert
 	}
 }
 
+// getRoundSkip checks if validators with voting power above the faulty threshold
+// sent ROUND_CHANGE messages for rounds higher than the one in the view.
+// If they did, at least one honest validator is on one of those rounds, so the node
+// can move to the lowest of them instead of waiting for its own round timeouts to expire.
+// A node that accepted a proposal in the current round keeps working on it,
+// since the round can still be finalized by the validators that stayed in it
+func (i *IBFT) getRoundSkip(view *proto.View) (uint64, bool) {
+	if i.state.getProposal() != nil {
+		return 0, false
+	}
+
+	return i.getRoundSkipTarget(view.Height, view.Round+1)
+}
+
+// getRoundSkipTarget returns the lowest round, at least the minimum one, the node can skip to.
+// The senders are aggregated across all the rounds, so the validators spread out
+// over different rounds count towards the same faulty threshold
+func (i *IBFT) getRoundSkipTarget(height, minRound uint64) (uint64, bool) {
+	msgs := i.messages.GetRoundChangeMessages(minRound, height)
+	if len(msgs) == 0 {
+		return 0, false
+	}
+
+	if !i.validatorManager.HasWeakQuorum(convertMessageToAddressSet(msgs)) {
+		return 0, false
+	}
+
+	// The messages are sorted by their rounds
+	return msgs[0].View.Round, true
+}
+
 // proposalMatchesCertificate checks a prepared certificate
 // against a proposal
 func (i *IBFT) proposalMatchesCertificate(
@@ -645,11 +967,37 @@ func (i *IBFT) runNewRound(ctx context.Context) error {
 	// this state is done executing
 	defer i.messages.Unsubscribe(sub.ID)
 
+	var (
+		// Subscribe for PREPARE messages, if the missed proposal can be requested
+		missingSub = i.subscribeMissingProposal(view)
+
+		// missingCh stays nil, and never fires, if there is no subscription
+		missingCh <-chan uint64
+
+		// requested are the peers the proposal was requested from
+		requested = make(map[string]struct{})
+	)
+
+	if missingSub != nil {
+		defer i.messages.Unsubscribe(missingSub.ID)
+
+		missingCh = missingSub.SubCh
+	}
+
+	// Request the proposal from the proposer, which doesn't re-multicast it in the long rounds
+	pollCtx, cancelPoll := context.WithCancel(ctx)
+	defer cancelPoll()
+
+	i.pollProposal(pollCtx, view)
+
 	for {
 		select {
 		case <-ctx.Done():
 			// Stop signal received, exit
 			return errTimeoutExpired
+		case <-missingCh:
+			// Other validators prepared a proposal the node doesn't have
+			i.requestMissingProposal(view, requested)
 		case <-sub.SubCh:
 			// SubscriptionDetails conditions have been met,
 			// grab the proposal messages
@@ -658,15 +1006,12 @@ func (i *IBFT) runNewRound(ctx context.Context) error {
 				continue
 			}
 
-			// Multicast the PREPARE message
-			i.state.setProposalMessage(proposalMessage)
+			// Accept the proposal, and multicast the PREPARE message
+			i.acceptProposal(proposalMessage)
 			i.sendPrepareMessage(view)
 
 			i.log.Debug("prepare message multicasted")
 
-			// Move to the prepare state
-			i.state.changeState(prepare)
-
 			return nil
 		}
 	}
@@ -698,6 +1043,11 @@ func (i *IBFT) validateProposalCommon(msg *proto.Message, view *proto.View) bool
 		return false
 	}
 
+	//	the prepare seal of the proposer is valid, so the PC of the proposal can be compacted
+	if !i.hasValidAggregatableSeal(msg) {
+		return false
+	}
+
 	//	is valid proposal
 	return i.backend.IsValidProposal(proposal.GetRawProposal())
 }
@@ -783,6 +1133,11 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 		if !i.backend.IsValidValidator(rc) {
 			return false
 		}
+
+		// Round change seal is valid, so the RCC can be compacted
+		if !i.hasValidAggregatableSeal(rc) {
+			return false
+		}
 	}
 
 	// Extract possible rounds and their corresponding
@@ -839,6 +1194,11 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 // a transition to PREPARE state, if the proposal is valid
 func (i *IBFT) handlePrePrepare(view *proto.View) *proto.Message {
 	isValidPrePrepare := func(message *proto.Message) bool {
+		// Make sure the node didn't vote for a different proposal in the view
+		if i.signedMessages.conflicts(proto.MessageType_PREPARE, view, messages.ExtractProposalHash(message)) {
+			return false
+		}
+
 		if view.Round == 0 {
 			//	proposal must be for round 0
 			return i.validateProposal0(message, view)
@@ -903,16 +1263,28 @@ func (i *IBFT) runPrepare(ctx context.Context) error {
 func (i *IBFT) handlePrepare(view *proto.View) bool {
 	isValidPrepare := func(message *proto.Message) bool {
 		// Verify that the proposal hash is valid
-		return i.backend.IsValidProposalHash(
+		if !i.backend.IsValidProposalHash(
 			i.state.getProposal(),
 			messages.ExtractPrepareHash(message),
-		)
+		) {
+			return false
+		}
+
+		// Make sure the PC can be compacted
+		return i.hasValidAggregatableSeal(message)
 	}
 
 	prepareMessages := i.messages.GetValidMessages(
 		view,
 		proto.MessageType_PREPARE,
-		isValidPrepare,
+		i.cachedValidation(
+			messages.ValidationContext{
+				MessageType:  proto.MessageType_PREPARE,
+				Round:        view.Round,
+				ProposalHash: i.state.getProposalHash(),
+			},
+			isValidPrepare,
+		),
 	)
 
 	if !i.hasQuorumByMsgType(prepareMessages, proto.MessageType_PREPARE) {
@@ -920,18 +1292,32 @@ func (i *IBFT) handlePrepare(view *proto.View) bool {
 		return false
 	}
 
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: i.state.getProposalMessage(),
+		PrepareMessages: prepareMessages,
+	}
+
+	// Persist the lock before voting for the proposal.
+	// Observers don't vote, so they are never locked
+	if !i.IsObserver() {
+		err := i.writeWAL(&proto.WALEntry{
+			Type:                      proto.WALEntry_PREPARED,
+			View:                      view,
+			LatestPreparedCertificate: certificate,
+			LatestPreparedProposal:    i.state.getProposal(),
+		})
+		if err != nil {
+			// Never vote without the persisted lock, the write is retried on the next PREPARE message
+			return false
+		}
+	}
+
 	// Multicast the COMMIT message
 	i.sendCommitMessage(view)
 
 	i.log.Debug("commit message multicasted")
 
-	i.state.finalizePrepare(
-		&proto.PreparedCertificate{
-			ProposalMessage: i.state.getProposalMessage(),
-			PrepareMessages: prepareMessages,
-		},
-		i.state.getProposal(),
-	)
+	i.state.finalizePrepare(certificate, i.state.getProposal())
 
 	return true
 }
@@ -964,7 +1350,7 @@ func (i *IBFT) runCommit(ctx context.Context) error {
 			// Stop signal received, exit
 			return errTimeoutExpired
 		case <-sub.SubCh:
-			if !i.handleCommit(view) {
+			if !i.handleCommit(ctx, view, sub.SubCh) {
 				//	quorum not reached, retry
 				continue
 			}
@@ -975,37 +1361,35 @@ func (i *IBFT) runCommit(ctx context.Context) error {
 }
 
 // handleCommit parses available commit messages and performs
-// a transition to FIN state, if quorum was reached
-func (i *IBFT) handleCommit(view *proto.View) bool {
-	isValidCommit := func(message *proto.Message) bool {
-		var (
-			proposalHash  = messages.ExtractCommitHash(message)
-			committedSeal = messages.ExtractCommittedSeal(message)
-		)
-		//	Verify that the proposal hash is valid
-		if !i.backend.IsValidProposalHash(i.state.getProposal(), proposalHash) {
-			return false
-		}
-
-		//	Verify that the committed seal is valid
-		return i.backend.IsValidCommittedSeal(proposalHash, committedSeal)
-	}
-
-	commitMessages := i.messages.GetValidMessages(view, proto.MessageType_COMMIT, isValidCommit)
+// a transition to FIN state, if quorum was reached.
+// Once the quorum is reached, more commit messages are collected
+// for the commit grace period, which ends early if the context is done.
+// The quorum is final, so the round is finalized even if the context is done
+func (i *IBFT) handleCommit(ctx context.Context, view *proto.View, commitCh <-chan uint64) bool {
+	commitMessages := i.getValidCommitMessages(view)
 	if !i.hasQuorumByMsgType(commitMessages, proto.MessageType_COMMIT) {
 		//	quorum not reached, keep polling
 		return false
 	}
 
-	// wait additional 0.2 seconds so more commit messages to be collected
-	time.Sleep(200 * time.Millisecond)
+	quorumSeals := len(commitMessages)
+
+	i.collectSeals(ctx, view, commitCh)
 
-	commitMessages = i.messages.GetValidMessages(view, proto.MessageType_COMMIT, isValidCommit)
+	commitMessages = i.getValidCommitMessages(view)
 	if !i.hasQuorumByMsgType(commitMessages, proto.MessageType_COMMIT) {
 		//	unexpected but check it just in case
 		return false
 	}
 
+	// Record the number of seals collected after the quorum was reached
+	extraSeals := len(commitMessages) - quorumSeals
+	if extraSeals < 0 {
+		extraSeals = 0
+	}
+
+	i.setGauge([]string{"go-ibft", "commit", "extra_seals"}, float32(extraSeals))
+
 	commitSeals, err := messages.ExtractCommittedSeals(commitMessages)
 	if err != nil {
 		// safe check
@@ -1023,6 +1407,74 @@ func (i *IBFT) handleCommit(view *proto.View) bool {
 	return true
 }
 
+// getValidCommitMessages returns the commit messages for the view
+// with valid proposal hashes and committed seals
+func (i *IBFT) getValidCommitMessages(view *proto.View) []*proto.Message {
+	isValidCommit := func(message *proto.Message) bool {
+		var (
+			proposalHash  = messages.ExtractCommitHash(message)
+			committedSeal = messages.ExtractCommittedSeal(message)
+		)
+		//	Verify that the proposal hash is valid
+		if !i.backend.IsValidProposalHash(i.state.getProposal(), proposalHash) {
+			return false
+		}
+
+		//	Verify that the committed seal is valid
+		return i.backend.IsValidCommittedSeal(proposalHash, committedSeal)
+	}
+
+	return i.messages.GetValidMessages(
+		view,
+		proto.MessageType_COMMIT,
+		i.cachedValidation(
+			messages.ValidationContext{
+				MessageType:  proto.MessageType_COMMIT,
+				Round:        view.Round,
+				ProposalHash: i.state.getProposalHash(),
+			},
+			isValidCommit,
+		),
+	)
+}
+
+// collectSeals waits for more commit messages to arrive, until the commit grace period
+// expires, until the commit messages reach the seal collection target,
+// or until the context is done
+func (i *IBFT) collectSeals(ctx context.Context, view *proto.View, commitCh <-chan uint64) {
+	cfg := i.getConfig()
+	if cfg.commitGracePeriod == 0 {
+		return
+	}
+
+	hasTarget := func() bool {
+		return i.validatorManager.HasVotingPowerShare(
+			convertMessageToAddressSet(i.getValidCommitMessages(view)),
+			cfg.sealCollectionTarget,
+		)
+	}
+
+	if hasTarget() {
+		return
+	}
+
+	timer := cfg.clock.NewTimer(cfg.commitGracePeriod)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			return
+		case <-commitCh:
+			if hasTarget() {
+				return
+			}
+		}
+	}
+}
+
 // runFin runs the fin state (block insertion)
 func (i *IBFT) runFin(ctx context.Context) {
 	i.log.Debug("enter: fin state")
@@ -1032,28 +1484,44 @@ func (i *IBFT) runFin(ctx context.Context) {
 }
 
 // insertBlock inserts the block
-func (i *IBFT) insertBlock() {
+func (i *IBFT) insertBlock(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
 	// Insert the block to the node's underlying
 	// blockchain layer
-	i.backend.InsertProposal(
-		&proto.Proposal{
-			RawProposal: i.state.getRawDataFromProposal(),
-			Round:       i.state.getRound(),
-		},
-		i.state.getCommittedSeals(),
-	)
+	if err := i.backend.InsertProposal(proposal, committedSeals); err != nil {
+		return err
+	}
 
 	// Remove stale messages
 	i.messages.PruneByHeight(i.state.getHeight())
+
+	return nil
+}
+
+// getFinalizedProposal returns the finalized proposal, and its committed seals
+func (i *IBFT) getFinalizedProposal() (*proto.Proposal, []*messages.CommittedSeal) {
+	return &proto.Proposal{
+		RawProposal: i.state.getRawDataFromProposal(),
+		Round:       i.state.getRound(),
+	}, i.state.getCommittedSeals()
 }
 
 // moveToNewRound moves the state to the new round
 func (i *IBFT) moveToNewRound(round uint64) {
-	i.state.setView(&proto.View{
+	view := &proto.View{
 		Height: i.state.getHeight(),
 		Round:  round,
+	}
+
+	// The view is only a hint for resuming the height, so the node
+	// moves to the new round even if it can't be persisted
+	_ = i.writeWAL(&proto.WALEntry{
+		Type: proto.WALEntry_VIEW,
+		View: view,
 	})
 
+	i.state.setView(view)
+	i.trackView(view)
+
 	i.state.setRoundStarted(false)
 	i.state.setProposalMessage(nil)
 	i.state.changeState(newRound)
@@ -1147,8 +1615,42 @@ func (i *IBFT) buildProposal(ctx context.Context, view *proto.View) *proto.Messa
 	)
 }
 
-// acceptProposal accepts the proposal and moves the state
+// trackView notifies the message storage layer of the current view, if it tracks it,
+// and lets the ingress pipeline accept the messages it already saw in the previous views
+func (i *IBFT) trackView(view *proto.View) {
+	if tracker, ok := i.messages.(ViewTracker); ok {
+		tracker.SetView(view)
+	}
+
+	if i.ingress != nil {
+		i.ingress.resetSeen()
+	}
+}
+
+// cachedValidation wraps the validation function with the validation cache
+// of the message storage layer, if it caches the validation results
+func (i *IBFT) cachedValidation(
+	context messages.ValidationContext,
+	isValid func(message *proto.Message) bool,
+) func(message *proto.Message) bool {
+	if cacher, ok := i.messages.(ValidationCacher); ok {
+		return cacher.CachedValidation(context, isValid)
+	}
+
+	return isValid
+}
+
+// acceptProposal accepts the proposal and moves the state.
+// The accepted proposal is persisted, so a restarted node resumes the round without waiting for it again
 func (i *IBFT) acceptProposal(proposalMessage *proto.Message) {
+	// The proposal is only a hint for resuming the round, so the node
+	// accepts it even if it can't be persisted
+	_ = i.writeWAL(&proto.WALEntry{
+		Type:    proto.WALEntry_PROPOSAL,
+		View:    proposalMessage.View,
+		Message: proposalMessage,
+	})
+
 	//	accept newly proposed block and move to PREPARE state
 	i.state.setProposalMessage(proposalMessage)
 	i.state.changeState(prepare)
@@ -1161,20 +1663,20 @@ func (i *IBFT) AddMessage(message *proto.Message) {
 		return
 	}
 
+	i.traceMessage(message)
+
 	// Check if the message should even be considered
 	if i.isAcceptableMessage(message) {
-		i.messages.AddMessage(message)
-
-		// Signal event if the quorum is reached. Since the subscriptions refer to the state height,
-		// no need to call this if the message height is not equal to the state height
-		if message.View.Height == i.state.getHeight() {
-			msgs := i.messages.GetValidMessages(
-				message.View,
-				message.Type,
-				func(_ *proto.Message) bool { return true })
-			if i.hasQuorumByMsgType(msgs, message.Type) {
-				i.messages.SignalEvent(message.Type, message.View)
-			}
+		if evidence := i.messages.AddMessage(message); evidence != nil {
+			i.log.Info("conflicting messages received", "type", evidence.Type(), "view", evidence.View())
+
+			i.backend.ReportEquivocation(evidence)
+		}
+
+		// The subscribers are notified by the message storage layer,
+		// once the messages reach the subscription quorum
+		if message.View.Height > i.state.getHeight() {
+			i.trackFutureHeight(message)
 		}
 	}
 }
@@ -1186,6 +1688,12 @@ func (i *IBFT) isAcceptableMessage(message *proto.Message) bool {
 		return false
 	}
 
+	return i.isAcceptableView(message)
+}
+
+// isAcceptableView checks if the message view can be accepted in the current state.
+// Unlike isAcceptableMessage, the signature of the message is not verified
+func (i *IBFT) isAcceptableView(message *proto.Message) bool {
 	// Invalid messages are discarded
 	if message.View == nil {
 		return false
@@ -1202,17 +1710,42 @@ func (i *IBFT) isAcceptableMessage(message *proto.Message) bool {
 		return message.View.Round >= i.state.getRound()
 	}
 
-	return true
+	// Make sure the sender of the future height message
+	// is in the validator set of that height, if it is known
+	return i.isFutureValidator(message.From, message.View.Height)
+}
+
+// isFutureValidator checks if the sender is in the validator set of the future height.
+// If the validator set of the height is not known yet, the sender is assumed valid
+func (i *IBFT) isFutureValidator(sender []byte, height uint64) bool {
+	if i.validatorSets == nil {
+		return true
+	}
+
+	votingPowers, ok := i.validatorSets.VotingPowersAt(height)
+	if !ok {
+		return true
+	}
+
+	_, ok = votingPowers[string(sender)]
+
+	return ok
 }
 
 // ExtendRoundTimeout extends each round's timer by the specified amount.
 func (i *IBFT) ExtendRoundTimeout(amount time.Duration) {
-	i.additionalTimeout = amount
+	i.configLock.Lock()
+	defer i.configLock.Unlock()
+
+	i.config.additionalTimeout = amount
 }
 
 // SetBaseRoundTimeout sets the base (round 0) timeout
 func (i *IBFT) SetBaseRoundTimeout(baseRoundTimeout time.Duration) {
-	i.baseRoundTimeout = baseRoundTimeout
+	i.configLock.Lock()
+	defer i.configLock.Unlock()
+
+	i.config.baseRoundTimeout = baseRoundTimeout
 }
 
 // validPC verifies that the prepared certificate is valid
@@ -1259,6 +1792,13 @@ func (i *IBFT) validPC(
 		return false
 	}
 
+	// Make sure the messages carry the valid aggregatable seals, if the backend aggregates them
+	for _, message := range allMessages {
+		if !i.hasValidAggregatableSeal(message) {
+			return false
+		}
+	}
+
 	// Make sure the proposal message is sent by the proposer
 	// for the round
 	proposal := certificate.ProposalMessage
@@ -1289,12 +1829,20 @@ func (i *IBFT) validPC(
 
 // sendPreprepareMessage sends out the preprepare message
 func (i *IBFT) sendPreprepareMessage(message *proto.Message) {
-	i.transport.Multicast(message)
+	if i.IsObserver() {
+		return
+	}
+
+	i.multicast(message)
 }
 
 // sendRoundChangeMessage sends out the round change message
 func (i *IBFT) sendRoundChangeMessage(height, newRound uint64) {
-	i.transport.Multicast(
+	if i.IsObserver() {
+		return
+	}
+
+	i.multicast(
 		i.backend.BuildRoundChangeMessage(
 			i.state.getLatestPreparedProposal(),
 			i.state.getLatestPC(),
@@ -1308,22 +1856,40 @@ func (i *IBFT) sendRoundChangeMessage(height, newRound uint64) {
 
 // sendPrepareMessage sends out the prepare message
 func (i *IBFT) sendPrepareMessage(view *proto.View) {
-	i.transport.Multicast(
-		i.backend.BuildPrepareMessage(
-			i.state.getProposalHash(),
-			view,
-		),
-	)
+	if i.IsObserver() {
+		return
+	}
+
+	proposalHash := i.state.getProposalHash()
+
+	i.multicastVote(proto.MessageType_PREPARE, view, proposalHash, func() *proto.Message {
+		return i.backend.BuildPrepareMessage(proposalHash, view)
+	})
 }
 
 // sendCommitMessage sends out the commit message
 func (i *IBFT) sendCommitMessage(view *proto.View) {
-	i.transport.Multicast(
-		i.backend.BuildCommitMessage(
-			i.state.getProposalHash(),
-			view,
-		),
-	)
+	if i.IsObserver() {
+		return
+	}
+
+	proposalHash := i.state.getProposalHash()
+
+	i.multicastVote(proto.MessageType_COMMIT, view, proposalHash, func() *proto.Message {
+		return i.backend.BuildCommitMessage(proposalHash, view)
+	})
+}
+
+// hasRoundSkipQuorum checks if the ROUND_CHANGE messages for the subscribed rounds have reached
+// the voting power above the faulty threshold, which is enough for lagging nodes to skip rounds
+func (i *IBFT) hasRoundSkipQuorum(details messages.SubscriptionDetails) bool {
+	if details.MessageType != proto.MessageType_ROUND_CHANGE || !details.HasMinRound {
+		return false
+	}
+
+	_, ok := i.getRoundSkipTarget(details.View.Height, details.View.Round)
+
+	return ok
 }
 
 // hasQuorumByMsgType provides information on whether messages of specific types have reached the quorum
@@ -1342,37 +1908,31 @@ func (i *IBFT) hasQuorumByMsgType(msgs []*proto.Message, msgType proto.MessageTy
 	}
 }
 
+// subscribe creates a message subscription, which is notified
+// once the messages of the subscribed type reach the quorum
 func (i *IBFT) subscribe(details messages.SubscriptionDetails) *messages.Subscription {
-	subscription := i.messages.Subscribe(details)
-	msgs := i.messages.GetValidMessages(
-		details.View,
-		details.MessageType,
-		func(_ *proto.Message) bool { return true })
-	// Check if any condition is already met
-	if i.hasQuorumByMsgType(msgs, details.MessageType) {
-		i.messages.SignalEvent(details.MessageType, details.View)
+	if details.HasQuorumFn == nil {
+		details.HasQuorumFn = func(_ uint64, msgs []*proto.Message) bool {
+			return i.hasQuorumByMsgType(msgs, details.MessageType) ||
+				i.hasRoundSkipQuorum(details)
+		}
 	}
 
-	return subscription
+	return i.messages.Subscribe(details)
 }
 
-// getRoundTimeout creates a round timeout based on the base timeout and the current round.
-// Exponentially increases timeout depending on the round number.
-// For instance:
-//   - round 1: 1 sec
-//   - round 2: 2 sec
-//   - round 3: 4 sec
-//   - round 4: 8 sec
-func getRoundTimeout(baseRoundTimeout, additionalTimeout time.Duration, round uint64) time.Duration {
-	if round > longRoundThreshold {
-		return 10 * time.Minute
-	}
-
-	var (
-		baseDuration = int(baseRoundTimeout)
-		roundFactor  = int(math.Pow(roundFactorBase, float64(round)))
-		roundTimeout = time.Duration(baseDuration * roundFactor)
-	)
-
-	return roundTimeout + additionalTimeout
+// getRoundTimeout creates a round timeout based on the base timeout and the current round,
+// using the default exponential round timeout policy
+func getRoundTimeout(
+	baseRoundTimeout,
+	additionalTimeout time.Duration,
+	longRoundThreshold,
+	round uint64,
+) time.Duration {
+	return ExponentialTimeout{
+		Base:               baseRoundTimeout,
+		Additional:         additionalTimeout,
+		LongRoundThreshold: longRoundThreshold,
+		LongRoundTimeout:   DefaultLongRoundTimeout,
+	}.RoundTimeout(round)
 }
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -1000,9 +1002,11 @@ func TestRunCommit(t *testing.T) {
 				log       = mockLogger{}
 				transport = mockTransport{}
 				backend   = mockBackend{
-					insertProposalFn: func(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) {
+					insertProposalFn: func(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
 						insertedProposal = proposal.RawProposal
 						insertedCommittedSeals = committedSeals
+
+						return nil
 					},
 					getVotingPowerFn: testCommonGetVotingPowertFnForCnt(1),
 					isValidProposalHashFn: func(_ *proto.Proposal, hash []byte) bool {
@@ -1080,7 +1084,7 @@ func TestRunCommit(t *testing.T) {
 			i.startRound(ctx)
 
 			i.wg.Wait()
-			i.insertBlock()
+			require.NoError(t, i.insertBlock(i.getFinalizedProposal()))
 
 			// Make sure the node changed the state to fin
 			require.Equal(t, fin, i.state.getStateName())
@@ -1262,7 +1266,7 @@ func TestIBFT_StartRoundTimer(t *testing.T) {
 		)
 
 		i := NewIBFT(log, backend, transport)
-		i.baseRoundTimeout = 0 * time.Second
+		i.config.baseRoundTimeout = 0 * time.Second
 
 		ctx, cancelFn := context.WithCancel(context.Background())
 
@@ -2965,7 +2969,8 @@ func TestIBFT_RunSequence_NewProposal(t *testing.T) {
 		<-time.After(1 * time.Second)
 	}()
 
-	i.RunSequence(ctx, height)
+	_, err := i.RunSequence(ctx, height)
+	require.ErrorIs(t, err, ErrSequenceCancelled)
 
 	// Make sure the correct proposal message was accepted
 	assert.Equal(t, ev.proposalMessage, i.state.getProposalMessage())
@@ -3014,7 +3019,8 @@ func TestIBFT_RunSequence_FutureRCC(t *testing.T) {
 		<-time.After(1 * time.Second)
 	}()
 
-	i.RunSequence(ctx, height)
+	_, err := i.RunSequence(ctx, height)
+	require.ErrorIs(t, err, ErrSequenceCancelled)
 
 	// Make sure the proposal message is not set
 	assert.Nil(t, i.state.getProposalMessage())
@@ -3048,7 +3054,7 @@ func TestIBFT_ExtendRoundTimer(t *testing.T) {
 	i.ExtendRoundTimeout(additionalTimeout)
 
 	// Make sure the round timeout was extended
-	assert.Equal(t, additionalTimeout, i.additionalTimeout)
+	assert.Equal(t, additionalTimeout, i.config.additionalTimeout)
 }
 
 func TestIBFTOverrideBaseRoundTimeout(t *testing.T) {
@@ -3060,7 +3066,7 @@ func TestIBFTOverrideBaseRoundTimeout(t *testing.T) {
 	i.SetBaseRoundTimeout(baseRoundTimeout)
 
 	// Make sure the base round timeout is properly set
-	assert.Equal(t, baseRoundTimeout, i.baseRoundTimeout)
+	assert.Equal(t, baseRoundTimeout, i.config.baseRoundTimeout)
 }
 
 func Test_getRoundTimeout(t *testing.T) {
@@ -3111,7 +3117,12 @@ func Test_getRoundTimeout(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := getRoundTimeout(tt.args.baseRoundTimeout, tt.args.additionalTimeout, tt.args.round)
+			got := getRoundTimeout(
+				tt.args.baseRoundTimeout,
+				tt.args.additionalTimeout,
+				DefaultLongRoundThreshold,
+				tt.args.round,
+			)
 			assert.Equalf(t, tt.want, got, "getRoundTimeout(%v, %v, %v)", tt.args.baseRoundTimeout, tt.args.additionalTimeout, tt.args.round)
 		})
 	}
@@ -3128,56 +3139,43 @@ func TestIBFT_AddMessage(t *testing.T) {
 
 	var validSender = []byte("node 0")
 
-	executeTest := func(
-		msg *proto.Message, shouldAddMessageCalled, shouldSignalEventCalled bool, quorumSize uint64) {
+	executeTest := func(msg *proto.Message, shouldAddMessageCalled bool) {
 		var (
-			signalEventCalled = false
-			addMessageCalled  = false
-			log               = mockLogger{}
-			backend           = mockBackend{}
-			transport         = mockTransport{}
-			messages          = mockMessages{}
+			addMessageCalled = false
+			log              = mockLogger{}
+			backend          = mockBackend{}
+			transport        = mockTransport{}
+			msgs             = mockMessages{}
 		)
 
 		backend.IsValidValidatorFn = func(m *proto.Message) bool {
 			return bytes.Equal(m.From, validSender)
 		}
 
-		backend.getVotingPowerFn = testCommonGetVotingPowertFnForCnt(quorumSize)
+		backend.getVotingPowerFn = testCommonGetVotingPowertFnForCnt(1)
 
-		messages.getValidMessagesFn = func(
-			view *proto.View,
-			messageType proto.MessageType,
-			isValid func(message *proto.Message) bool,
-		) []*proto.Message {
-			return []*proto.Message{msg}
-		}
-
-		messages.addMessageFn = func(m *proto.Message) {
+		msgs.addMessageFn = func(m *proto.Message) *messages.Evidence {
 			addMessageCalled = true
 
 			assert.Equal(t, msg, m)
-		}
 
-		messages.signalEventFn = func(messageType proto.MessageType, messageView *proto.View) {
-			signalEventCalled = true
+			return nil
 		}
 
 		i := NewIBFT(log, backend, transport)
 		require.NoError(t, i.validatorManager.Init(0))
-		i.messages = messages
+		i.messages = msgs
 		i.state.setView(&proto.View{Height: validHeight, Round: validRound})
 
 		i.AddMessage(msg)
 
 		assert.Equal(t, shouldAddMessageCalled, addMessageCalled)
-		assert.Equal(t, shouldSignalEventCalled, signalEventCalled)
 	}
 
 	t.Run("nil message case", func(t *testing.T) {
 		t.Parallel()
 
-		executeTest(nil, false, false, 1)
+		executeTest(nil, false)
 	})
 
 	t.Run("!isAcceptableMessage - invalid sender", func(t *testing.T) {
@@ -3187,7 +3185,7 @@ func TestIBFT_AddMessage(t *testing.T) {
 			View: &proto.View{Height: validHeight, Round: validRound},
 			Type: validMsgType,
 		}
-		executeTest(msg, false, false, 1)
+		executeTest(msg, false)
 	})
 
 	t.Run("!isAcceptableMessage - invalid view", func(t *testing.T) {
@@ -3197,7 +3195,7 @@ func TestIBFT_AddMessage(t *testing.T) {
 			From: validSender,
 			Type: validMsgType,
 		}
-		executeTest(msg, false, false, 1)
+		executeTest(msg, false)
 	})
 
 	t.Run("!isAcceptableMessage - invalid height", func(t *testing.T) {
@@ -3208,7 +3206,7 @@ func TestIBFT_AddMessage(t *testing.T) {
 			Type: validMsgType,
 			View: &proto.View{Height: validHeight - 1, Round: validRound},
 		}
-		executeTest(msg, false, false, 1)
+		executeTest(msg, false)
 	})
 
 	t.Run("!isAcceptableMessage - invalid round", func(t *testing.T) {
@@ -3219,28 +3217,498 @@ func TestIBFT_AddMessage(t *testing.T) {
 			Type: validMsgType,
 			View: &proto.View{Height: validHeight, Round: validRound - 1},
 		}
-		executeTest(msg, false, false, 1)
+		executeTest(msg, false)
 	})
 
-	t.Run("correct - but quorum not reached", func(t *testing.T) {
+	t.Run("correct", func(t *testing.T) {
 		t.Parallel()
 
 		msg := &proto.Message{
 			From: validSender,
-			Type: proto.MessageType_PREPARE,
+			Type: validMsgType,
 			View: &proto.View{Height: validHeight, Round: validRound},
 		}
-		executeTest(msg, true, false, 2)
+		executeTest(msg, true)
 	})
+}
+
+func TestIBFT_AddMessage_Equivocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+
+		first  = buildBasicPrepareMessage([]byte("hash 1"), []byte("node 0"), view)
+		second = buildBasicPrepareMessage([]byte("hash 2"), []byte("node 0"), view)
+
+		reported = make([]*messages.Evidence, 0)
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			reportEquivocationFn: func(evidence *messages.Evidence) {
+				reported = append(reported, evidence)
+			},
+		},
+		mockTransport{},
+	)
+	i.state.setView(view)
+
+	i.AddMessage(first)
+	i.AddMessage(second)
+
+	// Make sure the conflicting messages are reported once
+	i.AddMessage(second)
+
+	require.Len(t, reported, 1)
+	assert.Equal(t, first, reported[0].First)
+	assert.Equal(t, second, reported[0].Second)
+}
+
+func TestIBFT_WaitForRCC_HigherRound(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height = uint64(1)
+		round  = uint64(2)
+
+		notifyCh = make(chan uint64, 2)
+		calls    = 0
+
+		higherRoundRCC = []*proto.Message{
+			buildBasicRoundChangeMessage(nil, nil, &proto.View{Height: height, Round: round + 1}, []byte("node 0")),
+		}
+		roundRCC = []*proto.Message{
+			buildBasicRoundChangeMessage(nil, nil, &proto.View{Height: height, Round: round}, []byte("node 0")),
+		}
+	)
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+	i.messages = mockMessages{
+		subscribeFn: func(_ messages.SubscriptionDetails) *messages.Subscription {
+			return &messages.Subscription{
+				ID:    messages.SubscriptionID(1),
+				SubCh: notifyCh,
+			}
+		},
+		getExtendedRCCFn: func(
+			_ uint64,
+			_ func(message *proto.Message) bool,
+			_ func(round uint64, messages []*proto.Message) bool,
+		) []*proto.Message {
+			calls++
+
+			// The other validators are already on a higher round
+			if calls == 1 {
+				return higherRoundRCC
+			}
+
+			return roundRCC
+		},
+	}
+
+	notifyCh <- round
+	notifyCh <- round
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Second)
+	defer cancelFn()
+
+	// Make sure only the certificate for the round is returned
+	rcc := i.waitForRCC(ctx, height, round)
+	require.NotNil(t, rcc)
+	assert.Equal(t, roundRCC, rcc.RoundChangeMessages)
+}
+
+// TestIBFT_GetRoundSkip_SpreadRounds makes sure the senders of the ROUND_CHANGE messages
+// are aggregated across all the higher rounds, and the lowest of them is picked
+func TestIBFT_GetRoundSkip_SpreadRounds(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes = generateNodeAddresses(4)
+		view  = &proto.View{Height: 1, Round: 0}
+	)
+
+	// newIBFT creates an instance of 4 validators with the same voting power,
+	// where 2 of them are above the faulty threshold
+	newIBFT := func(t *testing.T, rounds map[int][]uint64) *IBFT {
+		t.Helper()
 
-	t.Run("correct - quorum reached", func(t *testing.T) {
+		i := NewIBFT(mockLogger{}, mockBackend{
+			getVotingPowerFn: func(_ uint64) (map[string]*big.Int, error) {
+				votingPowers := make(map[string]*big.Int, len(nodes))
+				for _, node := range nodes {
+					votingPowers[string(node)] = big.NewInt(3)
+				}
+
+				return votingPowers, nil
+			},
+		}, mockTransport{})
+
+		require.NoError(t, i.validatorManager.Init(view.Height))
+		i.state.reset(view.Height)
+
+		for node, nodeRounds := range rounds {
+			for _, round := range nodeRounds {
+				i.messages.AddMessage(buildBasicRoundChangeMessage(
+					nil,
+					nil,
+					&proto.View{Height: view.Height, Round: round},
+					nodes[node],
+				))
+			}
+		}
+
+		return i
+	}
+
+	t.Run("spread out rounds trigger the skip", func(t *testing.T) {
 		t.Parallel()
 
-		msg := &proto.Message{
-			From: validSender,
-			Type: validMsgType,
-			View: &proto.View{Height: validHeight, Round: validRound},
+		i := newIBFT(t, map[int][]uint64{
+			1: {1},
+			2: {2},
+		})
+
+		round, ok := i.getRoundSkip(view)
+		require.True(t, ok)
+		assert.Equal(t, uint64(1), round)
+	})
+
+	t.Run("rounds of a single sender don't trigger the skip", func(t *testing.T) {
+		t.Parallel()
+
+		i := newIBFT(t, map[int][]uint64{
+			1: {1, 2, 3},
+			2: {0},
+		})
+
+		_, ok := i.getRoundSkip(view)
+		assert.False(t, ok)
+	})
+}
+
+// TestIBFT_RunSequence_SkipOnStart makes sure the node starts the sequence in the round
+// the validators above the faulty threshold already moved to, instead of the first one
+func TestIBFT_RunSequence_SkipOnStart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes      = generateNodeAddresses(4)
+		height     = uint64(1)
+		skipRound  = uint64(2)
+		startedCh  = make(chan *proto.View, 1)
+		multicasts = make(chan *proto.Message, 1)
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			idFn:             func() []byte { return nodes[0] },
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+			startRoundFn: func(view *proto.View) error {
+				select {
+				case startedCh <- view:
+				default:
+				}
+
+				return nil
+			},
+			buildRoundChangeMessageFn: func(
+				proposal *proto.Proposal,
+				certificate *proto.PreparedCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return buildBasicRoundChangeMessage(proposal, certificate, view, nodes[0])
+			},
+		},
+		mockTransport{multicastFn: func(message *proto.Message) {
+			select {
+			case multicasts <- message:
+			default:
+			}
+		}},
+	)
+
+	// The other validators already moved to a higher round
+	for _, node := range nodes[1:3] {
+		i.AddMessage(buildBasicRoundChangeMessage(nil, nil, &proto.View{Height: height, Round: skipRound}, node))
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = i.RunSequence(ctx, height)
+	}()
+
+	// Make sure the first round started is the one of the validators
+	view := <-startedCh
+	assert.Equal(t, skipRound, view.Round)
+
+	message := <-multicasts
+	assert.Equal(t, proto.MessageType_ROUND_CHANGE, message.Type)
+	assert.Equal(t, skipRound, message.View.Round)
+
+	cancelFn()
+	<-done
+}
+
+// TestIBFT_HandleCommit_SealCollection makes sure more commit messages are
+// collected after the commit quorum is reached, for the commit grace period
+func TestIBFT_HandleCommit_SealCollection(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
 		}
-		executeTest(msg, true, true, 1)
+		seal = []byte("committed seal")
+	)
+
+	// newIBFT creates an instance of 4 validators, with the commit messages
+	// of the first 3 validators, which make the quorum
+	newIBFT := func(t *testing.T, opts ...Option) *IBFT {
+		t.Helper()
+
+		i, err := NewIBFTWithOptions(
+			mockLogger{},
+			mockBackend{
+				getVotingPowerFn: testCommonGetVotingPowertFnForCnt(4),
+			},
+			mockTransport{},
+			append([]Option{WithCommitGracePeriod(time.Hour)}, opts...)...,
+		)
+		require.NoError(t, err)
+		require.NoError(t, i.validatorManager.Init(view.Height))
+
+		i.state.setView(view)
+
+		for index := 0; index < 3; index++ {
+			i.messages.AddMessage(
+				buildBasicCommitMessage(validProposalHash, seal, []byte(fmt.Sprintf("node %d", index)), view),
+			)
+		}
+
+		return i
+	}
+
+	// handleCommit runs the commit handler in the background
+	handleCommit := func(ctx context.Context, i *IBFT, commitCh <-chan uint64) <-chan bool {
+		doneCh := make(chan bool, 1)
+
+		go func() {
+			doneCh <- i.handleCommit(ctx, view, commitCh)
+		}()
+
+		return doneCh
+	}
+
+	t.Run("target reached", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			commitCh = make(chan uint64, 1)
+			clock    = NewFakeClock(time.Unix(0, 0))
+			sink     = metrics.NewInmemSink(time.Minute, time.Minute)
+			i        = newIBFT(t, WithClock(clock), WithMetricsSink(sink))
+			doneCh   = handleCommit(context.Background(), i, commitCh)
+		)
+
+		// The last commit message ends the grace period, without advancing the clock
+		clock.BlockUntil(1)
+		i.messages.AddMessage(buildBasicCommitMessage(validProposalHash, seal, []byte("node 3"), view))
+		commitCh <- view.Round
+
+		select {
+		case done := <-doneCh:
+			assert.True(t, done)
+		case <-time.After(5 * time.Second):
+			t.Fatal("seal collection not finished")
+		}
+
+		assert.Equal(t, fin, i.state.getStateName())
+		assert.Len(t, i.state.getCommittedSeals(), 4)
+
+		data := sink.Data()
+		require.Len(t, data, 1)
+		assert.Equal(t, float32(1), data[0].Gauges["go-ibft.commit.extra_seals"].Value)
+	})
+
+	t.Run("grace period expired", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			clock  = NewFakeClock(time.Unix(0, 0))
+			i      = newIBFT(t, WithClock(clock))
+			doneCh = handleCommit(context.Background(), i, nil)
+		)
+
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+
+		select {
+		case done := <-doneCh:
+			assert.True(t, done)
+		case <-time.After(5 * time.Second):
+			t.Fatal("seal collection not finished")
+		}
+
+		assert.Equal(t, fin, i.state.getStateName())
+		assert.Len(t, i.state.getCommittedSeals(), 3)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+
+		var (
+			clock  = NewFakeClock(time.Unix(0, 0))
+			i      = newIBFT(t, WithClock(clock))
+			doneCh = handleCommit(ctx, i, nil)
+		)
+
+		clock.BlockUntil(1)
+		cancelFn()
+
+		select {
+		case done := <-doneCh:
+			assert.True(t, done)
+		case <-time.After(5 * time.Second):
+			t.Fatal("seal collection not finished")
+		}
+
+		// Make sure the round is finalized with the seals collected so far
+		assert.Equal(t, fin, i.state.getStateName())
+		assert.Len(t, i.state.getCommittedSeals(), 3)
 	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		i := newIBFT(t, WithCommitGracePeriod(0))
+
+		assert.True(t, i.handleCommit(context.Background(), view, nil))
+		assert.Len(t, i.state.getCommittedSeals(), 3)
+	})
+}
+
+func TestIBFT_TrackView(t *testing.T) {
+	t.Parallel()
+
+	store := messages.NewMessagesWithLimits(messages.Limits{MaxRoundLookahead: 1})
+	defer store.Close()
+
+	i, err := NewIBFTWithOptions(mockLogger{}, mockBackend{}, mockTransport{}, WithMessages(store))
+	require.NoError(t, err)
+
+	message := buildBasicPrepareMessage(validProposalHash, []byte("node"), &proto.View{Height: 0, Round: 3})
+
+	// Make sure the message is rejected until the state machine gets close to its round
+	store.AddMessage(message)
+	assert.Equal(t, uint64(1), store.LimitCounters().RoundLookahead)
+
+	i.moveToNewRound(2)
+
+	store.AddMessage(message)
+	assert.Equal(t, uint64(1), store.LimitCounters().RoundLookahead)
+	assert.Len(t, store.GetValidMessages(message.View, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return true
+	}), 1)
+}
+
+// TestIBFT_GetValidCommitMessages_Cached makes sure the stored commit messages
+// are validated only once per proposal, instead of on each new message arrival
+func TestIBFT_GetValidCommitMessages_Cached(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+		seal = []byte("committed seal")
+
+		sealValidations int
+	)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			isValidCommittedSealFn: func(_ []byte, _ *messages.CommittedSeal) bool {
+				sealValidations++
+
+				return true
+			},
+		},
+		mockTransport{},
+	)
+	require.NoError(t, err)
+
+	i.state.setView(view)
+
+	for index := 0; index < 3; index++ {
+		i.messages.AddMessage(
+			buildBasicCommitMessage(validProposalHash, seal, []byte(fmt.Sprintf("node %d", index)), view),
+		)
+
+		assert.Len(t, i.getValidCommitMessages(view), index+1)
+	}
+
+	// Make sure each committed seal was verified once
+	assert.Equal(t, 3, sealValidations)
+}
+
+// TestIBFT_Subscribe_Quorum makes sure the subscribers are notified
+// only once the messages reach the quorum
+func TestIBFT_Subscribe_Quorum(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+		seal = []byte("committed seal")
+	)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			getVotingPowerFn: testCommonGetVotingPowertFnForCnt(4),
+		},
+		mockTransport{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, i.validatorManager.Init(view.Height))
+
+	i.state.setView(view)
+
+	sub := i.subscribe(messages.SubscriptionDetails{
+		MessageType: proto.MessageType_COMMIT,
+		View:        view,
+	})
+	defer i.messages.Unsubscribe(sub.ID)
+
+	for index := 0; index < 3; index++ {
+		i.messages.AddMessage(
+			buildBasicCommitMessage(validProposalHash, seal, []byte(fmt.Sprintf("node %d", index)), view),
+		)
+
+		select {
+		case <-sub.SubCh:
+			// Make sure the subscriber is notified only with the quorum of 3 validators
+			assert.Equal(t, 2, index)
+		case <-time.After(100 * time.Millisecond):
+			assert.NotEqual(t, 2, index)
+		}
+	}
 }
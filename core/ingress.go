@@ -0,0 +1,217 @@
+package core
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+const (
+	// ingressSeenFactor is the number of the recently seen message hashes
+	// kept for deduplication, relative to the ingress queue size
+	ingressSeenFactor = 4
+)
+
+var (
+	// ErrIngressFull is returned when the message cannot be queued,
+	// since the ingress queue is full
+	ErrIngressFull = errors.New("ingress queue is full")
+)
+
+// ingress is the asynchronous pipeline of the incoming messages. The messages are
+// deduplicated by their hash, queued, and added by the workers in parallel, so the
+// signature verification doesn't run on the goroutine of the transport.
+// The deduplication only lasts for the current view, since a message dropped
+// by the message store, for example for being too far ahead of the view,
+// must be accepted again when it is re-sent after the view changes
+type ingress struct {
+	queue   chan *proto.Message
+	workers int
+
+	// add adds the message, after verifying its signature
+	add func(message *proto.Message)
+
+	// backpressure is notified when the queue is saturated, if set
+	backpressure BackpressureHandler
+
+	startOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+
+	lock sync.Mutex
+
+	// seen are the hashes of the messages recently queued in the current view,
+	// which are evicted in the order they were added
+	seen      map[[sha256.Size]byte]struct{}
+	seenOrder [][sha256.Size]byte
+	seenNext  int
+
+	saturated bool
+	closed    bool
+}
+
+// newIngress creates a new ingress pipeline, which is started on the first submitted message
+func newIngress(
+	queueSize,
+	workers int,
+	add func(message *proto.Message),
+	backpressure BackpressureHandler,
+) *ingress {
+	return &ingress{
+		queue:        make(chan *proto.Message, queueSize),
+		workers:      workers,
+		add:          add,
+		backpressure: backpressure,
+		done:         make(chan struct{}),
+		seen:         make(map[[sha256.Size]byte]struct{}),
+		seenOrder:    make([][sha256.Size]byte, queueSize*ingressSeenFactor),
+	}
+}
+
+// submit queues the message, unless the same message was recently queued.
+// ErrIngressFull is returned if the queue is full
+func (in *ingress) submit(message *proto.Message) error {
+	hash, err := messages.Digest(message)
+	if err != nil {
+		return err
+	}
+
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	if in.closed {
+		return ErrClosed
+	}
+
+	in.startOnce.Do(in.start)
+
+	if _, ok := in.seen[hash]; ok {
+		return nil
+	}
+
+	select {
+	case in.queue <- message:
+		in.markSeen(hash)
+
+		return nil
+	default:
+		if !in.saturated {
+			in.saturated = true
+			in.notifyBackpressure(true)
+		}
+
+		return ErrIngressFull
+	}
+}
+
+// start starts the workers
+func (in *ingress) start() {
+	in.wg.Add(in.workers)
+
+	for n := 0; n < in.workers; n++ {
+		go in.runWorker()
+	}
+}
+
+// runWorker adds the queued messages, until the ingress is closed
+func (in *ingress) runWorker() {
+	defer in.wg.Done()
+
+	for {
+		select {
+		case <-in.done:
+			return
+		case message := <-in.queue:
+			in.checkDrained()
+
+			in.add(message)
+		}
+	}
+}
+
+// checkDrained lifts the backpressure, once the queue is drained below half of its capacity
+func (in *ingress) checkDrained() {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	if in.saturated && len(in.queue) <= cap(in.queue)/2 {
+		in.saturated = false
+		in.notifyBackpressure(false)
+	}
+}
+
+// close stops the workers, and waits for them to finish.
+// The messages still in the queue are dropped
+func (in *ingress) close() {
+	in.lock.Lock()
+
+	if in.closed {
+		in.lock.Unlock()
+
+		return
+	}
+
+	in.closed = true
+	in.lock.Unlock()
+
+	close(in.done)
+	in.wg.Wait()
+}
+
+// markSeen notes the message hash, evicting the oldest one if the set is full.
+// The caller must hold the lock
+func (in *ingress) markSeen(hash [sha256.Size]byte) {
+	if len(in.seen) == len(in.seenOrder) {
+		delete(in.seen, in.seenOrder[in.seenNext])
+	}
+
+	in.seen[hash] = struct{}{}
+	in.seenOrder[in.seenNext] = hash
+	in.seenNext = (in.seenNext + 1) % len(in.seenOrder)
+}
+
+// resetSeen forgets the message hashes seen so far, when the state machine moves to a new view
+func (in *ingress) resetSeen() {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	// The slots of the evicted hashes are all overwritten before the set is full again,
+	// so the hashes left in them are never evicted from the new set
+	in.seen = make(map[[sha256.Size]byte]struct{})
+	in.seenNext = 0
+}
+
+// notifyBackpressure notifies the handler, if set. The caller must hold the lock
+func (in *ingress) notifyBackpressure(saturated bool) {
+	if in.backpressure != nil {
+		in.backpressure.OnBackpressure(saturated)
+	}
+}
+
+// SubmitMessage queues the message for the ingress pipeline, where its signature is
+// verified by one of the workers before the message is added. The cheap checks are
+// done before queueing it, so the messages of the past views are dropped early.
+// ErrIngressFull is returned if the queue is full, in which case the transport should
+// throttle its peers. If the ingress pipeline is not enabled, the message is added
+// synchronously, like with AddMessage
+func (i *IBFT) SubmitMessage(message *proto.Message) error {
+	// Make sure the message is present
+	if message == nil {
+		return nil
+	}
+
+	if i.ingress == nil {
+		i.AddMessage(message)
+
+		return nil
+	}
+
+	if !i.isAcceptableView(message) {
+		return nil
+	}
+
+	return i.ingress.submit(message)
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// mockBackpressureTransport is the mock transport which is notified of the backpressure
+type mockBackpressureTransport struct {
+	mockTransport
+
+	onBackpressureFn func(saturated bool)
+}
+
+func (t mockBackpressureTransport) OnBackpressure(saturated bool) {
+	t.onBackpressureFn(saturated)
+}
+
+// newIngressIBFT creates an IBFT instance with the ingress pipeline,
+// which verifies the messages with the passed in function
+func newIngressIBFT(
+	t *testing.T,
+	queueSize,
+	workers int,
+	isValidValidator func(*proto.Message) bool,
+	transport Transport,
+) *IBFT {
+	t.Helper()
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{IsValidValidatorFn: isValidValidator},
+		transport,
+		WithIngress(queueSize, workers),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = i.Close()
+	})
+
+	return i
+}
+
+// numPrepareMessages returns the number of the stored PREPARE messages for the view
+func numPrepareMessages(i *IBFT, view *proto.View) int {
+	return len(i.messages.GetValidMessages(view, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return true
+	}))
+}
+
+func TestIBFT_SubmitMessage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		verified atomic.Int64
+		view     = &proto.View{Height: 0, Round: 0}
+	)
+
+	i := newIngressIBFT(t, 10, 2, func(_ *proto.Message) bool {
+		verified.Add(1)
+
+		return true
+	}, mockTransport{})
+
+	senders := generateNodeAddresses(3)
+	for _, sender := range senders {
+		require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, sender, view)))
+	}
+
+	// Make sure the duplicates and the messages of the past views are dropped before verification
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, senders[0], view)))
+	require.NoError(t, i.SubmitMessage(nil))
+
+	i.state.setView(&proto.View{Height: 1, Round: 0})
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, []byte("past"), view)))
+
+	i.state.setView(view)
+
+	require.Eventually(t, func() bool {
+		return numPrepareMessages(i, view) == len(senders)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int64(len(senders)), verified.Load())
+
+	// Make sure the messages are not accepted once the instance is closed
+	require.NoError(t, i.Close())
+	assert.ErrorIs(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, []byte("node"), view)), ErrClosed)
+}
+
+func TestIBFT_SubmitMessage_ParallelVerification(t *testing.T) {
+	t.Parallel()
+
+	var (
+		workers  = 4
+		view     = &proto.View{Height: 0, Round: 0}
+		barrier  sync.WaitGroup
+		released = make(chan struct{})
+	)
+
+	barrier.Add(workers)
+
+	// Each verification waits for all the workers to be verifying
+	i := newIngressIBFT(t, workers, workers, func(_ *proto.Message) bool {
+		barrier.Done()
+		<-released
+
+		return true
+	}, mockTransport{})
+
+	for _, sender := range generateNodeAddresses(uint64(workers)) {
+		require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, sender, view)))
+	}
+
+	verifying := make(chan struct{})
+
+	go func() {
+		barrier.Wait()
+		close(verifying)
+	}()
+
+	select {
+	case <-verifying:
+		close(released)
+	case <-time.After(5 * time.Second):
+		close(released)
+		t.Fatal("messages not verified in parallel")
+	}
+
+	require.Eventually(t, func() bool {
+		return numPrepareMessages(i, view) == workers
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestIBFT_SubmitMessage_Backpressure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view          = &proto.View{Height: 0, Round: 0}
+		senders       = generateNodeAddresses(3)
+		verifying     = make(chan struct{}, len(senders))
+		released      = make(chan struct{})
+		backpressured = make(chan bool, 2)
+	)
+
+	i := newIngressIBFT(t, 1, 1, func(_ *proto.Message) bool {
+		verifying <- struct{}{}
+		<-released
+
+		return true
+	}, mockBackpressureTransport{
+		onBackpressureFn: func(saturated bool) {
+			backpressured <- saturated
+		},
+	})
+
+	// The first message is taken by the worker, and the second one fills the queue
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, senders[0], view)))
+	<-verifying
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, senders[1], view)))
+
+	// Make sure the transport is notified when the queue is full
+	err := i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, senders[2], view))
+	assert.ErrorIs(t, err, ErrIngressFull)
+	assert.True(t, <-backpressured)
+
+	// Make sure the backpressure is lifted once the queue is drained
+	close(released)
+
+	select {
+	case saturated := <-backpressured:
+		assert.False(t, saturated)
+	case <-time.After(5 * time.Second):
+		t.Fatal("backpressure not lifted")
+	}
+
+	// Make sure the rejected message can be submitted again
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, senders[2], view)))
+
+	require.Eventually(t, func() bool {
+		return numPrepareMessages(i, view) == len(senders)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestIBFT_SubmitMessage_ResentAfterViewChange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		verified atomic.Int64
+		view     = &proto.View{Height: 0, Round: 1}
+		message  = buildBasicPrepareMessage(validProposalHash, []byte("node"), view)
+	)
+
+	i := newIngressIBFT(t, 10, 1, func(_ *proto.Message) bool {
+		verified.Add(1)
+
+		return true
+	}, mockTransport{})
+
+	require.NoError(t, i.SubmitMessage(message))
+
+	require.Eventually(t, func() bool {
+		return verified.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The message is dropped from the store, like when it is evicted
+	i.messages.GetValidMessages(view, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return false
+	})
+
+	// Make sure the re-sent message is deduplicated within the view
+	require.NoError(t, i.SubmitMessage(message))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(1), verified.Load())
+
+	// Make sure the re-sent message is accepted again once the view changes
+	i.state.setView(view)
+	i.trackView(view)
+
+	require.NoError(t, i.SubmitMessage(message))
+
+	require.Eventually(t, func() bool {
+		return numPrepareMessages(i, view) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int64(2), verified.Load())
+}
+
+func TestIBFT_SubmitMessage_Synchronous(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{Height: 0, Round: 0}
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+	// Make sure the message is added right away without the ingress pipeline
+	require.NoError(t, i.SubmitMessage(buildBasicPrepareMessage(validProposalHash, []byte("node"), view)))
+	assert.Equal(t, 1, numPrepareMessages(i, view))
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrAlreadyStarted is returned when starting an IBFT instance that is already running
+	ErrAlreadyStarted = errors.New("ibft is already started")
+
+	// ErrNotStarted is returned when stopping an IBFT instance that is not running
+	ErrNotStarted = errors.New("ibft is not started")
+
+	// ErrClosed is returned when using an IBFT instance that is closed
+	ErrClosed = errors.New("ibft is closed")
+)
+
+type lifecycleState uint8
+
+const (
+	// idle is the state of a new instance. Sequences can be run,
+	// and they are cancelled when the instance is stopped or closed
+	idle lifecycleState = iota
+
+	// running is the state of a started instance
+	running
+
+	// stopped is the state of a stopped instance, it can be started again
+	stopped
+
+	// closed is the final state of an instance
+	closed
+)
+
+// lifecycle keeps track of the IBFT instance state, and
+// of the running sequences. The zero value is ready to use
+type lifecycle struct {
+	sync.Mutex
+
+	state lifecycleState
+
+	// cancelFns maps the running sequences to their cancel functions
+	cancelFns map[uint64]context.CancelFunc
+	nextID    uint64
+
+	// sequences is the barrier for the running sequences
+	sequences sync.WaitGroup
+}
+
+// cancelSequences cancels all the running sequences.
+// The caller must hold the lock
+func (l *lifecycle) cancelSequences() {
+	for _, cancelFn := range l.cancelFns {
+		cancelFn()
+	}
+}
+
+// Start starts the IBFT instance, after which it can run sequences.
+// A stopped instance can be started again
+func (i *IBFT) Start() error {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	switch i.lifecycle.state {
+	case running:
+		return ErrAlreadyStarted
+	case closed:
+		return ErrClosed
+	case idle, stopped:
+	}
+
+	i.lifecycle.state = running
+
+	return nil
+}
+
+// Stop cancels the running sequence, if any, and waits for
+// all its worker routines to finish. The instance can be started again.
+// An instance running sequences without being started is stopped as well
+func (i *IBFT) Stop() error {
+	i.lifecycle.Lock()
+
+	switch i.lifecycle.state {
+	case closed:
+		i.lifecycle.Unlock()
+
+		return ErrClosed
+	case idle:
+		if len(i.lifecycle.cancelFns) == 0 {
+			i.lifecycle.Unlock()
+
+			return ErrNotStarted
+		}
+	case stopped:
+		i.lifecycle.Unlock()
+
+		return ErrNotStarted
+	case running:
+	}
+
+	i.lifecycle.cancelSequences()
+	i.lifecycle.state = stopped
+	i.lifecycle.Unlock()
+
+	i.waitForSequences()
+
+	return nil
+}
+
+// Close stops the IBFT instance, if it is running, and releases its resources.
+// The instance cannot be used after it is closed
+func (i *IBFT) Close() error {
+	i.lifecycle.Lock()
+
+	if i.lifecycle.state == closed {
+		i.lifecycle.Unlock()
+
+		return ErrClosed
+	}
+
+	i.lifecycle.cancelSequences()
+	i.lifecycle.state = closed
+	i.lifecycle.Unlock()
+
+	i.waitForSequences()
+
+	if i.ingress != nil {
+		i.ingress.close()
+	}
+
+	// The subscriptions are closed only after all the routines
+	// using them are done
+	i.messages.Close()
+
+	return nil
+}
+
+// waitForSequences waits for the running sequences, and
+// for the worker routines that may outlive them, to finish
+func (i *IBFT) waitForSequences() {
+	i.lifecycle.sequences.Wait()
+	i.wg.Wait()
+}
+
+// enterSequence registers a new sequence, and derives a context from the passed in one,
+// which is cancelled when the instance is stopped. It returns an error if the instance
+// is stopped or closed. The returned release function must be called once the sequence is done
+func (i *IBFT) enterSequence(ctx context.Context) (context.Context, func(), error) {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	switch i.lifecycle.state {
+	case stopped:
+		return nil, nil, ErrNotStarted
+	case closed:
+		return nil, nil, ErrClosed
+	case idle, running:
+	}
+
+	if i.lifecycle.cancelFns == nil {
+		i.lifecycle.cancelFns = make(map[uint64]context.CancelFunc)
+	}
+
+	id := i.lifecycle.nextID
+	i.lifecycle.nextID++
+
+	ctx, cancelFn := context.WithCancel(ctx)
+	i.lifecycle.cancelFns[id] = cancelFn
+	i.lifecycle.sequences.Add(1)
+
+	return ctx, func() {
+		cancelFn()
+
+		i.lifecycle.Lock()
+		delete(i.lifecycle.cancelFns, id)
+		i.lifecycle.Unlock()
+
+		i.lifecycle.sequences.Done()
+	}, nil
+}
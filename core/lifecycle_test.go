@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+)
+
+func TestIBFT_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+	assert.ErrorIs(t, i.Stop(), ErrNotStarted)
+
+	require.NoError(t, i.Start())
+	assert.ErrorIs(t, i.Start(), ErrAlreadyStarted)
+
+	require.NoError(t, i.Stop())
+	assert.ErrorIs(t, i.Stop(), ErrNotStarted)
+
+	// Make sure a stopped instance can be started again
+	require.NoError(t, i.Start())
+
+	require.NoError(t, i.Close())
+	assert.ErrorIs(t, i.Close(), ErrClosed)
+	assert.ErrorIs(t, i.Start(), ErrClosed)
+	assert.ErrorIs(t, i.Stop(), ErrClosed)
+}
+
+// TestIBFT_StopRunningSequence makes sure stopping the instance
+// cancels the running sequence without leaking any routines.
+// The test is not parallel, so the leak check isn't affected by other tests
+func TestIBFT_StopRunningSequence(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var (
+		nodes = generateNodeAddresses(4)
+		done  = make(chan struct{})
+		msgs  = messages.NewMessages()
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+			isProposerFn: func(_ []byte, _ uint64, _ uint64) bool {
+				return false
+			},
+		},
+		mockTransport{},
+	)
+	i.messages = msgs
+
+	require.NoError(t, i.Start())
+
+	go func() {
+		defer close(done)
+
+		_, _ = i.RunSequence(context.Background(), 1)
+	}()
+
+	// Wait for the round workers to subscribe for messages
+	require.Eventually(t, func() bool {
+		return msgs.NumSubscriptions() > 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, i.Stop())
+
+	// Make sure the sequence is done, and all the subscriptions are cancelled
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sequence not done after stop")
+	}
+
+	assert.Equal(t, int64(0), msgs.NumSubscriptions())
+
+	// Make sure sequences don't run on a stopped instance
+	_, err := i.RunSequence(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNotStarted)
+
+	require.NoError(t, i.Close())
+}
+
+// TestIBFT_StopIdleRunningSequence makes sure stopping the instance
+// cancels the sequence that was run without starting the instance
+func TestIBFT_StopIdleRunningSequence(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes = generateNodeAddresses(4)
+		done  = make(chan struct{})
+		msgs  = messages.NewMessages()
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+			isProposerFn: func(_ []byte, _ uint64, _ uint64) bool {
+				return false
+			},
+		},
+		mockTransport{},
+	)
+	i.messages = msgs
+
+	go func() {
+		defer close(done)
+
+		_, _ = i.RunSequence(context.Background(), 1)
+	}()
+
+	// Wait for the round workers to subscribe for messages
+	require.Eventually(t, func() bool {
+		return msgs.NumSubscriptions() > 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, i.Stop())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sequence not done after stop")
+	}
+
+	// Make sure the instance is stopped
+	assert.ErrorIs(t, i.Stop(), ErrNotStarted)
+
+	_, err := i.RunSequence(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNotStarted)
+
+	require.NoError(t, i.Close())
+}
@@ -61,10 +61,11 @@ type buildRoundChangeMessageDelegate func(
 	*proto.View,
 ) *proto.Message
 
-type insertProposalDelegate func(*proto.Proposal, []*messages.CommittedSeal)
+type insertProposalDelegate func(*proto.Proposal, []*messages.CommittedSeal) error
 type idDelegate func() []byte
 type getVotingPowerDelegate func(uint64) (map[string]*big.Int, error)
 type startRoundDelegate func(*proto.View) error
+type reportEquivocationDelegate func(*messages.Evidence)
 
 var _ Backend = &mockBackend{}
 
@@ -85,6 +86,7 @@ type mockBackend struct {
 	idFn                      idDelegate
 	getVotingPowerFn          getVotingPowerDelegate
 	startRoundFn              startRoundDelegate
+	reportEquivocationFn      reportEquivocationDelegate
 }
 
 func (m mockBackend) ID() []byte {
@@ -95,9 +97,17 @@ func (m mockBackend) ID() []byte {
 	return nil
 }
 
-func (m mockBackend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) {
+func (m mockBackend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
 	if m.insertProposalFn != nil {
-		m.insertProposalFn(proposal, committedSeals)
+		return m.insertProposalFn(proposal, committedSeals)
+	}
+
+	return nil
+}
+
+func (m mockBackend) ReportEquivocation(evidence *messages.Evidence) {
+	if m.reportEquivocationFn != nil {
+		m.reportEquivocationFn(evidence)
 	}
 }
 
@@ -226,6 +236,30 @@ func (t mockTransport) Multicast(msg *proto.Message) {
 	}
 }
 
+// Define delegation methods
+type unicastFnDelegate func([]byte, *proto.Message)
+type requestMessagesFnDelegate func([]byte, *MessageRequest)
+
+// mockDirectTransport is the mock direct transport structure that is configurable
+type mockDirectTransport struct {
+	mockTransport
+
+	unicastFn         unicastFnDelegate
+	requestMessagesFn requestMessagesFnDelegate
+}
+
+func (t mockDirectTransport) Unicast(to []byte, msg *proto.Message) {
+	if t.unicastFn != nil {
+		t.unicastFn(to, msg)
+	}
+}
+
+func (t mockDirectTransport) RequestMessages(to []byte, request *MessageRequest) {
+	if t.requestMessagesFn != nil {
+		t.requestMessagesFn(to, request)
+	}
+}
+
 // Define delegation methods
 type opLogDelegate func(string, ...interface{})
 
@@ -255,7 +289,7 @@ func (l mockLogger) Error(msg string, args ...interface{}) {
 }
 
 type mockMessages struct {
-	addMessageFn    func(message *proto.Message)
+	addMessageFn    func(message *proto.Message) *messages.Evidence
 	pruneByHeightFn func(height uint64)
 	signalEventFn   func(messageType proto.MessageType, messageView *proto.View)
 
@@ -270,9 +304,11 @@ type mockMessages struct {
 		isValidRCC func(round uint64, messages []*proto.Message) bool,
 	) []*proto.Message
 	getMostRoundChangeMessagesFn func(uint64, uint64) []*proto.Message
+	getRoundChangeMessagesFn     func(uint64, uint64) []*proto.Message
 
 	subscribeFn   func(details messages.SubscriptionDetails) *messages.Subscription
 	unsubscribeFn func(id messages.SubscriptionID)
+	closeFn       func()
 }
 
 func (m mockMessages) GetValidMessages(
@@ -301,9 +337,17 @@ func (m mockMessages) Unsubscribe(id messages.SubscriptionID) {
 	}
 }
 
-func (m mockMessages) AddMessage(msg *proto.Message) {
+func (m mockMessages) AddMessage(msg *proto.Message) *messages.Evidence {
 	if m.addMessageFn != nil {
-		m.addMessageFn(msg)
+		return m.addMessageFn(msg)
+	}
+
+	return nil
+}
+
+func (m mockMessages) Close() {
+	if m.closeFn != nil {
+		m.closeFn()
 	}
 }
 
@@ -339,6 +383,14 @@ func (m mockMessages) GetMostRoundChangeMessages(round, height uint64) []*proto.
 	return nil
 }
 
+func (m mockMessages) GetRoundChangeMessages(round, height uint64) []*proto.Message {
+	if m.getRoundChangeMessagesFn != nil {
+		return m.getRoundChangeMessagesFn(round, height)
+	}
+
+	return nil
+}
+
 type backendConfigCallback func(*mockBackend)
 type loggerConfigCallback func(*mockLogger)
 type transportConfigCallback func(*mockTransport)
@@ -475,6 +527,34 @@ type mockCluster struct {
 	ctxs  []mockNodeContext // context handlers for the nodes in the cluster
 
 	wg mockNodeWg
+
+	// clock is the fake clock of the nodes, if they use one
+	clock *FakeClock
+}
+
+// useFakeClock switches the nodes to a fake clock, which is only moved forward
+// when a round is expired (see expireRound)
+func (m *mockCluster) useFakeClock() {
+	m.clock = NewFakeClock(time.Unix(0, 0))
+
+	for _, node := range m.nodes {
+		node.config.clock = m.clock
+		node.config.commitGracePeriod = 0
+	}
+}
+
+// expireRound waits until all the nodes started the timers of the round, and expires them,
+// so the nodes move on to the next round. It returns false if the context is done first
+func (m *mockCluster) expireRound(ctx context.Context, round uint64) bool {
+	timeout := m.nodes[0].getConfig().roundTimeout(round)
+
+	if !awaitTimers(ctx, m.clock, timeout, len(m.nodes)) {
+		return false
+	}
+
+	m.clock.Advance(timeout)
+
+	return true
 }
 
 func (m *mockCluster) runSequence(height uint64) {
@@ -485,7 +565,7 @@ func (m *mockCluster) runSequence(height uint64) {
 
 		go func(ctx context.Context, node *IBFT) {
 			// Start the main run loop for the node
-			node.RunSequence(ctx, height)
+			_, _ = node.RunSequence(ctx, height)
 
 			m.wg.Done()
 		}(m.ctxs[nodeIndex].ctx, node)
@@ -520,17 +600,23 @@ func (m *mockCluster) awaitNCompletions(
 	ctx context.Context,
 	count int64,
 ) error {
+	// The completions are polled, instead of spinning,
+	// so the nodes are not starved of the CPU time
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
 	for {
+		if m.wg.getDone() >= count {
+			return nil
+		}
+
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf(
 				"await exceeded timeout for %d nodes",
 				count,
 			)
-		default:
-			if m.wg.getDone() >= count {
-				return nil
-			}
+		case <-ticker.C:
 		}
 	}
 }
@@ -558,7 +644,7 @@ func (m *mockCluster) areAllNodesOnRound(round uint64) bool {
 // setBaseTimeout sets the base timeout for rounds
 func (m *mockCluster) setBaseTimeout(timeout time.Duration) {
 	for _, node := range m.nodes {
-		node.baseRoundTimeout = timeout
+		node.config.baseRoundTimeout = timeout
 	}
 }
 
@@ -584,3 +670,45 @@ func (s *mockState) reset(height uint64) {
 		Round:  s.desiredStartRound,
 	}
 }
+
+// mockWAL is the mock write-ahead log structure that is configurable
+type mockWAL struct {
+	writeFn         func(*proto.WALEntry) error
+	readFn          func(uint64) ([]*proto.WALEntry, error)
+	pruneByHeightFn func(uint64) error
+}
+
+func (w mockWAL) Write(entry *proto.WALEntry) error {
+	if w.writeFn != nil {
+		return w.writeFn(entry)
+	}
+
+	return nil
+}
+
+func (w mockWAL) Read(height uint64) ([]*proto.WALEntry, error) {
+	if w.readFn != nil {
+		return w.readFn(height)
+	}
+
+	return nil, nil
+}
+
+func (w mockWAL) PruneByHeight(height uint64) error {
+	if w.pruneByHeightFn != nil {
+		return w.pruneByHeightFn(height)
+	}
+
+	return nil
+}
+
+// mockSyncNotifier is the mock sync notifier structure that is configurable
+type mockSyncNotifier struct {
+	onFutureHeightFn func(uint64)
+}
+
+func (n mockSyncNotifier) OnFutureHeight(height uint64) {
+	if n.onFutureHeightFn != nil {
+		n.onFutureHeightFn(height)
+	}
+}
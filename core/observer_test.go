@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// newObserver creates an observer which counts the messages
+// it builds or sends, and notes the inserted proposal
+func newObserver(
+	t *testing.T,
+	c *cluster,
+	signed *atomic.Int64,
+	inserted chan<- []byte,
+) *IBFT {
+	t.Helper()
+
+	countSigned := func() *proto.Message {
+		signed.Add(1)
+
+		return nil
+	}
+
+	observer, err := NewIBFTWithOptions(
+		mockLogger{},
+		&mockBackend{
+			isValidProposalFn:     isValidProposal,
+			isValidProposalHashFn: isValidProposalHash,
+			isProposerFn:          c.isProposer,
+			idFn: func() []byte {
+				return []byte("observer")
+			},
+			buildProposalFn: func(_ uint64) []byte {
+				countSigned()
+
+				return nil
+			},
+			buildPrePrepareMessageFn: func(_ []byte, _ *proto.RoundChangeCertificate, _ *proto.View) *proto.Message {
+				return countSigned()
+			},
+			buildPrepareMessageFn: func(_ []byte, _ *proto.View) *proto.Message {
+				return countSigned()
+			},
+			buildCommitMessageFn: func(_ []byte, _ *proto.View) *proto.Message {
+				return countSigned()
+			},
+			buildRoundChangeMessageFn: func(_ *proto.Proposal, _ *proto.PreparedCertificate, _ *proto.View) *proto.Message {
+				return countSigned()
+			},
+			insertProposalFn: func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
+				inserted <- proposal.RawProposal
+
+				return nil
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
+		},
+		&mockTransport{multicastFn: func(_ *proto.Message) {
+			signed.Add(1)
+		}},
+		WithObserverMode(true),
+	)
+	require.NoError(t, err)
+
+	return observer
+}
+
+// newObservedCluster creates a cluster of validators, which
+// also gossip their messages to the returned observer
+func newObservedCluster(
+	t *testing.T,
+	numNodes uint64,
+	signed *atomic.Int64,
+	inserted chan<- []byte,
+) (*cluster, *IBFT) {
+	t.Helper()
+
+	var observer *IBFT
+
+	c := newCluster(
+		numNodes,
+		func(c *cluster) {
+			for _, node := range c.nodes {
+				node.core = NewIBFT(
+					mockLogger{},
+					&mockBackend{
+						isValidProposalFn:     isValidProposal,
+						isValidProposalHashFn: isValidProposalHash,
+						isProposerFn:          c.isProposer,
+
+						idFn: node.addr,
+
+						buildProposalFn:           buildValidEthereumBlock,
+						buildPrePrepareMessageFn:  node.buildPrePrepare,
+						buildPrepareMessageFn:     node.buildPrepare,
+						buildCommitMessageFn:      node.buildCommit,
+						buildRoundChangeMessageFn: node.buildRoundChange,
+
+						getVotingPowerFn: testCommonGetVotingPowertFnForNodes(c.nodes),
+					},
+					&mockTransport{multicastFn: func(message *proto.Message) {
+						c.gossip(message)
+						observer.AddMessage(message)
+					}},
+				)
+			}
+
+			observer = newObserver(t, c, signed, inserted)
+		},
+	)
+
+	// The observer shares the clock of the validators, so none of them times out
+	c.useFakeClock()
+	observer.config.clock = c.clock
+
+	return c, observer
+}
+
+func TestIBFT_Observer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		signed   atomic.Int64
+		inserted = make(chan []byte, 1)
+	)
+
+	c, observer := newObservedCluster(t, 4, &signed, inserted)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	observerDone := make(chan error, 1)
+
+	go func() {
+		_, err := observer.RunSequence(ctx, 1)
+
+		observerDone <- err
+	}()
+
+	require.NoError(t, c.progressToHeight(10*time.Second, 1))
+
+	// Make sure the observer finalized the proposal of the validators
+	select {
+	case err := <-observerDone:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("observer did not finalize the proposal")
+	}
+
+	assert.Equal(t, validEthereumBlock, <-inserted)
+
+	// Make sure the observer never built or sent a message
+	assert.Zero(t, signed.Load())
+}
+
+func TestIBFT_Observer_NeverProposes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		signed   atomic.Int64
+		inserted = make(chan []byte, 1)
+	)
+
+	// The observer is the proposer for all views
+	c := newCluster(1, func(c *cluster) {
+		c.nodes[0].address = []byte("observer")
+	})
+	observer := newObserver(t, c, &signed, inserted)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelFn()
+
+	_, err := observer.RunSequence(ctx, 1)
+	assert.ErrorIs(t, err, ErrSequenceCancelled)
+
+	assert.Zero(t, signed.Load())
+	assert.Empty(t, inserted)
+}
+
+func TestIBFT_Observer_Configure(t *testing.T) {
+	t.Parallel()
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+	assert.False(t, i.IsObserver())
+
+	require.NoError(t, i.Configure(WithObserverMode(true)))
+	assert.True(t, i.IsObserver())
+}
@@ -0,0 +1,440 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+)
+
+var (
+	// ErrInvalidOption is returned when an option has an invalid value
+	ErrInvalidOption = errors.New("invalid option")
+
+	// ErrSequenceRunning is returned when configuring an IBFT instance that is running a sequence
+	ErrSequenceRunning = errors.New("sequence is running")
+)
+
+// config holds the tunables of the IBFT state machine
+type config struct {
+	// baseRoundTimeout is the base round timeout for each round of consensus
+	baseRoundTimeout time.Duration
+
+	//	User configured additional timeout for each round of consensus
+	additionalTimeout time.Duration
+
+	// longRoundThreshold is the round above which rounds are considered
+	// too long, so additional sync mechanisms are applied
+	longRoundThreshold uint64
+
+	// periodicTaskInterval is the interval on which messages are
+	// re-sent, or requested, in rounds above the long round threshold
+	periodicTaskInterval time.Duration
+
+	// commitGracePeriod is the time waited after the commit quorum
+	// is reached, so more commit messages can be collected
+	commitGracePeriod time.Duration
+
+	// sealCollectionTarget is the voting power percentage of the
+	// commit messages which ends the commit grace period early
+	sealCollectionTarget uint64
+
+	// observer is set if the instance follows the consensus
+	// without proposing, voting or sending any messages
+	observer bool
+
+	// roundTimeoutPolicy determines the round timeouts.
+	// If not set, the exponential timeout is derived from
+	// the base and additional timeouts
+	roundTimeoutPolicy RoundTimeoutPolicy
+
+	// clock is the source of time for the timers
+	clock Clock
+
+	// metricsSink is the sink the measurements are emitted to.
+	// If not set, the global metrics sink is used
+	metricsSink metrics.MetricSink
+}
+
+// roundTimeout returns the timeout for the specified round
+func (c config) roundTimeout(round uint64) time.Duration {
+	if c.roundTimeoutPolicy != nil {
+		return c.roundTimeoutPolicy.RoundTimeout(round)
+	}
+
+	return getRoundTimeout(c.baseRoundTimeout, c.additionalTimeout, c.longRoundThreshold, round)
+}
+
+// defaultConfig returns the configuration used by NewIBFT
+func defaultConfig() config {
+	return config{
+		baseRoundTimeout:     DefaultBaseRoundTimeout,
+		longRoundThreshold:   DefaultLongRoundThreshold,
+		periodicTaskInterval: DefaultPeriodicTaskInterval,
+		commitGracePeriod:    DefaultCommitGracePeriod,
+		sealCollectionTarget: DefaultSealCollectionTarget,
+		clock:                realClock{},
+	}
+}
+
+// options are the settings an IBFT instance is built with
+type options struct {
+	config
+
+	// messages is the message storage layer, if set
+	messages Messages
+
+	// wal is the write-ahead log, if set
+	wal WAL
+
+	// validatorSets is the epoch-aware source of the validator sets, if set
+	validatorSets *ValidatorSets
+
+	// ingressQueueSize and ingressWorkers configure
+	// the ingress pipeline, if it is enabled
+	ingressQueueSize int
+	ingressWorkers   int
+
+	// traceRecorder is the recorder the instance is traced into, if set
+	traceRecorder TraceRecorder
+}
+
+// Option configures an IBFT instance
+type Option func(o *options) error
+
+// WithBaseRoundTimeout sets the base (round 0) timeout
+func WithBaseRoundTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: base round timeout must be positive", ErrInvalidOption)
+		}
+
+		o.baseRoundTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithAdditionalTimeout sets the amount each round's timer is extended by
+func WithAdditionalTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout < 0 {
+			return fmt.Errorf("%w: additional timeout must not be negative", ErrInvalidOption)
+		}
+
+		o.additionalTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithLongRoundThreshold sets the round above which rounds are considered too long,
+// so round change and proposal messages are periodically re-sent. The proposal is requested
+// from the proposer instead, if the transport can reach the peers directly
+func WithLongRoundThreshold(round uint64) Option {
+	return func(o *options) error {
+		o.longRoundThreshold = round
+
+		return nil
+	}
+}
+
+// WithPeriodicTaskInterval sets the interval on which messages
+// are re-sent, or requested, in rounds above the long round threshold
+func WithPeriodicTaskInterval(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 {
+			return fmt.Errorf("%w: periodic task interval must be positive", ErrInvalidOption)
+		}
+
+		o.periodicTaskInterval = interval
+
+		return nil
+	}
+}
+
+// WithCommitGracePeriod sets the time waited after the commit quorum
+// is reached, so more commit messages can be collected
+func WithCommitGracePeriod(period time.Duration) Option {
+	return func(o *options) error {
+		if period < 0 {
+			return fmt.Errorf("%w: commit grace period must not be negative", ErrInvalidOption)
+		}
+
+		o.commitGracePeriod = period
+
+		return nil
+	}
+}
+
+// WithSealCollectionTarget sets the voting power percentage of the commit messages
+// which ends the commit grace period early. For instance, 100 means all the validators
+func WithSealCollectionTarget(percentage uint64) Option {
+	return func(o *options) error {
+		if percentage == 0 || percentage > 100 {
+			return fmt.Errorf("%w: seal collection target must be between 1 and 100", ErrInvalidOption)
+		}
+
+		o.sealCollectionTarget = percentage
+
+		return nil
+	}
+}
+
+// WithObserverMode sets if the instance runs as an observer. An observer runs the state machine
+// passively: it validates the messages of the validators, and inserts the proposal once the commit
+// quorum is observed, but it never signs or sends any messages. Observers need not be validators
+func WithObserverMode(observer bool) Option {
+	return func(o *options) error {
+		o.observer = observer
+
+		return nil
+	}
+}
+
+// WithRoundTimeoutPolicy sets the policy which determines the round timeouts,
+// in which case the base and additional timeouts are not used.
+// The policy is validated if it implements the RoundTimeoutValidator
+func WithRoundTimeoutPolicy(policy RoundTimeoutPolicy) Option {
+	return func(o *options) error {
+		if policy == nil {
+			return fmt.Errorf("%w: round timeout policy must be set", ErrInvalidOption)
+		}
+
+		if validator, ok := policy.(RoundTimeoutValidator); ok {
+			if err := validator.Validate(); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+			}
+		}
+
+		o.roundTimeoutPolicy = policy
+
+		return nil
+	}
+}
+
+// WithClock sets the source of time for the timers
+func WithClock(clock Clock) Option {
+	return func(o *options) error {
+		if clock == nil {
+			return fmt.Errorf("%w: clock must be set", ErrInvalidOption)
+		}
+
+		o.clock = clock
+
+		return nil
+	}
+}
+
+// WithMetricsSink sets the sink the measurements are emitted to,
+// instead of the global one
+func WithMetricsSink(sink metrics.MetricSink) Option {
+	return func(o *options) error {
+		if sink == nil {
+			return fmt.Errorf("%w: metrics sink must be set", ErrInvalidOption)
+		}
+
+		o.metricsSink = sink
+
+		return nil
+	}
+}
+
+// WithMessages sets the message storage layer.
+// It can only be set when the instance is created
+func WithMessages(messages Messages) Option {
+	return func(o *options) error {
+		if messages == nil {
+			return fmt.Errorf("%w: messages must be set", ErrInvalidOption)
+		}
+
+		o.messages = messages
+
+		return nil
+	}
+}
+
+// WithWAL sets the write-ahead log used for persisting the state transitions
+func WithWAL(wal WAL) Option {
+	return func(o *options) error {
+		if wal == nil {
+			return fmt.Errorf("%w: wal must be set", ErrInvalidOption)
+		}
+
+		o.wal = wal
+
+		return nil
+	}
+}
+
+// WithValidatorSets sets the epoch-aware source of the validator sets, which is used
+// instead of querying the backend for each sequence, and for validating the messages
+// of the future heights. It can only be set when the instance is created
+func WithValidatorSets(sets *ValidatorSets) Option {
+	return func(o *options) error {
+		if sets == nil {
+			return fmt.Errorf("%w: validator sets must be set", ErrInvalidOption)
+		}
+
+		o.validatorSets = sets
+
+		return nil
+	}
+}
+
+// WithIngress enables the ingress pipeline of SubmitMessage, with the queue of the specified
+// size, and the number of workers verifying the messages in parallel. If the transport
+// implements the BackpressureHandler, it is notified when the queue is saturated.
+// It can only be set when the instance is created
+func WithIngress(queueSize, workers int) Option {
+	return func(o *options) error {
+		if queueSize <= 0 || workers <= 0 {
+			return fmt.Errorf("%w: ingress queue size and workers must be positive", ErrInvalidOption)
+		}
+
+		o.ingressQueueSize = queueSize
+		o.ingressWorkers = workers
+
+		return nil
+	}
+}
+
+// WithTraceRecorder sets the recorder the inputs and the outputs of the instance are traced into:
+// the added and the multicasted messages, the timers and the backend calls.
+// It can only be set when the instance is created
+func WithTraceRecorder(recorder TraceRecorder) Option {
+	return func(o *options) error {
+		if recorder == nil {
+			return fmt.Errorf("%w: trace recorder must be set", ErrInvalidOption)
+		}
+
+		o.traceRecorder = recorder
+
+		return nil
+	}
+}
+
+// apply applies the options in order, and returns the first error, if any
+func (o *options) apply(opts []Option) error {
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewIBFTWithOptions creates a new instance of the IBFT consensus protocol,
+// configured with the passed in options. The options not passed in are set to their defaults
+func NewIBFTWithOptions(
+	log Logger,
+	backend Backend,
+	transport Transport,
+	opts ...Option,
+) (*IBFT, error) {
+	o := options{config: defaultConfig()}
+	if err := o.apply(opts); err != nil {
+		return nil, err
+	}
+
+	backpressure, _ := transport.(BackpressureHandler)
+
+	var t *tracer
+
+	if o.traceRecorder != nil {
+		t = newTracer(o.traceRecorder, o.clock, log)
+
+		backend = &tracingBackend{Backend: backend, tracer: t}
+		transport = &tracingTransport{Transport: transport, tracer: t}
+		o.clock = &tracingClock{Clock: o.clock, tracer: t}
+	}
+
+	i := NewIBFT(log, backend, transport)
+	i.config = o.config
+	i.wal = o.wal
+	i.tracer = t
+
+	if o.messages != nil {
+		i.messages = o.messages
+	}
+
+	if o.validatorSets != nil {
+		i.validatorSets = o.validatorSets
+		i.validatorManager = NewValidatorManager(o.validatorSets, log)
+	}
+
+	if o.ingressQueueSize > 0 {
+		i.ingress = newIngress(o.ingressQueueSize, o.ingressWorkers, i.AddMessage, backpressure)
+	}
+
+	return i, nil
+}
+
+// Configure applies the options to the IBFT instance. The options are applied atomically,
+// in between sequences: ErrSequenceRunning is returned if a sequence is running.
+// The message storage layer cannot be changed once the instance is created
+func (i *IBFT) Configure(opts ...Option) error {
+	// Holding the lock prevents new sequences from starting
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	if i.lifecycle.state == closed {
+		return ErrClosed
+	}
+
+	if len(i.lifecycle.cancelFns) > 0 {
+		return ErrSequenceRunning
+	}
+
+	o := options{
+		config: i.getConfig(),
+		wal:    i.wal,
+	}
+	if err := o.apply(opts); err != nil {
+		return err
+	}
+
+	if o.messages != nil {
+		return fmt.Errorf("%w: messages can only be set when the instance is created", ErrInvalidOption)
+	}
+
+	if o.validatorSets != nil {
+		return fmt.Errorf("%w: validator sets can only be set when the instance is created", ErrInvalidOption)
+	}
+
+	if o.ingressQueueSize > 0 {
+		return fmt.Errorf("%w: ingress can only be set when the instance is created", ErrInvalidOption)
+	}
+
+	if o.traceRecorder != nil {
+		return fmt.Errorf("%w: trace recorder can only be set when the instance is created", ErrInvalidOption)
+	}
+
+	// Keep tracing the timers of a replaced clock
+	if _, traced := o.clock.(*tracingClock); i.tracer != nil && !traced {
+		o.clock = &tracingClock{Clock: o.clock, tracer: i.tracer}
+	}
+
+	i.configLock.Lock()
+	i.config = o.config
+	i.configLock.Unlock()
+
+	i.wal = o.wal
+
+	return nil
+}
+
+// getConfig returns a copy of the current configuration
+func (i *IBFT) getConfig() config {
+	i.configLock.RLock()
+	defer i.configLock.RUnlock()
+
+	return i.config
+}
+
+// IsObserver returns true if the instance runs as an observer
+func (i *IBFT) IsObserver() bool {
+	return i.getConfig().observer
+}
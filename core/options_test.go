@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+)
+
+func TestNewIBFTWithOptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		store = messages.NewMessages()
+		wal   = &mockWAL{}
+		sink  = metrics.NewInmemSink(time.Minute, time.Minute)
+	)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{},
+		mockTransport{},
+		WithBaseRoundTimeout(time.Second),
+		WithAdditionalTimeout(2*time.Second),
+		WithLongRoundThreshold(3),
+		WithPeriodicTaskInterval(time.Minute),
+		WithCommitGracePeriod(0),
+		WithSealCollectionTarget(50),
+		WithClock(realClock{}),
+		WithMetricsSink(sink),
+		WithMessages(store),
+		WithWAL(wal),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, config{
+		baseRoundTimeout:     time.Second,
+		additionalTimeout:    2 * time.Second,
+		longRoundThreshold:   3,
+		periodicTaskInterval: time.Minute,
+		commitGracePeriod:    0,
+		sealCollectionTarget: 50,
+		clock:                realClock{},
+		metricsSink:          sink,
+	}, i.config)
+	assert.Equal(t, store, i.messages)
+	assert.Equal(t, wal, i.wal)
+}
+
+func TestNewIBFTWithOptions_Defaults(t *testing.T) {
+	t.Parallel()
+
+	i, err := NewIBFTWithOptions(mockLogger{}, mockBackend{}, mockTransport{})
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultConfig(), i.config)
+	assert.NotNil(t, i.messages)
+	assert.Nil(t, i.wal)
+}
+
+func TestNewIBFTWithOptions_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option Option
+	}{
+		{"zero base round timeout", WithBaseRoundTimeout(0)},
+		{"negative additional timeout", WithAdditionalTimeout(-time.Second)},
+		{"zero periodic task interval", WithPeriodicTaskInterval(0)},
+		{"negative commit grace period", WithCommitGracePeriod(-time.Second)},
+		{"zero seal collection target", WithSealCollectionTarget(0)},
+		{"seal collection target above 100", WithSealCollectionTarget(101)},
+		{"missing round timeout policy", WithRoundTimeoutPolicy(nil)},
+		{"missing clock", WithClock(nil)},
+		{"missing metrics sink", WithMetricsSink(nil)},
+		{"missing messages", WithMessages(nil)},
+		{"missing wal", WithWAL(nil)},
+		{"missing validator sets", WithValidatorSets(nil)},
+		{"empty ingress queue", WithIngress(0, 1)},
+		{"no ingress workers", WithIngress(1, 0)},
+		{"missing trace recorder", WithTraceRecorder(nil)},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			i, err := NewIBFTWithOptions(mockLogger{}, mockBackend{}, mockTransport{}, test.option)
+
+			assert.ErrorIs(t, err, ErrInvalidOption)
+			assert.Nil(t, i)
+		})
+	}
+}
+
+func TestIBFT_Configure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applied in between sequences", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+
+		sink := metrics.NewInmemSink(time.Minute, time.Minute)
+		i := newSingleNodeIBFT(nil)
+
+		require.NoError(t, i.Configure(
+			WithMetricsSink(sink),
+			WithCommitGracePeriod(0),
+		))
+
+		_, err := i.RunSequence(ctx, 1)
+		require.NoError(t, err)
+
+		// Make sure the measurements are emitted to the configured sink
+		data := sink.Data()
+		require.Len(t, data, 1)
+		assert.Contains(t, data[0].Gauges, "go-ibft.sequence.duration")
+	})
+
+	t.Run("sequence running", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		_, release, err := i.enterSequence(context.Background())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, i.Configure(WithBaseRoundTimeout(time.Second)), ErrSequenceRunning)
+		assert.Equal(t, DefaultBaseRoundTimeout, i.config.baseRoundTimeout)
+
+		release()
+
+		assert.NoError(t, i.Configure(WithBaseRoundTimeout(time.Second)))
+		assert.Equal(t, time.Second, i.config.baseRoundTimeout)
+	})
+
+	t.Run("invalid options are not applied", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		err := i.Configure(
+			WithBaseRoundTimeout(time.Second),
+			WithPeriodicTaskInterval(0),
+		)
+
+		assert.ErrorIs(t, err, ErrInvalidOption)
+		assert.Equal(t, defaultConfig(), i.config)
+	})
+
+	t.Run("messages cannot be changed", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		assert.ErrorIs(t, i.Configure(WithMessages(messages.NewMessages())), ErrInvalidOption)
+	})
+
+	t.Run("validator sets cannot be changed", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+		sets, err := NewValidatorSets(mockBackend{}, 10, 100)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, i.Configure(WithValidatorSets(sets)), ErrInvalidOption)
+		assert.Nil(t, i.validatorSets)
+	})
+
+	t.Run("ingress cannot be enabled", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		assert.ErrorIs(t, i.Configure(WithIngress(1, 1)), ErrInvalidOption)
+		assert.Nil(t, i.ingress)
+	})
+
+	t.Run("trace recorder cannot be set", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		assert.ErrorIs(t, i.Configure(WithTraceRecorder(NewMemoryTraceRecorder())), ErrInvalidOption)
+		assert.Nil(t, i.tracer)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+		require.NoError(t, i.Close())
+
+		assert.ErrorIs(t, i.Configure(WithBaseRoundTimeout(time.Second)), ErrClosed)
+	})
+}
@@ -307,8 +307,10 @@ func TestProperty(t *testing.T) {
 			}
 
 			// Make sure the inserted proposal is noted
-			backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+			backend.insertProposalFn = func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
 				insertedProposals.insertProposal(nodeIndex, proposal.RawProposal)
+
+				return nil
 			}
 
 			// Make sure the proposal can be built
@@ -331,16 +333,25 @@ func TestProperty(t *testing.T) {
 		// to the entire cluster
 		multicastFn = cluster.pushMessage
 
+		// Make sure the round timeouts expire only when the rounds fail
+		cluster.useFakeClock()
+
 		// Run the sequence up until a certain height
 		for height := uint64(0); height < setup.desiredHeight; height++ {
 			// Create context timeout based on the bad nodes number
 			rounds := uint64(len(setup.events[height]))
-			ctxTimeout := getRoundTimeout(testRoundTimeout, testRoundTimeout, rounds*2)
+			ctxTimeout := getRoundTimeout(testRoundTimeout, testRoundTimeout, DefaultLongRoundThreshold, rounds*2)
 
 			// Start the main run loops
 			cluster.runSequence(height)
 
 			ctx, cancelFn := context.WithTimeout(context.Background(), ctxTimeout)
+
+			// Expire the rounds of the byzantine proposers, all but the last one fail
+			for round := uint64(0); round+1 < rounds; round++ {
+				assert.True(t, cluster.expireRound(ctx, round), "round %d not started on height %d", round, height)
+			}
+
 			err := cluster.awaitNCompletions(ctx, int64(quorum(setup.nodes)))
 			assert.NoError(t, err, "unable to wait for nodes to complete on height %d", height)
 			cancelFn()
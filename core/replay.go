@@ -0,0 +1,581 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// replayStepTimeout is the time the replayed instance is given
+// to reproduce each traced output, before the replay is considered diverged
+const replayStepTimeout = 5 * time.Second
+
+// ErrReplayDiverged is returned when the replayed instance doesn't reproduce the trace
+var ErrReplayDiverged = errors.New("replay diverged from the trace")
+
+// ReplayResult is the outcome of a replayed trace
+type ReplayResult struct {
+	// Replayed is the number of the trace entries replayed
+	Replayed int
+
+	// Sequences are the results of the replayed sequences, in the order they were started.
+	// The result is nil if the sequence didn't finish
+	Sequences []*SequenceResult
+
+	// Trace is the trace of the replayed instance
+	Trace []*proto.TraceEntry
+}
+
+// Replay feeds the trace into a fresh IBFT instance, driven by a fake clock, and reproduces the state
+// transitions of the traced instance. The backend calls are answered with the traced results,
+// so the backend of the traced instance is not needed, and the multicasted messages are dropped.
+// The options of the traced instance should be passed in, so the timers match.
+// ErrReplayDiverged is returned, along with the partial result, when the replayed instance
+// doesn't reproduce a traced output, or calls the backend with untraced arguments
+func Replay(ctx context.Context, log Logger, trace []*proto.TraceEntry, opts ...Option) (*ReplayResult, error) {
+	var (
+		clock    = newReplayClock()
+		backend  = newReplayBackend(trace)
+		recorder = NewMemoryTraceRecorder()
+	)
+
+	opts = append(opts[:len(opts):len(opts)], WithClock(clock), WithTraceRecorder(recorder))
+
+	node, err := NewIBFTWithOptions(log, backend, replayTransport{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &replayer{
+		node:     node,
+		clock:    clock,
+		started:  clock.Now(),
+		backend:  backend,
+		recorder: recorder,
+	}
+
+	err = r.replay(ctx, trace)
+
+	r.stopSequence()
+	_ = node.Close()
+
+	return &ReplayResult{
+		Replayed:  r.replayed,
+		Sequences: r.sequences,
+		Trace:     recorder.Entries(),
+	}, err
+}
+
+// replayer drives the replayed instance through the trace
+type replayer struct {
+	node     *IBFT
+	clock    *replayClock
+	started  time.Time
+	backend  *replayBackend
+	recorder *MemoryTraceRecorder
+
+	// replayed is the number of the trace entries replayed
+	replayed int
+
+	// seen is the number of the replayed entries already looked at,
+	// and pending are the ones not matched with the traced outputs yet
+	seen    int
+	pending []*proto.TraceEntry
+
+	// sequences are the results of the started sequences.
+	// The currently running sequence is cancelled with the cancelFn,
+	// and the done channel is closed once it returns
+	sequences []*SequenceResult
+	cancelFn  context.CancelFunc
+	done      chan struct{}
+}
+
+// replay replays the trace entries in order. The traced inputs are fed into the replayed instance,
+// and the traced outputs are awaited, with the fake clock set to the offset of each entry
+func (r *replayer) replay(ctx context.Context, trace []*proto.TraceEntry) error {
+	for index, entry := range trace {
+		r.advance(time.Duration(entry.Offset))
+
+		switch entry.Type {
+		case proto.TraceEntry_SEQUENCE:
+			r.startSequence(ctx, entry.Height)
+		case proto.TraceEntry_SEQUENCE_DONE:
+			if err := r.awaitSequence(ctx, entry.Height); err != nil {
+				return fmt.Errorf("entry %d: %w", index, err)
+			}
+		case proto.TraceEntry_RECEIVED:
+			r.node.AddMessage(entry.Message)
+		case proto.TraceEntry_TIMER_EXPIRED:
+			if err := r.expire(ctx, entry); err != nil {
+				return fmt.Errorf("entry %d: %w", index, err)
+			}
+		case proto.TraceEntry_SENT, proto.TraceEntry_TIMER_STARTED:
+			if err := r.expect(ctx, entry); err != nil {
+				return fmt.Errorf("entry %d: %w", index, err)
+			}
+		case proto.TraceEntry_BACKEND:
+			// The backend calls are answered by the replay backend
+		}
+
+		if err := r.backend.getErr(); err != nil {
+			return fmt.Errorf("entry %d: %w", index, err)
+		}
+
+		r.replayed++
+	}
+
+	return nil
+}
+
+// advance moves the fake clock forward to the offset from the start of the trace
+func (r *replayer) advance(offset time.Duration) {
+	if d := r.started.Add(offset).Sub(r.clock.Now()); d > 0 {
+		r.clock.Advance(d)
+	}
+}
+
+// startSequence starts running the sequence for the height. The previous sequence,
+// which didn't return in the trace, is cancelled, like it was by the caller of the traced instance
+func (r *replayer) startSequence(ctx context.Context, height uint64) {
+	r.stopSequence()
+
+	var (
+		index            = len(r.sequences)
+		done             = make(chan struct{})
+		sequenceCtx, cfn = context.WithCancel(ctx)
+	)
+
+	r.sequences = append(r.sequences, nil)
+	r.cancelFn, r.done = cfn, done
+
+	go func() {
+		defer close(done)
+
+		result, err := r.node.RunSequence(sequenceCtx, height)
+		if err == nil {
+			r.sequences[index] = result
+		}
+	}()
+}
+
+// awaitSequence waits for the running sequence to return, like it did in the trace
+func (r *replayer) awaitSequence(ctx context.Context, height uint64) error {
+	if r.done == nil {
+		return fmt.Errorf("%w: sequence for height %d not started", ErrReplayDiverged, height)
+	}
+
+	timer := time.NewTimer(replayStepTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.done:
+		r.cancelFn()
+		r.cancelFn, r.done = nil, nil
+
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: sequence for height %d not returned", ErrReplayDiverged, height)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopSequence cancels the running sequence, if any, and waits for it to return
+func (r *replayer) stopSequence() {
+	if r.done == nil {
+		return
+	}
+
+	r.cancelFn()
+	<-r.done
+
+	r.cancelFn, r.done = nil, nil
+}
+
+// expire expires the replayed timer matching the traced one, once it is started,
+// and waits for the replayed instance to observe the expiration
+func (r *replayer) expire(ctx context.Context, expected *proto.TraceEntry) error {
+	timer := time.NewTimer(replayStepTimeout)
+	defer timer.Stop()
+
+	for {
+		expired, changed := r.clock.expire(time.Duration(expected.Duration))
+		if expired {
+			return r.expect(ctx, expected)
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return fmt.Errorf("%w: %s not reproduced", ErrReplayDiverged, describeTraceEntry(expected))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// expect waits for the replayed instance to reproduce the traced output.
+// The outputs of the concurrent routines can be reproduced out of order
+func (r *replayer) expect(ctx context.Context, expected *proto.TraceEntry) error {
+	timer := time.NewTimer(replayStepTimeout)
+	defer timer.Stop()
+
+	for {
+		entries, changed := r.recorder.entriesSince(r.seen)
+		r.seen += len(entries)
+
+		for _, entry := range entries {
+			if entry.Type != proto.TraceEntry_BACKEND {
+				r.pending = append(r.pending, entry)
+			}
+		}
+
+		for index, entry := range r.pending {
+			if isSameOutput(expected, entry) {
+				r.pending = append(r.pending[:index], r.pending[index+1:]...)
+
+				return nil
+			}
+		}
+
+		if err := r.backend.getErr(); err != nil {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return fmt.Errorf("%w: %s not reproduced", ErrReplayDiverged, describeTraceEntry(expected))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isSameOutput checks if the replayed entry reproduces the traced output
+func isSameOutput(expected, replayed *proto.TraceEntry) bool {
+	if expected.Type != replayed.Type {
+		return false
+	}
+
+	if expected.Type == proto.TraceEntry_SENT {
+		return protobuf.Equal(expected.Message, replayed.Message)
+	}
+
+	return expected.Duration == replayed.Duration
+}
+
+// describeTraceEntry returns the description of the traced output
+func describeTraceEntry(entry *proto.TraceEntry) string {
+	if entry.Type == proto.TraceEntry_SENT {
+		return fmt.Sprintf(
+			"%s %s message for height %d, round %d",
+			entry.Type, entry.Message.GetType(), entry.Message.GetView().GetHeight(), entry.Message.GetView().GetRound(),
+		)
+	}
+
+	return fmt.Sprintf("%s timer of %s", entry.Type, time.Duration(entry.Duration))
+}
+
+// replayClock is the clock of the replayed instance. Its time is moved forward to the offsets
+// of the trace entries, but its timers expire only when the traced timers did.
+// This way the timers started together expire in the traced order
+type replayClock struct {
+	*FakeClock
+
+	lock sync.Mutex
+
+	// timers are the active timers, in the order they were started
+	timers []*replayTimer
+
+	// timersChanged is closed, and replaced, when a new timer is started
+	timersChanged chan struct{}
+}
+
+// newReplayClock creates a new replay clock, starting at the Unix epoch
+func newReplayClock() *replayClock {
+	return &replayClock{
+		FakeClock:     NewFakeClock(time.Unix(0, 0)),
+		timersChanged: make(chan struct{}),
+	}
+}
+
+// NewTimer creates a timer which expires only when the replayer expires it
+func (c *replayClock) NewTimer(d time.Duration) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &replayTimer{
+		clock:    c,
+		duration: d,
+		ch:       make(chan time.Time, 1),
+	}
+
+	c.timers = append(c.timers, t)
+
+	close(c.timersChanged)
+	c.timersChanged = make(chan struct{})
+
+	return t
+}
+
+// expire expires the earliest started active timer with the duration.
+// If there is none, the channel closed once a new timer is started is returned
+func (c *replayClock) expire(d time.Duration) (bool, <-chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for index, t := range c.timers {
+		if t.duration == d {
+			c.timers = append(c.timers[:index], c.timers[index+1:]...)
+			t.ch <- c.Now()
+
+			return true, nil
+		}
+	}
+
+	return false, c.timersChanged
+}
+
+// stop removes the timer from the active timers, and returns false if it was not active
+func (c *replayClock) stop(t *replayTimer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for index, active := range c.timers {
+		if active == t {
+			c.timers = append(c.timers[:index], c.timers[index+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// replayTimer is the timer of the replay clock
+type replayTimer struct {
+	clock    *replayClock
+	duration time.Duration
+	ch       chan time.Time
+}
+
+// C returns the channel the expiration time is delivered on
+func (t *replayTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop prevents the timer from expiring
+func (t *replayTimer) Stop() bool {
+	return t.clock.stop(t)
+}
+
+// replayTransport drops the messages multicasted by the replayed instance
+type replayTransport struct{}
+
+// Multicast drops the message
+func (replayTransport) Multicast(_ *proto.Message) {}
+
+// replayBackend answers the backend calls with the traced results
+type replayBackend struct {
+	lock sync.Mutex
+
+	// calls are the traced calls, by the method and the arguments.
+	// The calls with the same arguments are answered in the traced order,
+	// and the last one is repeated once they are exhausted
+	calls map[string][]*proto.BackendCall
+	next  map[string]int
+
+	// err is set once an untraced call is made
+	err error
+}
+
+var _ Backend = &replayBackend{}
+
+// newReplayBackend creates the backend answering with the calls of the trace
+func newReplayBackend(trace []*proto.TraceEntry) *replayBackend {
+	b := &replayBackend{
+		calls: make(map[string][]*proto.BackendCall),
+		next:  make(map[string]int),
+	}
+
+	for _, entry := range trace {
+		if entry.Type != proto.TraceEntry_BACKEND || entry.Call == nil {
+			continue
+		}
+
+		key := callKey(entry.Call.Method, entry.Call.Arguments)
+		b.calls[key] = append(b.calls[key], entry.Call)
+	}
+
+	return b
+}
+
+// callKey returns the key of the call, made of the method and the deterministically encoded arguments
+func callKey(method string, arguments *proto.BackendArguments) string {
+	raw, _ := protobuf.MarshalOptions{Deterministic: true}.Marshal(arguments)
+
+	return method + "/" + string(raw)
+}
+
+// call returns the traced result of the call. If the call was not traced,
+// the error is set, and the empty result is returned
+func (b *replayBackend) call(method string, arguments *proto.BackendArguments) *proto.BackendCall {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := callKey(method, arguments)
+
+	calls := b.calls[key]
+	if len(calls) == 0 {
+		if b.err == nil {
+			b.err = fmt.Errorf("%w: untraced %s call", ErrReplayDiverged, method)
+		}
+
+		return &proto.BackendCall{}
+	}
+
+	index := b.next[key]
+	if index < len(calls)-1 {
+		b.next[key]++
+	}
+
+	return calls[index]
+}
+
+// getErr returns the error of the first untraced call, if any
+func (b *replayBackend) getErr() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.err
+}
+
+// ID returns the traced ID of the node
+func (b *replayBackend) ID() []byte {
+	return b.call(methodID, &proto.BackendArguments{}).Data
+}
+
+// GetVotingPowers returns the traced voting powers of the validators at the height
+func (b *replayBackend) GetVotingPowers(height uint64) (map[string]*big.Int, error) {
+	call := b.call(methodGetVotingPowers, &proto.BackendArguments{Height: height})
+
+	votingPowers := make(map[string]*big.Int, len(call.VotingPowers))
+	for address, votingPower := range call.VotingPowers {
+		votingPowers[address] = new(big.Int).SetBytes(votingPower)
+	}
+
+	return votingPowers, traceError(call.Error)
+}
+
+// StartRound returns the traced result of the round start
+func (b *replayBackend) StartRound(view *proto.View) error {
+	return traceError(b.call(methodStartRound, &proto.BackendArguments{View: view}).Error)
+}
+
+// BuildProposal returns the traced proposal built for the view
+func (b *replayBackend) BuildProposal(view *proto.View) []byte {
+	return b.call(methodBuildProposal, &proto.BackendArguments{View: view}).Data
+}
+
+// InsertProposal returns the traced result of the proposal insertion
+func (b *replayBackend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
+	call := b.call(methodInsertProposal, &proto.BackendArguments{
+		Proposal:       proposal,
+		CommittedSeals: toProtoCommittedSeals(committedSeals),
+	})
+
+	return traceError(call.Error)
+}
+
+// ReportEquivocation checks if the equivocation was traced
+func (b *replayBackend) ReportEquivocation(evidence *messages.Evidence) {
+	b.call(methodReportEquivocation, &proto.BackendArguments{
+		Messages: []*proto.Message{evidence.First, evidence.Second},
+	})
+}
+
+// IsValidProposal returns the traced validity of the proposal
+func (b *replayBackend) IsValidProposal(rawProposal []byte) bool {
+	return b.call(methodIsValidProposal, &proto.BackendArguments{RawProposal: rawProposal}).Valid
+}
+
+// IsValidValidator returns the traced validity of the message sender
+func (b *replayBackend) IsValidValidator(msg *proto.Message) bool {
+	return b.call(methodIsValidValidator, &proto.BackendArguments{Message: msg}).Valid
+}
+
+// IsProposer returns if the ID was traced as the proposer for the view
+func (b *replayBackend) IsProposer(id []byte, height, round uint64) bool {
+	return b.call(methodIsProposer, &proto.BackendArguments{
+		Id:     id,
+		Height: height,
+		Round:  round,
+	}).Valid
+}
+
+// IsValidProposalHash returns if the hash was traced as matching the proposal
+func (b *replayBackend) IsValidProposalHash(proposal *proto.Proposal, hash []byte) bool {
+	return b.call(methodIsValidProposalHash, &proto.BackendArguments{
+		Proposal:     proposal,
+		ProposalHash: hash,
+	}).Valid
+}
+
+// IsValidCommittedSeal returns the traced validity of the committed seal
+func (b *replayBackend) IsValidCommittedSeal(proposalHash []byte, committedSeal *messages.CommittedSeal) bool {
+	return b.call(methodIsValidCommittedSeal, &proto.BackendArguments{
+		ProposalHash:   proposalHash,
+		CommittedSeals: toProtoCommittedSeals([]*messages.CommittedSeal{committedSeal}),
+	}).Valid
+}
+
+// BuildPrePrepareMessage returns the traced PREPREPARE message
+func (b *replayBackend) BuildPrePrepareMessage(
+	rawProposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	return b.call(methodBuildPrePrepareMessage, &proto.BackendArguments{
+		View:                   view,
+		RawProposal:            rawProposal,
+		RoundChangeCertificate: certificate,
+	}).Message
+}
+
+// BuildPrepareMessage returns the traced PREPARE message
+func (b *replayBackend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return b.call(methodBuildPrepareMessage, &proto.BackendArguments{
+		View:         view,
+		ProposalHash: proposalHash,
+	}).Message
+}
+
+// BuildCommitMessage returns the traced COMMIT message
+func (b *replayBackend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return b.call(methodBuildCommitMessage, &proto.BackendArguments{
+		View:         view,
+		ProposalHash: proposalHash,
+	}).Message
+}
+
+// BuildRoundChangeMessage returns the traced ROUND_CHANGE message
+func (b *replayBackend) BuildRoundChangeMessage(
+	proposal *proto.Proposal,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	return b.call(methodBuildRoundChangeMessage, &proto.BackendArguments{
+		View:                view,
+		Proposal:            proposal,
+		PreparedCertificate: certificate,
+	}).Message
+}
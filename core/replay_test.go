@@ -0,0 +1,246 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// tracedOptions are the options of the traced instance. The commit grace period is disabled,
+// so the instance finalizes the height as soon as it commits, before the round 1 timer expires
+func tracedOptions() []Option {
+	return []Option{
+		WithBaseRoundTimeout(100 * time.Millisecond),
+		WithCommitGracePeriod(0),
+	}
+}
+
+// newTracedIBFT creates a traced IBFT instance, which is the validator with the quorum
+// of the voting power, but not the proposer in round 0. The instance finalizes the height
+// in round 1, once the round 0 timer expires
+func newTracedIBFT(t *testing.T, recorder TraceRecorder) *IBFT {
+	t.Helper()
+
+	var (
+		i      *IBFT
+		node   = []byte("node 0")
+		absent = []byte("node 1")
+	)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return node
+			},
+			getVotingPowerFn: func(_ uint64) (map[string]*big.Int, error) {
+				return map[string]*big.Int{
+					string(node):   big.NewInt(10),
+					string(absent): big.NewInt(1),
+				}, nil
+			},
+			isProposerFn: func(from []byte, _ uint64, round uint64) bool {
+				if round == 0 {
+					return bytes.Equal(from, absent)
+				}
+
+				return bytes.Equal(from, node)
+			},
+			isValidProposalHashFn: func(_ *proto.Proposal, hash []byte) bool {
+				return bytes.Equal(hash, correctRoundMessage.hash)
+			},
+			buildProposalFn: func(_ uint64) []byte {
+				return correctRoundMessage.proposal.RawProposal
+			},
+			buildPrePrepareMessageFn: func(
+				rawProposal []byte,
+				certificate *proto.RoundChangeCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return buildBasicPreprepareMessage(rawProposal, correctRoundMessage.hash, certificate, node, view)
+			},
+			buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicPrepareMessage(correctRoundMessage.hash, node, view)
+			},
+			buildCommitMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicCommitMessage(correctRoundMessage.hash, correctRoundMessage.seal, node, view)
+			},
+			buildRoundChangeMessageFn: func(
+				proposal *proto.Proposal,
+				certificate *proto.PreparedCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return buildBasicRoundChangeMessage(proposal, certificate, view, node)
+			},
+		},
+		mockTransport{
+			multicastFn: func(message *proto.Message) {
+				i.AddMessage(message)
+			},
+		},
+		append(tracedOptions(), WithTraceRecorder(recorder))...,
+	)
+	require.NoError(t, err)
+
+	return i
+}
+
+// recordTrace runs the height on the traced instance, and returns the trace read from the file
+func recordTrace(t *testing.T) ([]*proto.TraceEntry, *SequenceResult) {
+	t.Helper()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	path := filepath.Join(t.TempDir(), "trace")
+
+	recorder, err := NewFileTraceRecorder(path)
+	require.NoError(t, err)
+
+	i := newTracedIBFT(t, recorder)
+
+	result, err := i.RunSequence(ctx, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, i.Close())
+	require.NoError(t, recorder.Close())
+
+	trace, err := ReadTraceFile(path)
+	require.NoError(t, err)
+
+	return trace, result
+}
+
+// filterTrace returns the entries of the trace with the specified type
+func filterTrace(trace []*proto.TraceEntry, entryType proto.TraceEntry_Type) []*proto.TraceEntry {
+	filtered := make([]*proto.TraceEntry, 0)
+
+	for _, entry := range trace {
+		if entry.Type == entryType {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+func TestIBFT_Trace(t *testing.T) {
+	t.Parallel()
+
+	trace, result := recordTrace(t)
+
+	require.Equal(t, uint64(1), result.Round)
+
+	assert.Equal(t, proto.TraceEntry_SEQUENCE, trace[0].Type)
+	assert.Equal(t, uint64(1), trace[0].Height)
+
+	assert.Equal(t, proto.TraceEntry_SEQUENCE_DONE, trace[len(trace)-1].Type)
+	assert.Equal(t, uint64(1), trace[len(trace)-1].Height)
+
+	// Make sure the round 0 timer expiration is traced
+	expired := filterTrace(trace, proto.TraceEntry_TIMER_EXPIRED)
+	require.NotEmpty(t, expired)
+	assert.Equal(t, int64(100*time.Millisecond), expired[0].Duration)
+
+	// Make sure the looped back messages are traced as both sent and received
+	sent := filterTrace(trace, proto.TraceEntry_SENT)
+	received := filterTrace(trace, proto.TraceEntry_RECEIVED)
+
+	require.NotEmpty(t, sent)
+	assert.Len(t, received, len(sent))
+
+	// Make sure the finalized proposal is traced
+	calls := filterTrace(trace, proto.TraceEntry_BACKEND)
+	inserted := false
+
+	for _, entry := range calls {
+		if entry.Call.Method == methodInsertProposal {
+			inserted = true
+
+			assert.True(t, protobuf.Equal(result.Proposal, entry.Call.Arguments.Proposal))
+		}
+	}
+
+	assert.True(t, inserted)
+
+	// Make sure the offsets are monotonic
+	for index := 1; index < len(trace); index++ {
+		assert.GreaterOrEqual(t, trace[index].Offset, trace[index-1].Offset)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	trace, result := recordTrace(t)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	replay, err := Replay(ctx, mockLogger{}, trace, tracedOptions()...)
+	require.NoError(t, err)
+
+	assert.Equal(t, len(trace), replay.Replayed)
+
+	// Make sure the replayed sequence finalized the same proposal in the same round
+	require.Len(t, replay.Sequences, 1)
+	require.NotNil(t, replay.Sequences[0])
+
+	assert.Equal(t, result.Round, replay.Sequences[0].Round)
+	assert.True(t, protobuf.Equal(result.Proposal, replay.Sequences[0].Proposal))
+
+	// Make sure the same messages were sent, on the same fake clock offsets
+	var (
+		sent         = filterTrace(trace, proto.TraceEntry_SENT)
+		replayedSent = filterTrace(replay.Trace, proto.TraceEntry_SENT)
+	)
+
+	require.Len(t, replayedSent, len(sent))
+
+	for index, entry := range sent {
+		assert.True(t, protobuf.Equal(entry.Message, replayedSent[index].Message))
+	}
+
+	// Make sure the round 0 timer expired on the traced offset
+	var (
+		expired         = filterTrace(trace, proto.TraceEntry_TIMER_EXPIRED)
+		replayedExpired = filterTrace(replay.Trace, proto.TraceEntry_TIMER_EXPIRED)
+	)
+
+	require.NotEmpty(t, replayedExpired)
+	assert.Equal(t, expired[0].Offset, replayedExpired[0].Offset)
+}
+
+func TestReplay_Diverged(t *testing.T) {
+	t.Parallel()
+
+	trace, _ := recordTrace(t)
+
+	// Drop the traced proposers, so the replayed instance calls the backend with untraced arguments
+	diverged := make([]*proto.TraceEntry, 0, len(trace))
+
+	for _, entry := range trace {
+		if entry.Type == proto.TraceEntry_BACKEND && entry.Call.Method == methodIsProposer {
+			continue
+		}
+
+		diverged = append(diverged, entry)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	replay, err := Replay(ctx, mockLogger{}, diverged, tracedOptions()...)
+
+	assert.ErrorIs(t, err, ErrReplayDiverged)
+	assert.Less(t, replay.Replayed, len(diverged))
+}
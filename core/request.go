@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bytes"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// directTransport returns the transport as the DirectTransport, if it can reach the peers directly.
+// The tracing transport is unwrapped, since it records only the multicasted messages
+//
+//nolint:ireturn
+func (i *IBFT) directTransport() (DirectTransport, bool) {
+	transport := i.transport
+	if traced, ok := transport.(*tracingTransport); ok {
+		transport = traced.Transport
+	}
+
+	direct, ok := transport.(DirectTransport)
+
+	return direct, ok
+}
+
+// HandleMessageRequest responds to the message request of the peer, by unicasting
+// the requested messages the node has back to it. The request is ignored
+// if the transport can't reach the peers directly
+func (i *IBFT) HandleMessageRequest(from []byte, request *MessageRequest) {
+	transport, ok := i.directTransport()
+	if !ok || request == nil || request.View == nil {
+		return
+	}
+
+	for _, message := range i.requestedMessages(request) {
+		transport.Unicast(from, message)
+	}
+}
+
+// requestedMessages returns the messages of the request the node has,
+// which are the stored messages and the one the node signed itself
+func (i *IBFT) requestedMessages(request *MessageRequest) []*proto.Message {
+	var (
+		view = &proto.View{
+			Height: request.View.Height,
+			Round:  request.View.Round,
+		}
+
+		// The stored messages are already verified, so none are dropped
+		stored = i.messages.GetValidMessages(view, request.MessageType, func(_ *proto.Message) bool {
+			return true
+		})
+	)
+
+	signed := i.signedMessages.get(request.MessageType, view)
+	if signed == nil {
+		return stored
+	}
+
+	for _, message := range stored {
+		if bytes.Equal(message.From, signed.From) {
+			return stored
+		}
+	}
+
+	return append(stored, signed)
+}
+
+// subscribeMissingProposal subscribes to the PREPARE messages of the view, which reveal
+// the peers having the proposal, if the node missed the PREPREPARE. Nil is returned
+// if the transport can't request the messages from the peers
+func (i *IBFT) subscribeMissingProposal(view *proto.View) *messages.Subscription {
+	if _, ok := i.directTransport(); !ok {
+		return nil
+	}
+
+	return i.messages.Subscribe(messages.SubscriptionDetails{
+		MessageType:    proto.MessageType_PREPARE,
+		View:           view,
+		MinNumMessages: 1,
+	})
+}
+
+// requestMissingProposal requests the PREPREPARE of the view from the senders of the PREPARE messages,
+// which have the proposal the node is missing. Each peer is asked only once in the view
+func (i *IBFT) requestMissingProposal(view *proto.View, requested map[string]struct{}) {
+	transport, ok := i.directTransport()
+	if !ok {
+		return
+	}
+
+	prepareMessages := i.messages.GetValidMessages(view, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return true
+	})
+
+	for _, message := range prepareMessages {
+		if _, ok := requested[string(message.From)]; ok || bytes.Equal(message.From, i.backend.ID()) {
+			continue
+		}
+
+		requested[string(message.From)] = struct{}{}
+
+		i.log.Debug("requesting the missing proposal", "round", view.Round)
+
+		transport.RequestMessages(message.From, &MessageRequest{
+			View: &proto.View{
+				Height: view.Height,
+				Round:  view.Round,
+			},
+			MessageType: proto.MessageType_PREPREPARE,
+		})
+	}
+}
+
+// requestProposal requests the PREPREPARE of the view from its proposer,
+// which responds with the proposal message it signed
+func (i *IBFT) requestProposal(view *proto.View) {
+	transport, ok := i.directTransport()
+	if !ok {
+		return
+	}
+
+	for id := range i.validatorManager.getVotingPowers() {
+		if id == string(i.backend.ID()) || !i.backend.IsProposer([]byte(id), view.Height, view.Round) {
+			continue
+		}
+
+		i.log.Debug("requesting the proposal from the proposer", "round", view.Round)
+
+		transport.RequestMessages([]byte(id), &MessageRequest{
+			View: &proto.View{
+				Height: view.Height,
+				Round:  view.Round,
+			},
+			MessageType: proto.MessageType_PREPREPARE,
+		})
+
+		return
+	}
+}
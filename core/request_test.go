@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestIBFT_HandleMessageRequest(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes = generateNodeAddresses(4)
+		view  = &proto.View{Height: 1, Round: 0}
+
+		proposal = buildBasicPreprepareMessage(
+			correctRoundMessage.proposal.RawProposal,
+			correctRoundMessage.hash,
+			nil,
+			nodes[1],
+			view,
+		)
+		prepare = buildBasicPrepareMessage(correctRoundMessage.hash, nodes[0], view)
+	)
+
+	newIBFT := func(unicasted *[]*proto.Message) *IBFT {
+		i := NewIBFT(
+			mockLogger{},
+			mockBackend{
+				idFn: func() []byte {
+					return nodes[0]
+				},
+			},
+			mockDirectTransport{
+				unicastFn: func(to []byte, message *proto.Message) {
+					assert.Equal(t, nodes[2], to)
+
+					*unicasted = append(*unicasted, message)
+				},
+			},
+		)
+
+		i.messages.AddMessage(proposal)
+		i.signedMessages.add(prepare)
+
+		return i
+	}
+
+	t.Run("stored messages are sent back", func(t *testing.T) {
+		t.Parallel()
+
+		var unicasted []*proto.Message
+
+		newIBFT(&unicasted).HandleMessageRequest(nodes[2], &MessageRequest{
+			View:        view,
+			MessageType: proto.MessageType_PREPREPARE,
+		})
+
+		assert.Equal(t, []*proto.Message{proposal}, unicasted)
+	})
+
+	t.Run("signed messages are sent back", func(t *testing.T) {
+		t.Parallel()
+
+		var unicasted []*proto.Message
+
+		newIBFT(&unicasted).HandleMessageRequest(nodes[2], &MessageRequest{
+			View:        view,
+			MessageType: proto.MessageType_PREPARE,
+		})
+
+		assert.Equal(t, []*proto.Message{prepare}, unicasted)
+	})
+
+	t.Run("missing messages are not sent back", func(t *testing.T) {
+		t.Parallel()
+
+		var unicasted []*proto.Message
+
+		i := newIBFT(&unicasted)
+
+		i.HandleMessageRequest(nodes[2], &MessageRequest{
+			View:        &proto.View{Height: 1, Round: 1},
+			MessageType: proto.MessageType_PREPREPARE,
+		})
+		i.HandleMessageRequest(nodes[2], nil)
+
+		assert.Empty(t, unicasted)
+	})
+
+	t.Run("traced transport sends the messages back", func(t *testing.T) {
+		t.Parallel()
+
+		var unicasted []*proto.Message
+
+		i := newIBFT(&unicasted)
+		i.transport = &tracingTransport{Transport: i.transport}
+
+		i.HandleMessageRequest(nodes[2], &MessageRequest{
+			View:        view,
+			MessageType: proto.MessageType_PREPREPARE,
+		})
+
+		assert.Equal(t, []*proto.Message{proposal}, unicasted)
+	})
+}
+
+func TestIBFT_RequestMissingProposal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes       = generateNodeAddresses(4)
+		view        = &proto.View{Height: 1, Round: 0}
+		requestedCh = make(chan []byte, 4)
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+			isProposerFn: func(from []byte, _, _ uint64) bool {
+				return string(from) == string(nodes[3])
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+		},
+		mockDirectTransport{
+			requestMessagesFn: func(to []byte, request *MessageRequest) {
+				assert.True(t, protobuf.Equal(view, request.View))
+				assert.Equal(t, proto.MessageType_PREPREPARE, request.MessageType)
+
+				requestedCh <- to
+			},
+		},
+	)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+
+		_, _ = i.RunSequence(ctx, view.Height)
+	}()
+
+	defer func() {
+		cancelFn()
+		<-doneCh
+	}()
+
+	require.Eventually(t, func() bool {
+		return i.state.getHeight() == view.Height && i.state.getRoundStarted()
+	}, time.Second, 10*time.Millisecond)
+
+	// Make sure the proposal is requested from the senders of the PREPARE messages
+	i.AddMessage(buildBasicPrepareMessage(correctRoundMessage.hash, nodes[1], view))
+
+	select {
+	case to := <-requestedCh:
+		assert.Equal(t, nodes[1], to)
+	case <-time.After(5 * time.Second):
+		t.Fatal("proposal not requested")
+	}
+
+	i.AddMessage(buildBasicPrepareMessage(correctRoundMessage.hash, nodes[2], view))
+
+	select {
+	case to := <-requestedCh:
+		assert.Equal(t, nodes[2], to)
+	case <-time.After(5 * time.Second):
+		t.Fatal("proposal not requested")
+	}
+
+	// Make sure the proposal is accepted once the response arrives
+	i.AddMessage(buildBasicPreprepareMessage(
+		correctRoundMessage.proposal.RawProposal,
+		correctRoundMessage.hash,
+		nil,
+		nodes[3],
+		view,
+	))
+
+	require.Eventually(t, func() bool {
+		return i.state.getProposalMessage() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Make sure each peer is asked only once
+	assert.Empty(t, requestedCh)
+}
+
+func TestIBFT_RequestProposal_LongRound(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes         = generateNodeAddresses(4)
+		view          = &proto.View{Height: 1, Round: 1}
+		clock         = NewFakeClock(time.Unix(0, 0))
+		requestedCh   = make(chan []byte, 4)
+		multicastedCh = make(chan *proto.Message, 4)
+	)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+			isProposerFn: func(from []byte, _, _ uint64) bool {
+				return string(from) == string(nodes[3])
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+		},
+		mockDirectTransport{
+			mockTransport: mockTransport{
+				multicastFn: func(message *proto.Message) {
+					multicastedCh <- message
+				},
+			},
+			requestMessagesFn: func(to []byte, request *MessageRequest) {
+				assert.True(t, protobuf.Equal(view, request.View))
+				assert.Equal(t, proto.MessageType_PREPREPARE, request.MessageType)
+
+				requestedCh <- to
+			},
+		},
+		WithLongRoundThreshold(0),
+		WithClock(clock),
+		WithTraceRecorder(NewMemoryTraceRecorder()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, i.validatorManager.Init(view.Height))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	defer func() {
+		cancelFn()
+		i.wg.Wait()
+	}()
+
+	requested := func() []byte {
+		select {
+		case to := <-requestedCh:
+			return to
+		case <-time.After(5 * time.Second):
+			t.Fatal("proposal not requested")
+
+			return nil
+		}
+	}
+
+	// Make sure the proposal is requested from the proposer, on the interval
+	i.pollProposal(ctx, view)
+
+	assert.Equal(t, nodes[3], requested())
+
+	clock.BlockUntil(1)
+	clock.Advance(DefaultPeriodicTaskInterval)
+
+	assert.Equal(t, nodes[3], requested())
+
+	// Make sure the proposer multicasts the proposal only once,
+	// since the peers request it directly
+	proposal := buildBasicPreprepareMessage(
+		correctRoundMessage.proposal.RawProposal,
+		correctRoundMessage.hash,
+		nil,
+		nodes[0],
+		view,
+	)
+
+	i.notifyPreprepare(ctx, view.Round, proposal)
+
+	assert.Equal(t, proposal, <-multicastedCh)
+	assert.Equal(t, 1, clock.Waiters())
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+var (
+	// ErrSequenceCancelled is returned when the sequence is cancelled
+	// before the proposal is finalized
+	ErrSequenceCancelled = errors.New("sequence cancelled")
+
+	// ErrValidatorSet is returned when the validator set
+	// for the sequence height cannot be initialized
+	ErrValidatorSet = errors.New("failed to initialize validator set")
+
+	// ErrBackend is returned when the backend fails to insert the finalized proposal
+	ErrBackend = errors.New("backend failure")
+
+	// ErrWAL is returned when the state of the sequence height
+	// cannot be restored from the write-ahead log
+	ErrWAL = errors.New("failed to restore state from WAL")
+
+	// ErrSyncRequired is returned when the sequence is aborted,
+	// since validators with quorum voting power are on a higher height
+	ErrSyncRequired = errors.New("validators are on a higher height")
+)
+
+// SequenceResult is the outcome of a finalized sequence
+type SequenceResult struct {
+	// Proposal is the finalized proposal
+	Proposal *proto.Proposal
+
+	// Round is the round in which the proposal was finalized
+	Round uint64
+
+	// CommittedSeals are the seals the proposal was finalized with
+	CommittedSeals []*messages.CommittedSeal
+
+	// Certificate is the finality certificate of the proposal
+	Certificate *proto.FinalityCertificate
+
+	// Rounds is the number of rounds the node ran for the sequence
+	Rounds uint64
+
+	// Duration is the time it took to finalize the proposal
+	Duration time.Duration
+}
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// newSingleNodeIBFT creates an IBFT instance which is
+// the only validator, so it can finalize proposals on its own
+func newSingleNodeIBFT(insertProposalFn insertProposalDelegate) *IBFT {
+	var (
+		i    *IBFT
+		node = []byte("node 0")
+	)
+
+	i = NewIBFT(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return node
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn([][]byte{node}),
+			isProposerFn: func(from []byte, _ uint64, _ uint64) bool {
+				return bytes.Equal(from, node)
+			},
+			isValidProposalHashFn: func(_ *proto.Proposal, hash []byte) bool {
+				return bytes.Equal(hash, correctRoundMessage.hash)
+			},
+			buildProposalFn: func(_ uint64) []byte {
+				return correctRoundMessage.proposal.RawProposal
+			},
+			buildPrePrepareMessageFn: func(
+				rawProposal []byte,
+				certificate *proto.RoundChangeCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return buildBasicPreprepareMessage(rawProposal, correctRoundMessage.hash, certificate, node, view)
+			},
+			buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicPrepareMessage(correctRoundMessage.hash, node, view)
+			},
+			buildCommitMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicCommitMessage(correctRoundMessage.hash, correctRoundMessage.seal, node, view)
+			},
+			insertProposalFn: insertProposalFn,
+		},
+		mockTransport{
+			multicastFn: func(message *proto.Message) {
+				i.AddMessage(message)
+			},
+		},
+	)
+
+	return i
+}
+
+func TestIBFT_RunSequence_Result(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	i := newSingleNodeIBFT(nil)
+
+	result, err := i.RunSequence(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, correctRoundMessage.proposal.RawProposal, result.Proposal.RawProposal)
+	assert.Equal(t, uint64(0), result.Round)
+	assert.Equal(t, uint64(1), result.Rounds)
+	assert.Positive(t, result.Duration)
+	assert.Equal(t, []*messages.CommittedSeal{
+		{
+			Signer:    []byte("node 0"),
+			Signature: correctRoundMessage.seal,
+		},
+	}, result.CommittedSeals)
+
+	// Make sure the finality certificate of the proposal is verifiable
+	votingPowers, err := testCommonGetVotingPowertFn([][]byte{[]byte("node 0")})(1)
+	require.NoError(t, err)
+
+	assert.Equal(t, &proto.View{Height: 1, Round: 0}, result.Certificate.View)
+	assert.Equal(t, correctRoundMessage.hash, result.Certificate.ProposalHash)
+	assert.NoError(t, VerifyFinalityCertificate(result.Certificate, 1, votingPowers, mockBackend{}))
+}
+
+// TestIBFT_RunSequence_CancelledSealCollection makes sure the proposal is inserted
+// if the sequence is cancelled while more seals are collected after the commit quorum
+func TestIBFT_RunSequence_CancelledSealCollection(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock     = NewFakeClock(time.Unix(0, 0))
+		insertedC = make(chan *proto.Proposal, 1)
+	)
+
+	i := newSingleNodeIBFT(func(proposal *proto.Proposal, _ []*messages.CommittedSeal) error {
+		insertedC <- proposal
+
+		return nil
+	})
+
+	// Node 0 has the quorum on its own, but the commit
+	// message of node 1 is needed for the seal collection target
+	backend, _ := i.backend.(mockBackend)
+	backend.getVotingPowerFn = func(_ uint64) (map[string]*big.Int, error) {
+		return map[string]*big.Int{
+			"node 0": big.NewInt(10),
+			"node 1": big.NewInt(1),
+		}, nil
+	}
+
+	i.backend = backend
+	i.validatorManager = NewValidatorManager(backend, mockLogger{})
+	i.config.clock = clock
+	i.config.commitGracePeriod = time.Hour
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	resultCh := make(chan *SequenceResult, 1)
+
+	go func() {
+		result, _ := i.RunSequence(ctx, 1)
+
+		resultCh <- result
+	}()
+
+	// Wait for the commit grace period to start
+	require.Eventually(t, func() bool {
+		clock.lock.Lock()
+		defer clock.lock.Unlock()
+
+		for w := range clock.waiters {
+			if w.deadline.Equal(clock.now.Add(time.Hour)) {
+				return true
+			}
+		}
+
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancelFn()
+
+	select {
+	case result := <-resultCh:
+		require.NotNil(t, result)
+		assert.Len(t, result.CommittedSeals, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sequence not done")
+	}
+
+	// Make sure the proposal was inserted with the seals collected so far
+	select {
+	case proposal := <-insertedC:
+		assert.Equal(t, correctRoundMessage.proposal.RawProposal, proposal.RawProposal)
+	default:
+		t.Fatal("proposal not inserted")
+	}
+}
+
+func TestIBFT_RunSequence_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("validator set failure", func(t *testing.T) {
+		t.Parallel()
+
+		errVotingPowers := errors.New("voting powers unavailable")
+
+		i := NewIBFT(mockLogger{}, mockBackend{
+			getVotingPowerFn: func(_ uint64) (map[string]*big.Int, error) {
+				return nil, errVotingPowers
+			},
+		}, mockTransport{})
+
+		result, err := i.RunSequence(context.Background(), 1)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrValidatorSet)
+		assert.ErrorIs(t, err, errVotingPowers)
+	})
+
+	t.Run("WAL failure", func(t *testing.T) {
+		t.Parallel()
+
+		errRead := errors.New("WAL unavailable")
+
+		i := NewIBFT(mockLogger{}, mockBackend{
+			getVotingPowerFn: testCommonGetVotingPowertFn([][]byte{[]byte("node 0")}),
+		}, mockTransport{})
+		i.SetWAL(mockWAL{
+			readFn: func(_ uint64) ([]*proto.WALEntry, error) {
+				return nil, errRead
+			},
+		})
+
+		result, err := i.RunSequence(context.Background(), 1)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrWAL)
+		assert.ErrorIs(t, err, errRead)
+	})
+
+	t.Run("backend failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+
+		errInsert := errors.New("insert failed")
+
+		i := newSingleNodeIBFT(func(_ *proto.Proposal, _ []*messages.CommittedSeal) error {
+			return errInsert
+		})
+
+		result, err := i.RunSequence(ctx, 1)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrBackend)
+		assert.ErrorIs(t, err, errInsert)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancelFn()
+
+		i := NewIBFT(mockLogger{}, mockBackend{
+			getVotingPowerFn: testCommonGetVotingPowertFnForCnt(4),
+		}, mockTransport{})
+
+		result, err := i.RunSequence(ctx, 1)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrSequenceCancelled)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		require.NoError(t, i.Start())
+		require.NoError(t, i.Stop())
+
+		result, err := i.RunSequence(context.Background(), 1)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrNotStarted)
+	})
+}
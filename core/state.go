@@ -108,6 +108,10 @@ func (s *state) getProposalHash() []byte {
 	s.RLock()
 	defer s.RUnlock()
 
+	if s.proposalMessage == nil {
+		return nil
+	}
+
 	return messages.ExtractProposalHash(s.proposalMessage)
 }
 
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// SyncNotifier is notified when the node falls behind the other validators
+type SyncNotifier interface {
+	// OnFutureHeight is called when senders with quorum voting power are observed
+	// at the specified height, which is higher than the one the node is running.
+	// The running sequence is aborted after the call, so the embedding chain can
+	// fetch the missing blocks and run the sequence for the newer height
+	OnFutureHeight(height uint64)
+}
+
+// SetSyncNotifier sets the notifier alerted when the node is lagging behind.
+// Sequences are aborted in favor of syncing only if the notifier is set
+func (i *IBFT) SetSyncNotifier(notifier SyncNotifier) {
+	i.syncNotifier = notifier
+}
+
+// trackFutureHeight notes the height of a message for a future height,
+// and alerts the sync notifier if a quorum of senders is already there
+func (i *IBFT) trackFutureHeight(message *proto.Message) {
+	if i.syncNotifier == nil {
+		return
+	}
+
+	i.futureHeights.add(message.From, message.View.Height)
+
+	i.checkFutureHeight()
+}
+
+// checkFutureHeight alerts the sync notifier, and signals the sequence routine
+// (RunSequence), if senders with quorum voting power are on a higher height
+func (i *IBFT) checkFutureHeight() {
+	if i.syncNotifier == nil {
+		return
+	}
+
+	height, ok := i.futureHeights.getQuorumHeight(i.hasFutureQuorum)
+	if !ok || !i.futureHeights.markNotified(height) {
+		return
+	}
+
+	i.log.Info("validators are on a higher height", "height", height)
+
+	i.syncNotifier.OnFutureHeight(height)
+
+	// Never block the message handling, the sequence routine
+	// only needs to know that it should stop
+	select {
+	case i.futureHeight <- height:
+	default:
+	}
+}
+
+// hasFutureQuorum checks if the senders reach the quorum of the future height.
+// The validator set of the height is used if it is known, otherwise the voting
+// powers of the current height are used, since the node has no knowledge of it
+func (i *IBFT) hasFutureQuorum(height uint64, senders map[string]struct{}) bool {
+	if i.validatorSets != nil {
+		if votingPowers, ok := i.validatorSets.VotingPowersAt(height); ok {
+			return hasVotingPowerQuorum(votingPowers, senders)
+		}
+	}
+
+	return i.validatorManager.HasQuorum(senders)
+}
+
+// futureHeights keeps track of the highest height
+// each sender was observed at. The zero value is ready to use
+type futureHeights struct {
+	sync.Mutex
+
+	// heights maps the sender to the highest height
+	// their messages were observed at
+	heights map[string]uint64
+
+	// notifiedHeight is the highest height
+	// the sync notifier was alerted about
+	notifiedHeight uint64
+}
+
+// reset removes all the tracked heights not higher than the specified one
+func (f *futureHeights) reset(height uint64) {
+	f.Lock()
+	defer f.Unlock()
+
+	for sender, senderHeight := range f.heights {
+		if senderHeight <= height {
+			delete(f.heights, sender)
+		}
+	}
+
+	f.notifiedHeight = height
+}
+
+// add notes the height the sender was observed at
+func (f *futureHeights) add(sender []byte, height uint64) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.heights == nil {
+		f.heights = make(map[string]uint64)
+	}
+
+	if f.heights[string(sender)] < height {
+		f.heights[string(sender)] = height
+	}
+}
+
+// getQuorumHeight returns the highest height for which the senders
+// observed at it, or at a higher height, reach the quorum
+func (f *futureHeights) getQuorumHeight(hasQuorum func(uint64, map[string]struct{}) bool) (uint64, bool) {
+	f.Lock()
+	defer f.Unlock()
+
+	heights := make([]uint64, 0, len(f.heights))
+	for _, height := range f.heights {
+		heights = append(heights, height)
+	}
+
+	// Start from the highest height, so the node
+	// can catch up with as many blocks as possible
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] > heights[j]
+	})
+
+	for _, height := range heights {
+		senders := make(map[string]struct{})
+
+		for sender, senderHeight := range f.heights {
+			if senderHeight >= height {
+				senders[sender] = struct{}{}
+			}
+		}
+
+		if hasQuorum(height, senders) {
+			return height, true
+		}
+	}
+
+	return 0, false
+}
+
+// markNotified notes the sync notifier was alerted about the height,
+// and returns false if it was already alerted about it, or a higher one
+func (f *futureHeights) markNotified(height uint64) bool {
+	f.Lock()
+	defer f.Unlock()
+
+	if height <= f.notifiedHeight {
+		return false
+	}
+
+	f.notifiedHeight = height
+
+	return true
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestFutureHeights_GetQuorumHeight(t *testing.T) {
+	t.Parallel()
+
+	// The quorum is reached by any 3 senders
+	hasQuorum := func(_ uint64, senders map[string]struct{}) bool {
+		return len(senders) >= 3
+	}
+
+	testTable := []struct {
+		name           string
+		heights        map[string]uint64
+		expectedHeight uint64
+		expectedFound  bool
+	}{
+		{
+			"no heights tracked",
+			nil,
+			0,
+			false,
+		},
+		{
+			"quorum not reached",
+			map[string]uint64{
+				"node 0": 5,
+				"node 1": 6,
+			},
+			0,
+			false,
+		},
+		{
+			"quorum on the same height",
+			map[string]uint64{
+				"node 0": 5,
+				"node 1": 5,
+				"node 2": 5,
+			},
+			5,
+			true,
+		},
+		{
+			"quorum on different heights",
+			map[string]uint64{
+				"node 0": 4,
+				"node 1": 7,
+				"node 2": 6,
+				"node 3": 9,
+			},
+			6,
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var heights futureHeights
+
+			for sender, height := range testCase.heights {
+				heights.add([]byte(sender), height)
+			}
+
+			height, found := heights.getQuorumHeight(hasQuorum)
+
+			assert.Equal(t, testCase.expectedFound, found)
+			assert.Equal(t, testCase.expectedHeight, height)
+		})
+	}
+}
+
+func TestFutureHeights_MarkNotified(t *testing.T) {
+	t.Parallel()
+
+	var heights futureHeights
+
+	heights.add([]byte("node 0"), 3)
+	heights.add([]byte("node 1"), 5)
+	heights.reset(3)
+
+	// Make sure the heights that are not in the future are dropped
+	assert.Len(t, heights.heights, 1)
+
+	assert.False(t, heights.markNotified(3))
+	assert.True(t, heights.markNotified(5))
+	assert.False(t, heights.markNotified(5))
+	assert.False(t, heights.markNotified(4))
+	assert.True(t, heights.markNotified(6))
+}
+
+func TestIBFT_FutureHeightSync(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height       = uint64(1)
+		futureHeight = uint64(5)
+		nodes        = generateNodeAddresses(4)
+	)
+
+	newFutureMessages := func() []*proto.Message {
+		view := &proto.View{
+			Height: futureHeight,
+			Round:  0,
+		}
+
+		return []*proto.Message{
+			buildBasicPrepareMessage(correctRoundMessage.hash, nodes[1], view),
+			buildBasicPrepareMessage(correctRoundMessage.hash, nodes[2], view),
+			buildBasicPrepareMessage(correctRoundMessage.hash, nodes[3], view),
+		}
+	}
+
+	newIBFT := func(notifiedCh chan uint64) *IBFT {
+		i := NewIBFT(
+			mockLogger{},
+			mockBackend{
+				idFn: func() []byte {
+					return nodes[0]
+				},
+				getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+			},
+			mockTransport{},
+		)
+
+		i.SetSyncNotifier(mockSyncNotifier{
+			onFutureHeightFn: func(height uint64) {
+				notifiedCh <- height
+			},
+		})
+
+		return i
+	}
+
+	runSequence := func(ctx context.Context, i *IBFT) chan error {
+		doneCh := make(chan error, 1)
+
+		go func() {
+			_, err := i.RunSequence(ctx, height)
+
+			doneCh <- err
+		}()
+
+		return doneCh
+	}
+
+	t.Run("sequence aborted on quorum at a future height", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		notifiedCh := make(chan uint64, 1)
+		i := newIBFT(notifiedCh)
+
+		doneCh := runSequence(ctx, i)
+
+		require.Eventually(t, func() bool {
+			return i.state.getHeight() == height
+		}, time.Second, 10*time.Millisecond)
+
+		for _, message := range newFutureMessages() {
+			i.AddMessage(message)
+		}
+
+		select {
+		case notifiedHeight := <-notifiedCh:
+			assert.Equal(t, futureHeight, notifiedHeight)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sync notifier not alerted")
+		}
+
+		select {
+		case err := <-doneCh:
+			assert.ErrorIs(t, err, ErrSyncRequired)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sequence not aborted")
+		}
+	})
+
+	t.Run("sequence aborted on start when lagging behind", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		notifiedCh := make(chan uint64, 1)
+		i := newIBFT(notifiedCh)
+
+		// Make sure the messages are received before the sequence is started.
+		// The quorum can't be calculated yet, since the validator set is unknown
+		for _, message := range newFutureMessages() {
+			i.AddMessage(message)
+		}
+
+		doneCh := runSequence(ctx, i)
+
+		select {
+		case notifiedHeight := <-notifiedCh:
+			assert.Equal(t, futureHeight, notifiedHeight)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sync notifier not alerted")
+		}
+
+		select {
+		case err := <-doneCh:
+			assert.ErrorIs(t, err, ErrSyncRequired)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sequence not aborted")
+		}
+	})
+
+	t.Run("sequence not aborted without quorum", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+
+		notifiedCh := make(chan uint64, 1)
+		i := newIBFT(notifiedCh)
+
+		doneCh := runSequence(ctx, i)
+
+		require.Eventually(t, func() bool {
+			return i.state.getHeight() == height
+		}, time.Second, 10*time.Millisecond)
+
+		for _, message := range newFutureMessages()[:2] {
+			i.AddMessage(message)
+		}
+
+		select {
+		case <-notifiedCh:
+			t.Fatal("sync notifier alerted without quorum")
+		case <-doneCh:
+			t.Fatal("sequence aborted without quorum")
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		cancelFn()
+		assert.ErrorIs(t, <-doneCh, ErrSequenceCancelled)
+	})
+}
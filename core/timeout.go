@@ -0,0 +1,283 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLongRoundTimeout is the default timeout of the rounds above the long round threshold
+	DefaultLongRoundTimeout = 10 * time.Minute
+
+	// MeasurementRound is the prefix of the round duration measurements
+	MeasurementRound = "round"
+	// MeasurementSequence is the prefix of the sequence duration measurements
+	MeasurementSequence = "sequence"
+
+	adaptiveTimeoutMultiplier = 2   // Multiple of the average sequence duration used as the base timeout
+	adaptiveTimeoutSmoothing  = 0.2 // Weight of the latest observation in the average sequence duration
+)
+
+// ErrInvalidRoundTimeout is returned when the timeouts of a round timeout policy are invalid
+var ErrInvalidRoundTimeout = errors.New("invalid round timeout")
+
+// RoundTimeoutPolicy determines how long each round of consensus lasts.
+// Implementations must be safe for concurrent use
+type RoundTimeoutPolicy interface {
+	// RoundTimeout returns the timeout for the specified round
+	RoundTimeout(round uint64) time.Duration
+}
+
+// LatencyObserver is implemented by the round timeout policies
+// that adapt to the measured durations of the rounds and sequences
+type LatencyObserver interface {
+	// ObserveLatency is called with each measured duration, where the
+	// prefix is either MeasurementRound or MeasurementSequence.
+	// The sequence durations are only observed for the finalized sequences
+	ObserveLatency(prefix string, duration time.Duration)
+}
+
+// RoundTimeoutValidator is implemented by the round timeout policies
+// which check their configuration when they are set
+type RoundTimeoutValidator interface {
+	// Validate returns an error if the policy is misconfigured
+	Validate() error
+}
+
+// ExponentialTimeout doubles the timeout on each round, up to the long round threshold,
+// after which the rounds last for the long round timeout.
+// The default policy is built from the configured base and additional timeouts.
+// For instance, with a base timeout of 1 sec:
+//   - round 0: 1 sec
+//   - round 1: 2 sec
+//   - round 2: 4 sec
+//   - round 3: 8 sec
+type ExponentialTimeout struct {
+	// Base is the timeout of round 0
+	Base time.Duration
+
+	// Additional is added to the timeout of each round
+	Additional time.Duration
+
+	// LongRoundThreshold is the round above which
+	// the rounds last for the long round timeout
+	LongRoundThreshold uint64
+
+	// LongRoundTimeout is the timeout of the rounds above the long round threshold
+	LongRoundTimeout time.Duration
+}
+
+// RoundTimeout returns the exponential timeout for the specified round.
+// The timeout saturates at the maximum duration, instead of overflowing
+func (p ExponentialTimeout) RoundTimeout(round uint64) time.Duration {
+	if round > p.LongRoundThreshold {
+		return p.LongRoundTimeout
+	}
+
+	roundTimeout := capExponential(p.Base, 0, round)
+	if roundTimeout > math.MaxInt64-p.Additional {
+		return math.MaxInt64
+	}
+
+	return roundTimeout + p.Additional
+}
+
+// LinearTimeout increases the timeout by a fixed amount on each round
+type LinearTimeout struct {
+	// Base is the timeout of round 0
+	Base time.Duration
+
+	// Increment is added to the timeout on each round
+	Increment time.Duration
+}
+
+// Validate checks the base timeout is positive, and the increment is not negative
+func (p LinearTimeout) Validate() error {
+	if p.Base <= 0 {
+		return fmt.Errorf("%w: timeout %s must be positive", ErrInvalidRoundTimeout, p.Base)
+	}
+
+	if p.Increment < 0 {
+		return fmt.Errorf("%w: increment %s must not be negative", ErrInvalidRoundTimeout, p.Increment)
+	}
+
+	return nil
+}
+
+// RoundTimeout returns the linear timeout for the specified round
+func (p LinearTimeout) RoundTimeout(round uint64) time.Duration {
+	return p.Base + p.Increment*time.Duration(round)
+}
+
+// CappedExponentialTimeout doubles the timeout on each round, up to the maximum timeout
+type CappedExponentialTimeout struct {
+	// Base is the timeout of round 0
+	Base time.Duration
+
+	// Max is the upper bound of the timeout. The timeout is uncapped if it is not set
+	Max time.Duration
+}
+
+// Validate checks the base timeout is positive, and not above the maximum timeout
+func (p CappedExponentialTimeout) Validate() error {
+	return validateTimeoutBounds(p.Base, p.Max)
+}
+
+// RoundTimeout returns the capped exponential timeout for the specified round
+func (p CappedExponentialTimeout) RoundTimeout(round uint64) time.Duration {
+	return capExponential(p.Base, p.Max, round)
+}
+
+// JitteredTimeout adds a random amount of time to the timeouts of another policy,
+// so the validators don't time out all at once
+type JitteredTimeout struct {
+	// Policy is the policy the timeouts are derived from
+	Policy RoundTimeoutPolicy
+
+	// Factor is the upper bound of the added time, as a fraction
+	// of the timeout. For instance, 0.1 adds up to 10%
+	Factor float64
+}
+
+// RoundTimeout returns the jittered timeout for the specified round
+func (p JitteredTimeout) RoundTimeout(round uint64) time.Duration {
+	timeout := p.Policy.RoundTimeout(round)
+
+	maxJitter := int64(float64(timeout) * p.Factor)
+	if maxJitter <= 0 {
+		return timeout
+	}
+
+	//nolint:gosec
+	return timeout + time.Duration(rand.Int63n(maxJitter))
+}
+
+// ObserveLatency passes the measured duration to the underlying policy, if it observes them
+func (p JitteredTimeout) ObserveLatency(prefix string, duration time.Duration) {
+	if observer, ok := p.Policy.(LatencyObserver); ok {
+		observer.ObserveLatency(prefix, duration)
+	}
+}
+
+// Validate checks the underlying policy is set, the jitter factor is not negative,
+// and validates the underlying policy, if it can be
+func (p JitteredTimeout) Validate() error {
+	if p.Policy == nil {
+		return fmt.Errorf("%w: jittered policy is not set", ErrInvalidRoundTimeout)
+	}
+
+	if p.Factor < 0 {
+		return fmt.Errorf("%w: jitter factor %v must not be negative", ErrInvalidRoundTimeout, p.Factor)
+	}
+
+	if validator, ok := p.Policy.(RoundTimeoutValidator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+// AdaptiveTimeout derives the timeouts from the observed durations of the previous sequences.
+// The round 0 timeout is a multiple of their moving average, and it is doubled on each round,
+// within the minimum and maximum timeouts
+type AdaptiveTimeout struct {
+	lock sync.RWMutex
+
+	minTimeout time.Duration
+	maxTimeout time.Duration
+
+	// average is the moving average of the sequence durations,
+	// which is zero until the first one is observed
+	average time.Duration
+}
+
+// NewAdaptiveTimeout creates a new adaptive round timeout policy,
+// bounded by the minimum and maximum timeouts. The timeouts are uncapped
+// if the maximum timeout is zero
+func NewAdaptiveTimeout(minTimeout, maxTimeout time.Duration) (*AdaptiveTimeout, error) {
+	if err := validateTimeoutBounds(minTimeout, maxTimeout); err != nil {
+		return nil, err
+	}
+
+	return &AdaptiveTimeout{
+		minTimeout: minTimeout,
+		maxTimeout: maxTimeout,
+	}, nil
+}
+
+// RoundTimeout returns the adaptive timeout for the specified round
+func (p *AdaptiveTimeout) RoundTimeout(round uint64) time.Duration {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	base := time.Duration(float64(p.average) * adaptiveTimeoutMultiplier)
+	if base < p.minTimeout {
+		base = p.minTimeout
+	}
+
+	return capExponential(base, p.maxTimeout, round)
+}
+
+// ObserveLatency updates the moving average with the measured sequence duration
+func (p *AdaptiveTimeout) ObserveLatency(prefix string, duration time.Duration) {
+	if prefix != MeasurementSequence {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.average == 0 {
+		p.average = duration
+
+		return
+	}
+
+	p.average = time.Duration(
+		adaptiveTimeoutSmoothing*float64(duration) + (1-adaptiveTimeoutSmoothing)*float64(p.average),
+	)
+}
+
+// validateTimeoutBounds checks the lower timeout is positive, and not above the upper one, if it is set
+func validateTimeoutBounds(lower, upper time.Duration) error {
+	if lower <= 0 {
+		return fmt.Errorf("%w: timeout %s must be positive", ErrInvalidRoundTimeout, lower)
+	}
+
+	if upper < 0 || (upper > 0 && upper < lower) {
+		return fmt.Errorf("%w: maximum timeout %s is below %s", ErrInvalidRoundTimeout, upper, lower)
+	}
+
+	return nil
+}
+
+// capExponential doubles the base timeout for each round, up to the maximum timeout.
+// The timeout is uncapped if the maximum timeout is zero
+func capExponential(base, maxTimeout time.Duration, round uint64) time.Duration {
+	timeout := base
+	if timeout <= 0 {
+		return timeout
+	}
+
+	if maxTimeout <= 0 {
+		maxTimeout = math.MaxInt64
+	}
+
+	for r := uint64(0); r < round && timeout < maxTimeout; r++ {
+		if timeout > maxTimeout/2 {
+			return maxTimeout
+		}
+
+		timeout *= 2
+	}
+
+	if timeout > maxTimeout {
+		return maxTimeout
+	}
+
+	return timeout
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTimeoutPolicy(t *testing.T) {
+	t.Parallel()
+
+	exponential := ExponentialTimeout{
+		Base:               time.Second,
+		Additional:         time.Second,
+		LongRoundThreshold: 3,
+		LongRoundTimeout:   time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		policy   RoundTimeoutPolicy
+		round    uint64
+		expected time.Duration
+	}{
+		{"exponential round 0", exponential, 0, 2 * time.Second},
+		{"exponential round 3", exponential, 3, 9 * time.Second},
+		{"exponential long round", exponential, 4, time.Hour},
+		{
+			"exponential overflowing round",
+			ExponentialTimeout{Base: 10 * time.Second, LongRoundThreshold: math.MaxUint64},
+			34,
+			math.MaxInt64,
+		},
+		{
+			"exponential overflowing additional",
+			ExponentialTimeout{Base: 10 * time.Second, Additional: time.Second, LongRoundThreshold: math.MaxUint64},
+			math.MaxUint64 - 1,
+			math.MaxInt64,
+		},
+		{"linear round 0", LinearTimeout{Base: time.Second, Increment: 2 * time.Second}, 0, time.Second},
+		{"linear round 3", LinearTimeout{Base: time.Second, Increment: 2 * time.Second}, 3, 7 * time.Second},
+		{"capped round 0", CappedExponentialTimeout{Base: time.Second, Max: 10 * time.Second}, 0, time.Second},
+		{"capped round 3", CappedExponentialTimeout{Base: time.Second, Max: 10 * time.Second}, 3, 8 * time.Second},
+		{"capped round 4", CappedExponentialTimeout{Base: time.Second, Max: 10 * time.Second}, 4, 10 * time.Second},
+		{"uncapped round 4", CappedExponentialTimeout{Base: time.Second}, 4, 16 * time.Second},
+		{"uncapped huge round", CappedExponentialTimeout{Base: time.Second}, math.MaxUint64, math.MaxInt64},
+		{
+			"capped huge round",
+			CappedExponentialTimeout{Base: time.Second, Max: math.MaxInt64},
+			math.MaxUint64,
+			math.MaxInt64,
+		},
+		{"jittered without jitter", JitteredTimeout{Policy: exponential, Factor: 0}, 1, 3 * time.Second},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.policy.RoundTimeout(test.round))
+		})
+	}
+}
+
+func TestJitteredTimeout(t *testing.T) {
+	t.Parallel()
+
+	policy := JitteredTimeout{
+		Policy: LinearTimeout{Base: time.Second},
+		Factor: 0.5,
+	}
+
+	for n := 0; n < 100; n++ {
+		timeout := policy.RoundTimeout(0)
+
+		assert.GreaterOrEqual(t, timeout, time.Second)
+		assert.Less(t, timeout, 1500*time.Millisecond)
+	}
+}
+
+func TestAdaptiveTimeout(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewAdaptiveTimeout(time.Second, time.Minute)
+	require.NoError(t, err)
+
+	// Make sure the minimum timeout is used until the sequences are observed
+	assert.Equal(t, time.Second, policy.RoundTimeout(0))
+	assert.Equal(t, 2*time.Second, policy.RoundTimeout(1))
+
+	// Make sure only the sequence durations are observed
+	policy.ObserveLatency(MeasurementRound, 10*time.Second)
+	assert.Equal(t, time.Second, policy.RoundTimeout(0))
+
+	policy.ObserveLatency(MeasurementSequence, 2*time.Second)
+	assert.Equal(t, 4*time.Second, policy.RoundTimeout(0))
+
+	// Make sure the average is moving towards the latest observations
+	policy.ObserveLatency(MeasurementSequence, 12*time.Second)
+	assert.Equal(t, 8*time.Second, policy.RoundTimeout(0))
+
+	// Make sure the maximum timeout is respected
+	assert.Equal(t, time.Minute, policy.RoundTimeout(3))
+}
+
+func TestIBFT_RoundTimeoutPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewAdaptiveTimeout(time.Second, time.Minute)
+	require.NoError(t, err)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{},
+		mockTransport{},
+		WithBaseRoundTimeout(time.Hour),
+		WithRoundTimeoutPolicy(JitteredTimeout{Policy: policy}),
+	)
+	require.NoError(t, err)
+
+	// Make sure the policy is used instead of the base timeout
+	assert.Equal(t, time.Second, i.getConfig().roundTimeout(0))
+
+	// Make sure the measurements are observed by the policy
+	i.observeLatency(MeasurementSequence, 2*time.Second)
+	assert.Equal(t, 4*time.Second, i.getConfig().roundTimeout(0))
+}
+
+func TestRoundTimeoutPolicy_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy RoundTimeoutPolicy
+		valid  bool
+	}{
+		{"capped", CappedExponentialTimeout{Base: time.Second, Max: time.Minute}, true},
+		{"uncapped", CappedExponentialTimeout{Base: time.Second}, true},
+		{"capped without base", CappedExponentialTimeout{Max: time.Minute}, false},
+		{"capped below base", CappedExponentialTimeout{Base: time.Minute, Max: time.Second}, false},
+		{"capped negative max", CappedExponentialTimeout{Base: time.Second, Max: -time.Second}, false},
+		{"jittered", JitteredTimeout{Policy: CappedExponentialTimeout{Base: time.Second}, Factor: 0.1}, true},
+		{"jittered negative factor", JitteredTimeout{Policy: LinearTimeout{Base: time.Second}, Factor: -1}, false},
+		{"jittered invalid policy", JitteredTimeout{Policy: CappedExponentialTimeout{}}, false},
+		{"jittered without policy", JitteredTimeout{Factor: 0.1}, false},
+		{"linear", LinearTimeout{Base: time.Second, Increment: time.Second}, true},
+		{"linear without increment", LinearTimeout{Base: time.Second}, true},
+		{"linear without base", LinearTimeout{Increment: time.Second}, false},
+		{"linear negative increment", LinearTimeout{Base: time.Second, Increment: -time.Second}, false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewIBFTWithOptions(
+				mockLogger{},
+				mockBackend{},
+				mockTransport{},
+				WithRoundTimeoutPolicy(test.policy),
+			)
+
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidOption)
+				assert.ErrorIs(t, err, ErrInvalidRoundTimeout)
+			}
+		})
+	}
+
+	t.Run("adaptive", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewAdaptiveTimeout(time.Second, 0)
+		assert.NoError(t, err)
+
+		_, err = NewAdaptiveTimeout(0, time.Minute)
+		assert.ErrorIs(t, err, ErrInvalidRoundTimeout)
+
+		_, err = NewAdaptiveTimeout(time.Minute, time.Second)
+		assert.ErrorIs(t, err, ErrInvalidRoundTimeout)
+	})
+}
+
+// TestIBFT_RoundTimeoutPolicy_CancelledSequence makes sure
+// the durations of the cancelled sequences are not observed
+func TestIBFT_RoundTimeoutPolicy_CancelledSequence(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewAdaptiveTimeout(time.Second, time.Minute)
+	require.NoError(t, err)
+
+	nodes := generateNodeAddresses(4)
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+			getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+		},
+		mockTransport{},
+		WithRoundTimeoutPolicy(policy),
+	)
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	_, err = i.RunSequence(ctx, 1)
+	require.ErrorIs(t, err, ErrSequenceCancelled)
+
+	assert.Equal(t, time.Second, policy.RoundTimeout(0))
+}
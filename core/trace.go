@@ -0,0 +1,524 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// The names of the traced backend methods
+const (
+	methodID                      = "ID"
+	methodGetVotingPowers         = "GetVotingPowers"
+	methodStartRound              = "StartRound"
+	methodBuildProposal           = "BuildProposal"
+	methodInsertProposal          = "InsertProposal"
+	methodReportEquivocation      = "ReportEquivocation"
+	methodIsValidProposal         = "IsValidProposal"
+	methodIsValidValidator        = "IsValidValidator"
+	methodIsProposer              = "IsProposer"
+	methodIsValidProposalHash     = "IsValidProposalHash"
+	methodIsValidCommittedSeal    = "IsValidCommittedSeal"
+	methodBuildPrePrepareMessage  = "BuildPrePrepareMessage"
+	methodBuildPrepareMessage     = "BuildPrepareMessage"
+	methodBuildCommitMessage      = "BuildCommitMessage"
+	methodBuildRoundChangeMessage = "BuildRoundChangeMessage"
+)
+
+// TraceRecorder is the append-only log the inputs and the outputs of the IBFT state machine
+// are traced into: the added and the multicasted messages, the timers and the backend calls.
+// The trace of a stalled height can be replayed with Replay.
+// Implementations must be safe for concurrent use
+type TraceRecorder interface {
+	// Record appends the entry to the trace
+	Record(entry *proto.TraceEntry) error
+}
+
+// MemoryTraceRecorder is the TraceRecorder which keeps the trace in memory
+type MemoryTraceRecorder struct {
+	lock sync.Mutex
+
+	entries []*proto.TraceEntry
+
+	// entriesChanged is closed, and replaced, when a new entry is recorded
+	entriesChanged chan struct{}
+}
+
+// NewMemoryTraceRecorder creates a new in-memory trace recorder
+func NewMemoryTraceRecorder() *MemoryTraceRecorder {
+	return &MemoryTraceRecorder{
+		entriesChanged: make(chan struct{}),
+	}
+}
+
+// Record appends the entry to the trace
+func (r *MemoryTraceRecorder) Record(entry *proto.TraceEntry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.entries = append(r.entries, entry)
+
+	close(r.entriesChanged)
+	r.entriesChanged = make(chan struct{})
+
+	return nil
+}
+
+// Entries returns the recorded entries, in the order they were recorded
+func (r *MemoryTraceRecorder) Entries() []*proto.TraceEntry {
+	entries, _ := r.entriesSince(0)
+
+	return entries
+}
+
+// entriesSince returns the entries recorded after the first n ones,
+// and the channel which is closed once a new entry is recorded
+func (r *MemoryTraceRecorder) entriesSince(n int) ([]*proto.TraceEntry, <-chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if n > len(r.entries) {
+		n = len(r.entries)
+	}
+
+	return append([]*proto.TraceEntry{}, r.entries[n:]...), r.entriesChanged
+}
+
+// tracer records the trace entries of an IBFT instance
+type tracer struct {
+	recorder TraceRecorder
+	log      Logger
+
+	// clock is the source of the entry offsets
+	clock   Clock
+	started time.Time
+
+	// idOnce makes sure the node ID is traced only once, since it never changes
+	idOnce sync.Once
+}
+
+// newTracer creates a tracer recording into the passed in recorder,
+// with the offsets relative to the current time of the clock
+func newTracer(recorder TraceRecorder, clock Clock, log Logger) *tracer {
+	return &tracer{
+		recorder: recorder,
+		log:      log,
+		clock:    clock,
+		started:  clock.Now(),
+	}
+}
+
+// record sets the offset of the entry, and appends it to the trace
+func (t *tracer) record(entry *proto.TraceEntry) {
+	entry.Offset = int64(t.clock.Since(t.started))
+
+	if err := t.recorder.Record(entry); err != nil {
+		t.log.Error("failed to record the trace entry", "type", entry.Type, "err", err)
+	}
+}
+
+// recordCall records the backend call
+func (t *tracer) recordCall(call *proto.BackendCall) {
+	t.record(&proto.TraceEntry{
+		Type: proto.TraceEntry_BACKEND,
+		Call: call,
+	})
+}
+
+// traceMessage records the message added to the IBFT instance, if it is traced
+func (i *IBFT) traceMessage(message *proto.Message) {
+	if i.tracer == nil {
+		return
+	}
+
+	i.tracer.record(&proto.TraceEntry{
+		Type:    proto.TraceEntry_RECEIVED,
+		Message: message,
+	})
+}
+
+// traceSequence records the start of the sequence, if the IBFT instance is traced
+func (i *IBFT) traceSequence(height uint64) {
+	if i.tracer == nil {
+		return
+	}
+
+	i.tracer.record(&proto.TraceEntry{
+		Type:   proto.TraceEntry_SEQUENCE,
+		Height: height,
+	})
+}
+
+// traceSequenceDone records the return from the sequence, if the IBFT instance is traced
+func (i *IBFT) traceSequenceDone(height uint64) {
+	if i.tracer == nil {
+		return
+	}
+
+	i.tracer.record(&proto.TraceEntry{
+		Type:   proto.TraceEntry_SEQUENCE_DONE,
+		Height: height,
+	})
+}
+
+// tracingTransport records the multicasted messages
+type tracingTransport struct {
+	Transport
+
+	tracer *tracer
+}
+
+// Multicast records the message, and multicasts it
+func (t *tracingTransport) Multicast(message *proto.Message) {
+	t.tracer.record(&proto.TraceEntry{
+		Type:    proto.TraceEntry_SENT,
+		Message: message,
+	})
+
+	t.Transport.Multicast(message)
+}
+
+// tracingClock records the started and the expired timers
+type tracingClock struct {
+	Clock
+
+	tracer *tracer
+}
+
+// NewTimer records the timer, and records its expiration
+//
+//nolint:ireturn
+func (c *tracingClock) NewTimer(d time.Duration) Timer {
+	c.tracer.record(&proto.TraceEntry{
+		Type:     proto.TraceEntry_TIMER_STARTED,
+		Duration: int64(d),
+	})
+
+	t := &tracingTimer{
+		timer:  c.Clock.NewTimer(d),
+		ch:     make(chan time.Time, 1),
+		stopCh: make(chan struct{}),
+	}
+
+	go t.watch(c.tracer, d)
+
+	return t
+}
+
+// tracingTimer records the expiration of the timer, before delivering it
+type tracingTimer struct {
+	timer Timer
+
+	ch       chan time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// watch waits for the timer to expire, or to be stopped
+func (t *tracingTimer) watch(tracer *tracer, d time.Duration) {
+	select {
+	case now := <-t.timer.C():
+		tracer.record(&proto.TraceEntry{
+			Type:     proto.TraceEntry_TIMER_EXPIRED,
+			Duration: int64(d),
+		})
+
+		t.ch <- now
+	case <-t.stopCh:
+	}
+}
+
+// C returns the channel the time is delivered on
+func (t *tracingTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop prevents the timer from firing
+func (t *tracingTimer) Stop() bool {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+
+	return t.timer.Stop()
+}
+
+// tracingBackend records the backend calls, with their arguments and results
+type tracingBackend struct {
+	Backend
+
+	tracer *tracer
+}
+
+// ID returns the validator's ID, which is recorded only the first time
+func (b *tracingBackend) ID() []byte {
+	id := b.Backend.ID()
+
+	b.tracer.idOnce.Do(func() {
+		b.tracer.recordCall(&proto.BackendCall{
+			Method:    methodID,
+			Arguments: &proto.BackendArguments{},
+			Data:      id,
+		})
+	})
+
+	return id
+}
+
+// GetVotingPowers records the voting powers of the validators at the height
+func (b *tracingBackend) GetVotingPowers(height uint64) (map[string]*big.Int, error) {
+	votingPowers, err := b.Backend.GetVotingPowers(height)
+
+	call := &proto.BackendCall{
+		Method:       methodGetVotingPowers,
+		Arguments:    &proto.BackendArguments{Height: height},
+		Error:        errorString(err),
+		VotingPowers: make(map[string][]byte, len(votingPowers)),
+	}
+
+	for address, votingPower := range votingPowers {
+		call.VotingPowers[address] = votingPower.Bytes()
+	}
+
+	b.tracer.recordCall(call)
+
+	return votingPowers, err
+}
+
+// StartRound records the start of the round
+func (b *tracingBackend) StartRound(view *proto.View) error {
+	err := b.Backend.StartRound(view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method:    methodStartRound,
+		Arguments: &proto.BackendArguments{View: view},
+		Error:     errorString(err),
+	})
+
+	return err
+}
+
+// BuildProposal records the proposal built for the view
+func (b *tracingBackend) BuildProposal(view *proto.View) []byte {
+	rawProposal := b.Backend.BuildProposal(view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method:    methodBuildProposal,
+		Arguments: &proto.BackendArguments{View: view},
+		Data:      rawProposal,
+	})
+
+	return rawProposal
+}
+
+// InsertProposal records the inserted proposal, with its committed seals
+func (b *tracingBackend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
+	err := b.Backend.InsertProposal(proposal, committedSeals)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodInsertProposal,
+		Arguments: &proto.BackendArguments{
+			Proposal:       proposal,
+			CommittedSeals: toProtoCommittedSeals(committedSeals),
+		},
+		Error: errorString(err),
+	})
+
+	return err
+}
+
+// ReportEquivocation records the conflicting messages of the evidence
+func (b *tracingBackend) ReportEquivocation(evidence *messages.Evidence) {
+	b.Backend.ReportEquivocation(evidence)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodReportEquivocation,
+		Arguments: &proto.BackendArguments{
+			Messages: []*proto.Message{evidence.First, evidence.Second},
+		},
+	})
+}
+
+// IsValidProposal records the validity of the proposal
+func (b *tracingBackend) IsValidProposal(rawProposal []byte) bool {
+	valid := b.Backend.IsValidProposal(rawProposal)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method:    methodIsValidProposal,
+		Arguments: &proto.BackendArguments{RawProposal: rawProposal},
+		Valid:     valid,
+	})
+
+	return valid
+}
+
+// IsValidValidator records the validity of the message sender
+func (b *tracingBackend) IsValidValidator(msg *proto.Message) bool {
+	valid := b.Backend.IsValidValidator(msg)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method:    methodIsValidValidator,
+		Arguments: &proto.BackendArguments{Message: msg},
+		Valid:     valid,
+	})
+
+	return valid
+}
+
+// IsProposer records if the ID is the proposer for the view
+func (b *tracingBackend) IsProposer(id []byte, height, round uint64) bool {
+	valid := b.Backend.IsProposer(id, height, round)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodIsProposer,
+		Arguments: &proto.BackendArguments{
+			Id:     id,
+			Height: height,
+			Round:  round,
+		},
+		Valid: valid,
+	})
+
+	return valid
+}
+
+// IsValidProposalHash records if the hash matches the proposal
+func (b *tracingBackend) IsValidProposalHash(proposal *proto.Proposal, hash []byte) bool {
+	valid := b.Backend.IsValidProposalHash(proposal, hash)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodIsValidProposalHash,
+		Arguments: &proto.BackendArguments{
+			Proposal:     proposal,
+			ProposalHash: hash,
+		},
+		Valid: valid,
+	})
+
+	return valid
+}
+
+// IsValidCommittedSeal records the validity of the committed seal
+func (b *tracingBackend) IsValidCommittedSeal(proposalHash []byte, committedSeal *messages.CommittedSeal) bool {
+	valid := b.Backend.IsValidCommittedSeal(proposalHash, committedSeal)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodIsValidCommittedSeal,
+		Arguments: &proto.BackendArguments{
+			ProposalHash:   proposalHash,
+			CommittedSeals: toProtoCommittedSeals([]*messages.CommittedSeal{committedSeal}),
+		},
+		Valid: valid,
+	})
+
+	return valid
+}
+
+// BuildPrePrepareMessage records the built PREPREPARE message
+func (b *tracingBackend) BuildPrePrepareMessage(
+	rawProposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	message := b.Backend.BuildPrePrepareMessage(rawProposal, certificate, view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodBuildPrePrepareMessage,
+		Arguments: &proto.BackendArguments{
+			View:                   view,
+			RawProposal:            rawProposal,
+			RoundChangeCertificate: certificate,
+		},
+		Message: message,
+	})
+
+	return message
+}
+
+// BuildPrepareMessage records the built PREPARE message
+func (b *tracingBackend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	message := b.Backend.BuildPrepareMessage(proposalHash, view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodBuildPrepareMessage,
+		Arguments: &proto.BackendArguments{
+			View:         view,
+			ProposalHash: proposalHash,
+		},
+		Message: message,
+	})
+
+	return message
+}
+
+// BuildCommitMessage records the built COMMIT message
+func (b *tracingBackend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	message := b.Backend.BuildCommitMessage(proposalHash, view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodBuildCommitMessage,
+		Arguments: &proto.BackendArguments{
+			View:         view,
+			ProposalHash: proposalHash,
+		},
+		Message: message,
+	})
+
+	return message
+}
+
+// BuildRoundChangeMessage records the built ROUND_CHANGE message
+func (b *tracingBackend) BuildRoundChangeMessage(
+	proposal *proto.Proposal,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	message := b.Backend.BuildRoundChangeMessage(proposal, certificate, view)
+
+	b.tracer.recordCall(&proto.BackendCall{
+		Method: methodBuildRoundChangeMessage,
+		Arguments: &proto.BackendArguments{
+			View:                view,
+			Proposal:            proposal,
+			PreparedCertificate: certificate,
+		},
+		Message: message,
+	})
+
+	return message
+}
+
+// toProtoCommittedSeals converts the committed seals to their protobuf representation
+func toProtoCommittedSeals(committedSeals []*messages.CommittedSeal) []*proto.CommittedSeal {
+	seals := make([]*proto.CommittedSeal, 0, len(committedSeals))
+
+	for _, seal := range committedSeals {
+		seals = append(seals, &proto.CommittedSeal{
+			Signer:    seal.Signer,
+			Signature: seal.Signature,
+		})
+	}
+
+	return seals
+}
+
+// errorString returns the message of the error, or an empty string if it is not set
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// traceError returns the error traced with the message, if any
+func traceError(message string) error {
+	if message == "" {
+		return nil
+	}
+
+	return errors.New(message)
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+var errTraceClosed = errors.New("trace is closed")
+
+var _ TraceRecorder = &FileTraceRecorder{}
+
+// FileTraceRecorder is the TraceRecorder which appends the entries to a file.
+// Like in the FileWAL, every entry is prefixed by its length. The file is not synced
+// after each write, since the trace is used for debugging
+type FileTraceRecorder struct {
+	lock sync.Mutex
+
+	// file is the file the entries are appended to
+	file *os.File
+
+	// closed is the flag indicating if the trace is closed
+	closed bool
+}
+
+// NewFileTraceRecorder creates a new FileTraceRecorder instance, appending the entries
+// to the file at the specified path. The file is created if it does not exist
+func NewFileTraceRecorder(path string) (*FileTraceRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTraceRecorder{
+		file: file,
+	}, nil
+}
+
+// Record appends the entry to the file
+func (r *FileTraceRecorder) Record(entry *proto.TraceEntry) error {
+	frame, err := encodeFrame(entry)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return errTraceClosed
+	}
+
+	_, err = r.file.Write(frame)
+
+	return err
+}
+
+// Close closes the trace file
+func (r *FileTraceRecorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+
+	return r.file.Close()
+}
+
+// ReadTrace reads all the trace entries, in the order they were recorded.
+// A partially written entry at the end of the trace is ignored
+func ReadTrace(reader io.Reader) ([]*proto.TraceEntry, error) {
+	var (
+		bufferedReader = bufio.NewReader(reader)
+		entries        = make([]*proto.TraceEntry, 0)
+	)
+
+	for {
+		entry := &proto.TraceEntry{}
+
+		err := readFrame(bufferedReader, entry)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// ReadTraceFile reads all the trace entries from the file at the specified path
+func ReadTraceFile(path string) ([]*proto.TraceEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ReadTrace(file)
+}
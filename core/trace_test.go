@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func generateTraceEntries() []*proto.TraceEntry {
+	view := &proto.View{
+		Height: 1,
+		Round:  0,
+	}
+
+	return []*proto.TraceEntry{
+		{
+			Type:   proto.TraceEntry_SEQUENCE,
+			Height: 1,
+		},
+		{
+			Type:     proto.TraceEntry_TIMER_STARTED,
+			Offset:   int64(time.Millisecond),
+			Duration: int64(time.Second),
+		},
+		{
+			Type:    proto.TraceEntry_RECEIVED,
+			Offset:  int64(2 * time.Millisecond),
+			Message: buildBasicPrepareMessage(correctRoundMessage.hash, []byte("node 1"), view),
+		},
+		{
+			Type:   proto.TraceEntry_BACKEND,
+			Offset: int64(3 * time.Millisecond),
+			Call: &proto.BackendCall{
+				Method: methodGetVotingPowers,
+				Arguments: &proto.BackendArguments{
+					Height: 1,
+				},
+				VotingPowers: map[string][]byte{
+					"node 1": {1},
+				},
+			},
+		},
+	}
+}
+
+func assertTraceEntries(t *testing.T, expected, actual []*proto.TraceEntry) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+
+	for index, entry := range expected {
+		assert.True(t, protobuf.Equal(entry, actual[index]))
+	}
+}
+
+func TestFileTraceRecorder_RecordRead(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path    = filepath.Join(t.TempDir(), "trace")
+		entries = generateTraceEntries()
+	)
+
+	recorder, err := NewFileTraceRecorder(path)
+	require.NoError(t, err)
+
+	for _, entry := range entries[:2] {
+		require.NoError(t, recorder.Record(entry))
+	}
+
+	require.NoError(t, recorder.Close())
+	assert.ErrorIs(t, recorder.Record(entries[2]), errTraceClosed)
+
+	// Make sure the reopened trace is appended to
+	recorder, err = NewFileTraceRecorder(path)
+	require.NoError(t, err)
+
+	for _, entry := range entries[2:] {
+		require.NoError(t, recorder.Record(entry))
+	}
+
+	require.NoError(t, recorder.Close())
+
+	readEntries, err := ReadTraceFile(path)
+	require.NoError(t, err)
+
+	assertTraceEntries(t, entries, readEntries)
+}
+
+func TestReadTrace_TornWrite(t *testing.T) {
+	t.Parallel()
+
+	var (
+		entries = generateTraceEntries()
+		buf     bytes.Buffer
+	)
+
+	for _, entry := range entries {
+		frame, err := encodeFrame(entry)
+		require.NoError(t, err)
+
+		buf.Write(frame)
+	}
+
+	// Simulate a crash in the middle of the last write
+	torn := buf.Bytes()[:buf.Len()-2]
+
+	readEntries, err := ReadTrace(bytes.NewReader(torn))
+	require.NoError(t, err)
+
+	assertTraceEntries(t, entries[:len(entries)-1], readEntries)
+}
+
+func TestReadTraceFile_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadTraceFile(filepath.Join(t.TempDir(), "missing"))
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMemoryTraceRecorder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		recorder = NewMemoryTraceRecorder()
+		entries  = generateTraceEntries()
+	)
+
+	_, changed := recorder.entriesSince(0)
+
+	require.NoError(t, recorder.Record(entries[0]))
+
+	// Make sure the waiters are notified of the new entry
+	select {
+	case <-changed:
+	default:
+		t.Fatal("entries not changed")
+	}
+
+	for _, entry := range entries[1:] {
+		require.NoError(t, recorder.Record(entry))
+	}
+
+	assertTraceEntries(t, entries, recorder.Entries())
+
+	since, _ := recorder.entriesSince(3)
+	assertTraceEntries(t, entries[3:], since)
+}
+
+func TestTracingClock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock    = NewFakeClock(time.Unix(0, 0))
+		recorder = NewMemoryTraceRecorder()
+		tc       = &tracingClock{
+			Clock:  clock,
+			tracer: newTracer(recorder, clock, mockLogger{}),
+		}
+	)
+
+	expired := tc.NewTimer(time.Second)
+	stopped := tc.NewTimer(2 * time.Second)
+
+	clock.Advance(time.Second)
+	<-expired.C()
+
+	stopped.Stop()
+	clock.Advance(time.Second)
+
+	entries := recorder.Entries()
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, proto.TraceEntry_TIMER_STARTED, entries[0].Type)
+	assert.Equal(t, proto.TraceEntry_TIMER_STARTED, entries[1].Type)
+
+	// Make sure only the expired timer is traced, with its offset
+	assert.Equal(t, proto.TraceEntry_TIMER_EXPIRED, entries[2].Type)
+	assert.Equal(t, int64(time.Second), entries[2].Duration)
+	assert.Equal(t, int64(time.Second), entries[2].Offset)
+}
@@ -8,3 +8,35 @@ type Transport interface {
 	// Multicast multicasts the message to other peers
 	Multicast(message *proto.Message)
 }
+
+// BackpressureHandler is implemented by the transports which can throttle
+// the incoming messages, when the ingress queue of the IBFT instance is saturated
+type BackpressureHandler interface {
+	// OnBackpressure is called with true when the ingress queue becomes full,
+	// and with false once it is drained below half of its capacity.
+	// It must not block, nor submit messages to the IBFT instance
+	OnBackpressure(saturated bool)
+}
+
+// DirectTransport is implemented by the transports which can reach the peers directly,
+// in addition to multicasting the messages. It enables the nodes to request
+// the messages they missed from the peers which have them
+type DirectTransport interface {
+	Transport
+
+	// Unicast sends the message to the peer only
+	Unicast(to []byte, message *proto.Message)
+
+	// RequestMessages sends the request to the peer, which passes it
+	// to HandleMessageRequest of its IBFT instance. It must not block
+	RequestMessages(to []byte, request *MessageRequest)
+}
+
+// MessageRequest is the request for the messages of the type in the view
+type MessageRequest struct {
+	// View is the view of the requested messages
+	View *proto.View
+
+	// MessageType is the type of the requested messages
+	MessageType proto.MessageType
+}
@@ -35,6 +35,10 @@ type ValidatorManager struct {
 	// when the round is above rcMinQuorumThreshold for the height specified in the current View
 	rcMinQuorum *big.Int
 
+	// weakQuorumSize represents the voting power above the faulty threshold,
+	// which guarantees that at least one honest validator is among the senders
+	weakQuorumSize *big.Int
+
 	// validatorsVotingPower is a map of the validator addresses on their voting power for
 	// the height specified in the current View
 	validatorsVotingPower map[string]*big.Int
@@ -49,6 +53,7 @@ func NewValidatorManager(backend ValidatorBackend, log Logger) *ValidatorManager
 	return &ValidatorManager{
 		quorumSize:            big.NewInt(0),
 		rcMinQuorum:           big.NewInt(0),
+		weakQuorumSize:        big.NewInt(0),
 		backend:               backend,
 		validatorsVotingPower: nil,
 		log:                   log,
@@ -80,10 +85,19 @@ func (vm *ValidatorManager) setCurrentVotingPower(validatorsVotingPower map[stri
 	vm.validatorsVotingPower = validatorsVotingPower
 	vm.quorumSize = calculateQuorum(totalVotingPower)
 	vm.rcMinQuorum = calculateRCMinQuorum(totalVotingPower)
+	vm.weakQuorumSize = calculateWeakQuorum(totalVotingPower, vm.quorumSize)
 
 	return nil
 }
 
+// getVotingPowers returns the voting powers of the validators at the current height
+func (vm *ValidatorManager) getVotingPowers() map[string]*big.Int {
+	vm.vpLock.RLock()
+	defer vm.vpLock.RUnlock()
+
+	return vm.validatorsVotingPower
+}
+
 // HasQuorum provides information on whether messages have reached the quorum
 func (vm *ValidatorManager) HasQuorum(sendersAddrs map[string]struct{}) bool {
 	vm.vpLock.RLock()
@@ -94,18 +108,52 @@ func (vm *ValidatorManager) HasQuorum(sendersAddrs map[string]struct{}) bool {
 		return false
 	}
 
-	messageVotePower := big.NewInt(0)
-
-	for from := range sendersAddrs {
-		if vote, ok := vm.validatorsVotingPower[from]; ok {
-			messageVotePower.Add(messageVotePower, vote)
-		}
-	}
+	messageVotePower := calculateSendersVotingPower(vm.validatorsVotingPower, sendersAddrs)
 
 	// aggVotingPower >= (2 * totalVotingPower / 3) + 1
 	return messageVotePower.Cmp(vm.quorumSize) >= 0
 }
 
+// HasWeakQuorum provides information on whether messages have reached the voting power
+// above the faulty threshold, meaning at least one honest validator sent them
+func (vm *ValidatorManager) HasWeakQuorum(sendersAddrs map[string]struct{}) bool {
+	vm.vpLock.RLock()
+	defer vm.vpLock.RUnlock()
+
+	// if not initialized correctly return false
+	if vm.validatorsVotingPower == nil {
+		return false
+	}
+
+	messageVotePower := calculateSendersVotingPower(vm.validatorsVotingPower, sendersAddrs)
+
+	// aggVotingPower >= totalVotingPower - quorumSize + 1
+	return messageVotePower.Cmp(vm.weakQuorumSize) >= 0
+}
+
+// HasVotingPowerShare provides information on whether the senders have
+// at least the specified percentage of the total voting power
+func (vm *ValidatorManager) HasVotingPowerShare(sendersAddrs map[string]struct{}, percentage uint64) bool {
+	vm.vpLock.RLock()
+	defer vm.vpLock.RUnlock()
+
+	// if not initialized correctly return false
+	if vm.validatorsVotingPower == nil {
+		return false
+	}
+
+	messageVotePower := calculateSendersVotingPower(vm.validatorsVotingPower, sendersAddrs)
+
+	// aggVotingPower * 100 >= totalVotingPower * percentage
+	messageVotePower.Mul(messageVotePower, big.NewInt(100))
+	target := new(big.Int).Mul(
+		calculateTotalVotingPower(vm.validatorsVotingPower),
+		new(big.Int).SetUint64(percentage),
+	)
+
+	return messageVotePower.Cmp(target) >= 0
+}
+
 // HasPrepareQuorum provides information on whether prepared messages have reached the quorum
 func (vm *ValidatorManager) HasPrepareQuorum(stateName stateType, proposalMessage *proto.Message,
 	msgs []*proto.Message) bool {
@@ -152,15 +200,21 @@ func (vm *ValidatorManager) HasRoundChangeQuorum(currentRound uint64, sendersAdd
 		return false
 	}
 
-	messageVotePower := big.NewInt(0)
+	messageVotePower := calculateSendersVotingPower(vm.validatorsVotingPower, sendersAddrs)
 
-	for from := range sendersAddrs {
-		if vote, ok := vm.validatorsVotingPower[from]; ok {
-			messageVotePower.Add(messageVotePower, vote)
-		}
+	return messageVotePower.Cmp(vm.rcMinQuorum) >= 0
+}
+
+// hasVotingPowerQuorum checks if the senders reach the quorum of the validator set
+func hasVotingPowerQuorum(votingPowers map[string]*big.Int, sendersAddrs map[string]struct{}) bool {
+	totalVotingPower := calculateTotalVotingPower(votingPowers)
+	if totalVotingPower.Sign() <= 0 {
+		return false
 	}
 
-	return messageVotePower.Cmp(vm.rcMinQuorum) >= 0
+	messageVotePower := calculateSendersVotingPower(votingPowers, sendersAddrs)
+
+	return messageVotePower.Cmp(calculateQuorum(totalVotingPower)) >= 0
 }
 
 // calculateQuorum calculates quorum size which is (614/1000 = 61.4%) + 1 of total voting power.
@@ -187,6 +241,14 @@ func calculateRCMinQuorum(totalVotingPower *big.Int) *big.Int {
 	return quorum.Div(quorum, big.NewInt(100))
 }
 
+// calculateWeakQuorum calculates the weak quorum size, which is the maximum
+// faulty voting power (totalVotingPower - quorum) + 1
+func calculateWeakQuorum(totalVotingPower, quorum *big.Int) *big.Int {
+	weakQuorum := new(big.Int).Sub(totalVotingPower, quorum)
+
+	return weakQuorum.Add(weakQuorum, big.NewInt(1))
+}
+
 func calculateTotalVotingPower(validatorsVotingPower map[string]*big.Int) *big.Int {
 	totalVotingPower := big.NewInt(0)
 	for _, validatorVotingPower := range validatorsVotingPower {
@@ -196,6 +258,19 @@ func calculateTotalVotingPower(validatorsVotingPower map[string]*big.Int) *big.I
 	return totalVotingPower
 }
 
+// calculateSendersVotingPower sums the voting powers of the senders which are in the validator set
+func calculateSendersVotingPower(votingPowers map[string]*big.Int, sendersAddrs map[string]struct{}) *big.Int {
+	sendersVotingPower := big.NewInt(0)
+
+	for from := range sendersAddrs {
+		if vote, ok := votingPowers[from]; ok {
+			sendersVotingPower.Add(sendersVotingPower, vote)
+		}
+	}
+
+	return sendersVotingPower
+}
+
 // convertMessageToAddressSet converts messages slice to addresses map
 func convertMessageToAddressSet(messages []*proto.Message) map[string]struct{} {
 	result := make(map[string]struct{}, len(messages))
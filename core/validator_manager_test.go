@@ -285,6 +285,156 @@ func Test_calculateRCMinQuorum(t *testing.T) {
 	}
 }
 
+func Test_calculateSendersVotingPower(t *testing.T) {
+	t.Parallel()
+
+	votingPowers := map[string]*big.Int{
+		"A": big.NewInt(1),
+		"B": big.NewInt(2),
+		"C": big.NewInt(4),
+	}
+
+	cases := []struct {
+		senders  map[string]struct{}
+		expected *big.Int
+	}{
+		{
+			senders:  map[string]struct{}{},
+			expected: big.NewInt(0),
+		},
+		{
+			senders:  map[string]struct{}{"A": {}, "C": {}},
+			expected: big.NewInt(5),
+		},
+		{
+			senders:  map[string]struct{}{"B": {}, "D": {}},
+			expected: big.NewInt(2),
+		},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.expected, calculateSendersVotingPower(votingPowers, c.senders))
+	}
+}
+
+func Test_HasWeakQuorum(t *testing.T) {
+	t.Parallel()
+
+	vm := &ValidatorManager{
+		vpLock: &sync.RWMutex{},
+	}
+
+	cases := []struct {
+		validatorsVotingPower map[string]*big.Int
+		signers               map[string]struct{}
+		hasWeakQuorum         bool
+	}{
+		{
+			// case total voting power 4 (quorum is 4, weak quorum is 1)
+			validatorsVotingPower: map[string]*big.Int{
+				"A": big.NewInt(1),
+				"B": big.NewInt(1),
+				"C": big.NewInt(1),
+				"D": big.NewInt(1),
+			},
+			signers: map[string]struct{}{
+				"A": {},
+			},
+			hasWeakQuorum: true,
+		},
+		{
+			// case total voting power 40 (quorum is 25, weak quorum is 16)
+			validatorsVotingPower: map[string]*big.Int{
+				"A": big.NewInt(10),
+				"B": big.NewInt(10),
+				"C": big.NewInt(10),
+				"D": big.NewInt(10),
+			},
+			signers: map[string]struct{}{
+				"A": {},
+			},
+			hasWeakQuorum: false,
+		},
+		{
+			// case total voting power 40 (quorum is 25, weak quorum is 16)
+			validatorsVotingPower: map[string]*big.Int{
+				"A": big.NewInt(10),
+				"B": big.NewInt(10),
+				"C": big.NewInt(10),
+				"D": big.NewInt(10),
+			},
+			signers: map[string]struct{}{
+				"A": {},
+				"B": {},
+			},
+			hasWeakQuorum: true,
+		},
+		{
+			// case total voting power 100 (quorum is 62, weak quorum is 39)
+			validatorsVotingPower: map[string]*big.Int{
+				"A": big.NewInt(38),
+				"B": big.NewInt(31),
+				"C": big.NewInt(31),
+			},
+			signers: map[string]struct{}{
+				"A": {},
+				"X": {},
+			},
+			hasWeakQuorum: false,
+		},
+	}
+
+	for _, c := range cases {
+		require.NoError(t, vm.setCurrentVotingPower(c.validatorsVotingPower))
+		require.Equal(t, c.hasWeakQuorum, vm.HasWeakQuorum(c.signers))
+	}
+}
+
+func Test_HasVotingPowerShare(t *testing.T) {
+	t.Parallel()
+
+	vm := &ValidatorManager{
+		vpLock: &sync.RWMutex{},
+	}
+
+	require.NoError(t, vm.setCurrentVotingPower(map[string]*big.Int{
+		"A": big.NewInt(50),
+		"B": big.NewInt(30),
+		"C": big.NewInt(20),
+	}))
+
+	cases := []struct {
+		signers       map[string]struct{}
+		percentage    uint64
+		hasPowerShare bool
+	}{
+		{
+			signers:       map[string]struct{}{"A": {}},
+			percentage:    50,
+			hasPowerShare: true,
+		},
+		{
+			signers:       map[string]struct{}{"B": {}, "C": {}},
+			percentage:    51,
+			hasPowerShare: false,
+		},
+		{
+			signers:       map[string]struct{}{"A": {}, "B": {}, "X": {}},
+			percentage:    100,
+			hasPowerShare: false,
+		},
+		{
+			signers:       map[string]struct{}{"A": {}, "B": {}, "C": {}},
+			percentage:    100,
+			hasPowerShare: true,
+		},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.hasPowerShare, vm.HasVotingPowerShare(c.signers, c.percentage))
+	}
+}
+
 func Test_HasRoundChangeQuorum(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,350 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+const (
+	// maxCachedEpochs is the number of epochs the validator
+	// sets fetched from the backend are cached for
+	maxCachedEpochs = 16
+)
+
+var (
+	// ErrInvalidValidatorSet is returned when a validator set has no voting power
+	ErrInvalidValidatorSet = errors.New("invalid validator set")
+
+	// ErrNotEpochStart is returned when a validator set change
+	// is not effective at the start of an epoch
+	ErrNotEpochStart = errors.New("height is not the start of an epoch")
+
+	// ErrExcessiveChurn is returned when a validator set change
+	// moves more voting power than allowed per epoch
+	ErrExcessiveChurn = errors.New("validator set churn exceeds the limit")
+
+	// ErrEpochStarted is returned when a validator set change
+	// is scheduled for an epoch which is already in use
+	ErrEpochStarted = errors.New("epoch already started")
+
+	// ErrInvalidMaxChurn is returned when the maximum churn is not a percentage
+	ErrInvalidMaxChurn = errors.New("maximum churn must be at most 100")
+)
+
+// ValidatorSets is the epoch-aware source of the validator sets. The validator set only
+// changes at the start of each epoch, so the set of each epoch is fetched from the backend
+// once, and cached for the whole epoch. Changes which are known ahead of time can be
+// scheduled for the start of a future epoch.
+//
+// ValidatorSets implements the ValidatorBackend, so it can be used by the ValidatorManager
+type ValidatorSets struct {
+	lock sync.RWMutex
+
+	backend ValidatorBackend
+
+	// epochSize is the number of heights in an epoch
+	epochSize uint64
+
+	// maxChurn is the percentage of the voting power
+	// which is allowed to change hands per epoch
+	maxChurn uint64
+
+	// cached are the validator sets fetched from the backend, by the epoch start height
+	cached map[uint64]map[string]*big.Int
+
+	// scheduled are the validator set changes, by the epoch start height they are effective at
+	scheduled map[uint64]map[string]*big.Int
+
+	// current is the start height of the latest epoch in use, if any epoch is in use yet.
+	// The validator set of an epoch in use can't be changed anymore
+	current    uint64
+	hasCurrent bool
+}
+
+// NewValidatorSets creates the validator sets for epochs of the specified size, which
+// are fetched from the backend. The maximum churn is the percentage of the voting power
+// which is allowed to change hands between epochs, so 100 means any change is allowed.
+// An epoch size of 0 is treated as 1, meaning the validator set can change on each height
+func NewValidatorSets(backend ValidatorBackend, epochSize, maxChurn uint64) (*ValidatorSets, error) {
+	if maxChurn > 100 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMaxChurn, maxChurn)
+	}
+
+	if epochSize == 0 {
+		epochSize = 1
+	}
+
+	return &ValidatorSets{
+		backend:   backend,
+		epochSize: epochSize,
+		maxChurn:  maxChurn,
+		cached:    make(map[uint64]map[string]*big.Int),
+		scheduled: make(map[uint64]map[string]*big.Int),
+	}, nil
+}
+
+// EpochSize returns the number of heights in an epoch
+func (s *ValidatorSets) EpochSize() uint64 {
+	return s.epochSize
+}
+
+// EpochStart returns the start height of the epoch the height belongs to
+func (s *ValidatorSets) EpochStart(height uint64) uint64 {
+	return height - height%s.epochSize
+}
+
+// GetVotingPowers returns the validator set used for the height. The validator set of
+// the epoch is fetched from the backend, if it is neither scheduled nor cached.
+// The epoch of the height is in use from then on, so its validator set can't be changed.
+// The returned map must not be modified
+func (s *ValidatorSets) GetVotingPowers(height uint64) (map[string]*big.Int, error) {
+	votingPowers, err := s.votingPowers(height)
+	if err != nil {
+		return nil, err
+	}
+
+	epochStart := s.EpochStart(height)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.use(epochStart)
+
+	// The change could have been scheduled before the epoch was marked as in use
+	if scheduled, ok := s.scheduled[epochStart]; ok {
+		return scheduled, nil
+	}
+
+	return votingPowers, nil
+}
+
+// votingPowers returns the validator set used for the height,
+// fetching it from the backend if it is neither scheduled nor cached
+func (s *ValidatorSets) votingPowers(height uint64) (map[string]*big.Int, error) {
+	if votingPowers, ok := s.VotingPowersAt(height); ok {
+		return votingPowers, nil
+	}
+
+	epochStart := s.EpochStart(height)
+
+	votingPowers, err := s.backend.GetVotingPowers(epochStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if calculateTotalVotingPower(votingPowers).Sign() <= 0 {
+		return nil, fmt.Errorf("%w: no voting power at height %d", ErrInvalidValidatorSet, epochStart)
+	}
+
+	votingPowers = copyVotingPowers(votingPowers)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The change could have been scheduled while the backend was queried
+	if scheduled, ok := s.scheduled[epochStart]; ok {
+		return scheduled, nil
+	}
+
+	s.cached[epochStart] = votingPowers
+	s.evict()
+
+	return votingPowers, nil
+}
+
+// VotingPowersAt returns the validator set used for the height, if it is already known,
+// meaning it was either scheduled, or fetched for the epoch. Unlike GetVotingPowers,
+// the backend is never queried, so it is safe to use for future heights.
+// The returned map must not be modified
+func (s *ValidatorSets) VotingPowersAt(height uint64) (map[string]*big.Int, bool) {
+	epochStart := s.EpochStart(height)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if votingPowers, ok := s.scheduled[epochStart]; ok {
+		return votingPowers, true
+	}
+
+	votingPowers, ok := s.cached[epochStart]
+
+	return votingPowers, ok
+}
+
+// ScheduleChange schedules the validator set change effective at the height, which
+// must be the start of an epoch after the current one. The change is validated against
+// the validator sets of the previous and the following epoch, if the following one
+// is scheduled too: the new set must have voting power, and the voting power
+// changing hands must not exceed the maximum churn
+func (s *ValidatorSets) ScheduleChange(height uint64, votingPowers map[string]*big.Int) error {
+	if height%s.epochSize != 0 {
+		return fmt.Errorf("%w: height %d, epoch size %d", ErrNotEpochStart, height, s.epochSize)
+	}
+
+	if err := s.checkNotStarted(height); err != nil {
+		return err
+	}
+
+	if calculateTotalVotingPower(votingPowers).Sign() <= 0 {
+		return fmt.Errorf("%w: no voting power at height %d", ErrInvalidValidatorSet, height)
+	}
+
+	if height > 0 {
+		previous, err := s.votingPowers(height - 1)
+		if err != nil {
+			return err
+		}
+
+		if !isChurnAllowed(previous, votingPowers, s.maxChurn) {
+			return fmt.Errorf("%w: %d%% per epoch", ErrExcessiveChurn, s.maxChurn)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The epoch could have started while the backend was queried
+	if err := s.checkNotStartedLocked(height); err != nil {
+		return err
+	}
+
+	// The change is the previous validator set of the following epoch, if it is scheduled
+	if following, ok := s.scheduled[height+s.epochSize]; ok &&
+		!isChurnAllowed(votingPowers, following, s.maxChurn) {
+		return fmt.Errorf(
+			"%w: %d%% per epoch, to the change at height %d",
+			ErrExcessiveChurn,
+			s.maxChurn,
+			height+s.epochSize,
+		)
+	}
+
+	s.scheduled[height] = copyVotingPowers(votingPowers)
+	delete(s.cached, height)
+
+	return nil
+}
+
+// Prune removes the validator sets of the epochs before the epoch of the height,
+// which is in use from then on, so its validator set can't be changed
+func (s *ValidatorSets) Prune(height uint64) {
+	epochStart := s.EpochStart(height)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.use(epochStart)
+
+	for start := range s.cached {
+		if start < epochStart {
+			delete(s.cached, start)
+		}
+	}
+
+	for start := range s.scheduled {
+		if start < epochStart {
+			delete(s.scheduled, start)
+		}
+	}
+}
+
+// checkNotStarted checks if the epoch starting at the height is after the current epoch
+func (s *ValidatorSets) checkNotStarted(height uint64) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.checkNotStartedLocked(height)
+}
+
+// checkNotStartedLocked checks if the epoch starting at the height
+// is after the current epoch. The caller must hold the lock
+func (s *ValidatorSets) checkNotStartedLocked(height uint64) error {
+	if s.hasCurrent && height <= s.current {
+		return fmt.Errorf("%w: height %d, current epoch start %d", ErrEpochStarted, height, s.current)
+	}
+
+	return nil
+}
+
+// use marks the epoch starting at the height as in use,
+// if it is after the current epoch. The caller must hold the lock
+func (s *ValidatorSets) use(epochStart uint64) {
+	if !s.hasCurrent || epochStart > s.current {
+		s.current, s.hasCurrent = epochStart, true
+	}
+}
+
+// evict removes the validator sets of the lowest epochs fetched from the backend,
+// so at most maxCachedEpochs are cached. The caller must hold the lock
+func (s *ValidatorSets) evict() {
+	for len(s.cached) > maxCachedEpochs {
+		var (
+			lowest uint64
+			found  bool
+		)
+
+		for start := range s.cached {
+			if !found || start < lowest {
+				lowest, found = start, true
+			}
+		}
+
+		delete(s.cached, lowest)
+	}
+}
+
+// isChurnAllowed checks if the voting power changing hands between the validator sets
+// is within the maximum churn percentage of the previous total voting power.
+// The churn is the larger of the voting power added and the voting power removed
+func isChurnAllowed(previous, next map[string]*big.Int, maxChurn uint64) bool {
+	var (
+		added   = big.NewInt(0)
+		removed = big.NewInt(0)
+	)
+
+	for address, votingPower := range next {
+		diff := new(big.Int).Set(votingPower)
+		if previousPower, ok := previous[address]; ok {
+			diff.Sub(diff, previousPower)
+		}
+
+		if diff.Sign() > 0 {
+			added.Add(added, diff)
+		} else {
+			removed.Sub(removed, diff)
+		}
+	}
+
+	for address, votingPower := range previous {
+		if _, ok := next[address]; !ok {
+			removed.Add(removed, votingPower)
+		}
+	}
+
+	churn := added
+	if removed.Cmp(added) > 0 {
+		churn = removed
+	}
+
+	// churn * 100 <= previousTotalVotingPower * maxChurn
+	churn.Mul(churn, big.NewInt(100))
+	limit := new(big.Int).Mul(
+		calculateTotalVotingPower(previous),
+		new(big.Int).SetUint64(maxChurn),
+	)
+
+	return churn.Cmp(limit) <= 0
+}
+
+// copyVotingPowers returns a copy of the voting powers
+func copyVotingPowers(votingPowers map[string]*big.Int) map[string]*big.Int {
+	result := make(map[string]*big.Int, len(votingPowers))
+
+	for address, votingPower := range votingPowers {
+		result[address] = new(big.Int).Set(votingPower)
+	}
+
+	return result
+}
@@ -0,0 +1,357 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// newVotingPowers creates the voting powers of the passed in validators
+func newVotingPowers(votingPowers map[string]int64) map[string]*big.Int {
+	result := make(map[string]*big.Int, len(votingPowers))
+	for address, votingPower := range votingPowers {
+		result[address] = big.NewInt(votingPower)
+	}
+
+	return result
+}
+
+func TestValidatorSets_GetVotingPowers(t *testing.T) {
+	t.Parallel()
+
+	var (
+		queried []uint64
+		backend = mockBackend{
+			getVotingPowerFn: func(height uint64) (map[string]*big.Int, error) {
+				queried = append(queried, height)
+
+				return newVotingPowers(map[string]int64{"A": int64(height) + 1}), nil
+			},
+		}
+	)
+
+	sets, err := NewValidatorSets(backend, 10, 100)
+	require.NoError(t, err)
+
+	_, known := sets.VotingPowersAt(15)
+	assert.False(t, known)
+
+	// Make sure the validator set of the epoch start is used for the whole epoch
+	for height := uint64(10); height < 20; height++ {
+		votingPowers, err := sets.GetVotingPowers(height)
+		require.NoError(t, err)
+
+		assert.Equal(t, newVotingPowers(map[string]int64{"A": 11}), votingPowers)
+	}
+
+	votingPowers, known := sets.VotingPowersAt(15)
+	assert.True(t, known)
+	assert.Equal(t, newVotingPowers(map[string]int64{"A": 11}), votingPowers)
+
+	_, err = sets.GetVotingPowers(20)
+	require.NoError(t, err)
+
+	// Make sure the backend is queried once per epoch
+	assert.Equal(t, []uint64{10, 20}, queried)
+
+	// Make sure the pruned epochs are fetched again
+	sets.Prune(20)
+
+	_, known = sets.VotingPowersAt(10)
+	assert.False(t, known)
+
+	_, known = sets.VotingPowersAt(20)
+	assert.True(t, known)
+}
+
+func TestValidatorSets_GetVotingPowers_Errors(t *testing.T) {
+	t.Parallel()
+
+	errVotingPowers := errors.New("voting powers unavailable")
+
+	sets, err := NewValidatorSets(mockBackend{
+		getVotingPowerFn: func(height uint64) (map[string]*big.Int, error) {
+			if height == 0 {
+				return nil, errVotingPowers
+			}
+
+			return newVotingPowers(map[string]int64{"A": 0}), nil
+		},
+	}, 1, 100)
+	require.NoError(t, err)
+
+	_, err = sets.GetVotingPowers(0)
+	assert.ErrorIs(t, err, errVotingPowers)
+
+	_, err = sets.GetVotingPowers(1)
+	assert.ErrorIs(t, err, ErrInvalidValidatorSet)
+
+	// Make sure the failed lookups are not cached
+	_, known := sets.VotingPowersAt(1)
+	assert.False(t, known)
+}
+
+func TestValidatorSets_Evict(t *testing.T) {
+	t.Parallel()
+
+	sets, err := NewValidatorSets(mockBackend{
+		getVotingPowerFn: testCommonGetVotingPowertFnForCnt(4),
+	}, 0, 100)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), sets.EpochSize())
+
+	for height := uint64(0); height <= maxCachedEpochs; height++ {
+		_, err := sets.GetVotingPowers(height)
+		require.NoError(t, err)
+	}
+
+	// Make sure only the latest epochs are cached
+	_, known := sets.VotingPowersAt(0)
+	assert.False(t, known)
+
+	_, known = sets.VotingPowersAt(maxCachedEpochs)
+	assert.True(t, known)
+}
+
+func TestValidatorSets_ScheduleChange(t *testing.T) {
+	t.Parallel()
+
+	// The total voting power of the previous epochs is 100
+	previous := map[string]int64{"A": 40, "B": 30, "C": 20, "D": 10}
+
+	testTable := []struct {
+		name         string
+		height       uint64
+		votingPowers map[string]int64
+		expectedErr  error
+	}{
+		{
+			"change within the churn limit",
+			10,
+			map[string]int64{"A": 40, "B": 30, "C": 20, "E": 10},
+			nil,
+		},
+		{
+			"voting power added within the churn limit",
+			10,
+			map[string]int64{"A": 40, "B": 30, "C": 20, "D": 20, "E": 5},
+			nil,
+		},
+		{
+			"first epoch",
+			0,
+			map[string]int64{"E": 1},
+			nil,
+		},
+		{
+			"excessive churn",
+			10,
+			map[string]int64{"A": 40, "B": 30, "E": 30},
+			ErrExcessiveChurn,
+		},
+		{
+			"excessive voting power added",
+			10,
+			map[string]int64{"A": 40, "B": 30, "C": 20, "D": 10, "E": 30},
+			ErrExcessiveChurn,
+		},
+		{
+			"no voting power",
+			10,
+			map[string]int64{"A": 0},
+			ErrInvalidValidatorSet,
+		},
+		{
+			"not at the epoch start",
+			15,
+			previous,
+			ErrNotEpochStart,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			sets, err := NewValidatorSets(mockBackend{
+				getVotingPowerFn: func(_ uint64) (map[string]*big.Int, error) {
+					return newVotingPowers(previous), nil
+				},
+			}, 10, 25)
+			require.NoError(t, err)
+
+			err = sets.ScheduleChange(testCase.height, newVotingPowers(testCase.votingPowers))
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			// Make sure the change is used for the epoch, and it is never pruned early
+			sets.Prune(testCase.height)
+
+			votingPowers, known := sets.VotingPowersAt(testCase.height + 9)
+			assert.True(t, known)
+			assert.Equal(t, newVotingPowers(testCase.votingPowers), votingPowers)
+
+			votingPowers, err = sets.GetVotingPowers(testCase.height)
+			require.NoError(t, err)
+			assert.Equal(t, newVotingPowers(testCase.votingPowers), votingPowers)
+		})
+	}
+}
+
+func TestValidatorSets_InvalidMaxChurn(t *testing.T) {
+	t.Parallel()
+
+	sets, err := NewValidatorSets(mockBackend{}, 10, 101)
+
+	assert.ErrorIs(t, err, ErrInvalidMaxChurn)
+	assert.Nil(t, sets)
+}
+
+func TestValidatorSets_ScheduleChange_StartedEpoch(t *testing.T) {
+	t.Parallel()
+
+	sets, err := NewValidatorSets(mockBackend{
+		getVotingPowerFn: testCommonGetVotingPowertFnForCnt(4),
+	}, 10, 100)
+	require.NoError(t, err)
+
+	votingPowers, err := testCommonGetVotingPowertFnForCnt(4)(0)
+	require.NoError(t, err)
+
+	// Make sure the changes are scheduled before any epoch is in use
+	require.NoError(t, sets.ScheduleChange(0, votingPowers))
+
+	_, err = sets.GetVotingPowers(15)
+	require.NoError(t, err)
+
+	// Make sure the current and the previous epochs can't be changed
+	assert.ErrorIs(t, sets.ScheduleChange(0, votingPowers), ErrEpochStarted)
+	assert.ErrorIs(t, sets.ScheduleChange(10, votingPowers), ErrEpochStarted)
+	assert.NoError(t, sets.ScheduleChange(20, votingPowers))
+
+	// Make sure the pruned epochs are in use too
+	sets.Prune(30)
+
+	assert.ErrorIs(t, sets.ScheduleChange(30, votingPowers), ErrEpochStarted)
+	assert.NoError(t, sets.ScheduleChange(40, votingPowers))
+}
+
+func TestValidatorSets_ScheduleChange_FollowingEpoch(t *testing.T) {
+	t.Parallel()
+
+	// The total voting power of the previous epochs is 100
+	previous := map[string]int64{"A": 40, "B": 30, "C": 20, "D": 10}
+
+	sets, err := NewValidatorSets(mockBackend{
+		getVotingPowerFn: func(_ uint64) (map[string]*big.Int, error) {
+			return newVotingPowers(previous), nil
+		},
+	}, 10, 25)
+	require.NoError(t, err)
+
+	// The following epoch replaces D with E
+	following := map[string]int64{"A": 40, "B": 30, "C": 20, "E": 10}
+	require.NoError(t, sets.ScheduleChange(20, newVotingPowers(following)))
+
+	// Make sure the change is rejected if the following epoch
+	// would change too much voting power after it
+	err = sets.ScheduleChange(10, newVotingPowers(map[string]int64{"A": 40, "B": 30, "D": 10, "F": 20}))
+	assert.ErrorIs(t, err, ErrExcessiveChurn)
+
+	votingPowers, _ := sets.VotingPowersAt(10)
+	assert.Equal(t, newVotingPowers(previous), votingPowers)
+
+	// Make sure the change is accepted if both transitions are within the churn limit
+	assert.NoError(t, sets.ScheduleChange(10, newVotingPowers(map[string]int64{"A": 40, "B": 30, "C": 20, "F": 10})))
+}
+
+// TestIBFT_ValidatorSets makes sure the messages of the future heights
+// are validated against the validator sets scheduled for them
+func TestIBFT_ValidatorSets(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes      = generateNodeAddresses(4)
+		newNodes   = generateNodeAddresses(8)[4:]
+		notifiedCh = make(chan uint64, 1)
+	)
+
+	sets, err := NewValidatorSets(mockBackend{
+		getVotingPowerFn: testCommonGetVotingPowertFn(nodes),
+	}, 10, 100)
+	require.NoError(t, err)
+
+	// The validators are replaced in the next epoch
+	nextVotingPowers, err := testCommonGetVotingPowertFn(newNodes)(10)
+	require.NoError(t, err)
+	require.NoError(t, sets.ScheduleChange(10, nextVotingPowers))
+
+	i, err := NewIBFTWithOptions(
+		mockLogger{},
+		mockBackend{
+			idFn: func() []byte {
+				return nodes[0]
+			},
+		},
+		mockTransport{},
+		WithValidatorSets(sets),
+	)
+	require.NoError(t, err)
+
+	i.SetSyncNotifier(mockSyncNotifier{
+		onFutureHeightFn: func(height uint64) {
+			notifiedCh <- height
+		},
+	})
+
+	// Make sure the validator set of the current epoch is fetched through the validator sets
+	require.NoError(t, i.validatorManager.Init(1))
+	assert.Equal(t, sets, i.validatorManager.backend)
+
+	addMessages := func(senders [][]byte, height uint64) {
+		for _, sender := range senders {
+			i.AddMessage(buildBasicPrepareMessage(correctRoundMessage.hash, sender, &proto.View{
+				Height: height,
+				Round:  0,
+			}))
+		}
+	}
+
+	// Make sure the messages of the old validators are rejected for the next epoch
+	addMessages(nodes, 10)
+	assert.Empty(t, i.messages.GetValidMessages(
+		&proto.View{Height: 10},
+		proto.MessageType_PREPARE,
+		func(_ *proto.Message) bool { return true },
+	))
+
+	// Make sure the quorum of the next epoch validators triggers the sync
+	addMessages(newNodes, 10)
+
+	select {
+	case height := <-notifiedCh:
+		assert.Equal(t, uint64(10), height)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync notifier not alerted")
+	}
+
+	assert.Len(t, i.messages.GetValidMessages(
+		&proto.View{Height: 10},
+		proto.MessageType_PREPARE,
+		func(_ *proto.Message) bool { return true },
+	), len(newNodes))
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// WAL is the write-ahead log used for persisting the IBFT state transitions.
+// It allows a restarted node to resume the current height without losing
+// its prepared certificate, and without signing messages that conflict
+// with the ones it has already sent out.
+// Implementations must be safe for concurrent use
+type WAL interface {
+	// Write appends the entry to the log.
+	// The entry must be durably stored before the method returns
+	Write(entry *proto.WALEntry) error
+
+	// Read returns all the entries written for the specified height,
+	// in the order they were written
+	Read(height uint64) ([]*proto.WALEntry, error)
+
+	// PruneByHeight removes all the entries for heights lower than the specified one
+	PruneByHeight(height uint64) error
+}
+
+// SetWAL sets the write-ahead log used by the IBFT state machine
+func (i *IBFT) SetWAL(wal WAL) {
+	i.wal = wal
+}
+
+// writeWAL appends the entry to the write-ahead log, if any.
+// The failure is logged, and returned so the callers don't act on the entry
+func (i *IBFT) writeWAL(entry *proto.WALEntry) error {
+	if i.wal == nil {
+		return nil
+	}
+
+	if err := i.wal.Write(entry); err != nil {
+		i.log.Error("failed to write the WAL entry", "type", entry.Type, "err", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// pruneWAL removes the write-ahead log entries for heights lower than the specified one
+func (i *IBFT) pruneWAL(height uint64) {
+	if i.wal == nil {
+		return
+	}
+
+	if err := i.wal.PruneByHeight(height); err != nil {
+		i.log.Error("failed to prune the WAL", "height", height, "err", err)
+	}
+}
+
+// restoreState rebuilds the state for the specified height from the write-ahead log.
+// The view and the latest prepared certificate are restored, as well as
+// the messages signed for the height, so they are never signed again with
+// a different content. If the proposal of the restored round was accepted,
+// the node resumes the round in the prepare or the commit state, and re-sends its vote
+func (i *IBFT) restoreState(height uint64) error {
+	if i.wal == nil {
+		return nil
+	}
+
+	entries, err := i.wal.Read(height)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var (
+		round = i.state.getRound()
+
+		// proposal is the latest accepted proposal message,
+		// and preparedRound is the round of the latest lock
+		proposal      *proto.Message
+		preparedRound *uint64
+	)
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case proto.WALEntry_VIEW:
+			if entry.View.Round > round {
+				round = entry.View.Round
+			}
+		case proto.WALEntry_PREPARED:
+			i.state.finalizePrepare(
+				entry.LatestPreparedCertificate,
+				entry.LatestPreparedProposal,
+			)
+
+			preparedRound = &entry.View.Round
+		case proto.WALEntry_MESSAGE:
+			i.signedMessages.add(entry.Message)
+
+			if entry.Message.View.Round > round {
+				round = entry.Message.View.Round
+			}
+		case proto.WALEntry_PROPOSAL:
+			proposal = entry.Message
+		}
+	}
+
+	view := &proto.View{
+		Height: height,
+		Round:  round,
+	}
+
+	i.state.setView(view)
+	i.state.changeState(newRound)
+
+	// The proposal of a previous round is not accepted in the restored one
+	if proposal != nil && proposal.View.Round == round {
+		i.state.setProposalMessage(proposal)
+		i.state.setRoundStarted(true)
+
+		if preparedRound != nil && *preparedRound == round {
+			i.state.changeState(commit)
+			i.sendCommitMessage(view)
+		} else {
+			i.state.changeState(prepare)
+			i.sendPrepareMessage(view)
+		}
+	}
+
+	i.log.Info(
+		"state restored from WAL",
+		"height", height,
+		"round", round,
+		"state", i.state.getStateName(),
+		"entries", len(entries),
+	)
+
+	return nil
+}
+
+// multicast persists the message in the write-ahead log,
+// and sends it out to the other peers. The message is not sent if it can't be persisted,
+// since the node could sign a conflicting message after a restart otherwise
+func (i *IBFT) multicast(message *proto.Message) {
+	if message != nil && message.View != nil && i.signedMessages.add(message) {
+		err := i.writeWAL(&proto.WALEntry{
+			Type:    proto.WALEntry_MESSAGE,
+			View:    message.View,
+			Message: message,
+		})
+		if err != nil {
+			// The message was never sent, so it can be signed again
+			i.signedMessages.remove(message)
+
+			return
+		}
+	}
+
+	i.transport.Multicast(message)
+}
+
+// multicastVote multicasts a PREPARE or COMMIT message for the proposal hash.
+// If the node has already voted in the view, the signed vote is re-sent
+// instead of building a new one, and votes for a different proposal are refused
+func (i *IBFT) multicastVote(
+	messageType proto.MessageType,
+	view *proto.View,
+	proposalHash []byte,
+	build func() *proto.Message,
+) {
+	vote := i.signedMessages.get(messageType, view)
+	if vote == nil {
+		i.multicast(build())
+
+		return
+	}
+
+	if !bytes.Equal(messages.ExtractMessageHash(vote), proposalHash) {
+		i.log.Error("refusing to sign a conflicting message", "type", messageType, "round", view.Round)
+
+		return
+	}
+
+	i.transport.Multicast(vote)
+}
+
+// signedMessageKey identifies a message signed by the node
+type signedMessageKey struct {
+	height      uint64
+	round       uint64
+	messageType proto.MessageType
+}
+
+// signedMessages keeps track of the messages the node has signed
+// for the current height. The zero value is ready to use
+type signedMessages struct {
+	sync.RWMutex
+
+	messages map[signedMessageKey]*proto.Message
+}
+
+// reset removes all the tracked messages
+func (s *signedMessages) reset() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.messages = nil
+}
+
+// add tracks the message, and returns true if no message
+// of the same type was tracked for the view
+func (s *signedMessages) add(message *proto.Message) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	key := signedMessageKey{
+		height:      message.View.Height,
+		round:       message.View.Round,
+		messageType: message.Type,
+	}
+
+	if _, exists := s.messages[key]; exists {
+		return false
+	}
+
+	if s.messages == nil {
+		s.messages = make(map[signedMessageKey]*proto.Message)
+	}
+
+	s.messages[key] = message
+
+	return true
+}
+
+// remove stops tracking the message
+func (s *signedMessages) remove(message *proto.Message) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.messages, signedMessageKey{
+		height:      message.View.Height,
+		round:       message.View.Round,
+		messageType: message.Type,
+	})
+}
+
+// get returns the message of the specified type signed for the view, if any
+func (s *signedMessages) get(messageType proto.MessageType, view *proto.View) *proto.Message {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.messages[signedMessageKey{
+		height:      view.Height,
+		round:       view.Round,
+		messageType: messageType,
+	}]
+}
+
+// conflicts checks if a message of the specified type, signed for the view,
+// contains a proposal hash different from the passed in one
+func (s *signedMessages) conflicts(messageType proto.MessageType, view *proto.View, proposalHash []byte) bool {
+	message := s.get(messageType, view)
+	if message == nil {
+		return false
+	}
+
+	return !bytes.Equal(messages.ExtractMessageHash(message), proposalHash)
+}
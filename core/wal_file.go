@@ -0,0 +1,293 @@
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+const (
+	// walFileExtension is the extension of the files created by the FileWAL
+	walFileExtension = ".wal"
+
+	// maxFrameSize is the upper bound for the size of a single WAL or trace entry
+	maxFrameSize = 64 * 1024 * 1024
+)
+
+var (
+	errFrameTooLarge = errors.New("entry is too large")
+	errWALClosed     = errors.New("WAL is closed")
+)
+
+var _ WAL = &FileWAL{}
+
+// FileWAL is the WAL implementation that stores the entries of each height
+// in a separate file inside the configured directory. Every entry is
+// prefixed by its length, and the file is synced after each write
+type FileWAL struct {
+	lock sync.Mutex
+
+	// dir is the directory containing the WAL files
+	dir string
+
+	// file is the file the entries for the current height are appended to
+	file *os.File
+
+	// height is the height of the entries stored in the current file
+	height uint64
+
+	// closed is the flag indicating if the WAL is closed
+	closed bool
+}
+
+// NewFileWAL creates a new FileWAL instance, storing the WAL files
+// in the specified directory
+func NewFileWAL(dir string) (*FileWAL, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileWAL{
+		dir: dir,
+	}, nil
+}
+
+// Write appends the entry to the file of the entry height
+func (w *FileWAL) Write(entry *proto.WALEntry) error {
+	frame, err := encodeFrame(entry)
+	if err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return errWALClosed
+	}
+
+	file, err := w.getFile(entry.View.GetHeight())
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(frame); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// Read returns all the entries written for the specified height.
+// A partially written entry at the end of the file, left by a crash
+// in the middle of a write, is ignored
+func (w *FileWAL) Read(height uint64) ([]*proto.WALEntry, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	file, err := os.Open(w.filePath(height))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var (
+		reader  = bufio.NewReader(file)
+		entries = make([]*proto.WALEntry, 0)
+	)
+
+	for {
+		entry := &proto.WALEntry{}
+
+		err := readFrame(reader, entry)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// A torn write, if any, is ignored
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// PruneByHeight removes the files of all heights lower than the specified one
+func (w *FileWAL) PruneByHeight(height uint64) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	files, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fileHeight, ok := parseWALFileName(file.Name())
+		if !ok || fileHeight >= height {
+			continue
+		}
+
+		if w.file != nil && w.height == fileHeight {
+			if err := w.closeFile(); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Remove(filepath.Join(w.dir, file.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the currently opened WAL file
+func (w *FileWAL) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.closed = true
+
+	return w.closeFile()
+}
+
+// getFile returns the file for the specified height, opening it if needed
+func (w *FileWAL) getFile(height uint64) (*os.File, error) {
+	if w.file != nil && w.height == height {
+		return w.file, nil
+	}
+
+	if err := w.closeFile(); err != nil {
+		return nil, err
+	}
+
+	// The new entries must not be appended after a torn write, or they would be unreadable
+	if err := truncateTornFrame(w.filePath(height)); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(w.filePath(height), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	w.file = file
+	w.height = height
+
+	return file, nil
+}
+
+// truncateTornFrame truncates the file to the end of its last complete entry,
+// dropping the partially written entry left by a crash in the middle of a write, if any
+func truncateTornFrame(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+
+	for offset < len(data) {
+		size, n := binary.Uvarint(data[offset:])
+		if n <= 0 || size > uint64(len(data)-offset-n) {
+			break
+		}
+
+		offset += n + int(size)
+	}
+
+	if offset == len(data) {
+		return nil
+	}
+
+	return os.Truncate(path, int64(offset))
+}
+
+// closeFile closes the currently opened file, if any
+func (w *FileWAL) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// filePath returns the path of the file for the specified height
+func (w *FileWAL) filePath(height uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", height, walFileExtension))
+}
+
+// parseWALFileName extracts the height from the WAL file name
+func parseWALFileName(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, walFileExtension) {
+		return 0, false
+	}
+
+	height, err := strconv.ParseUint(strings.TrimSuffix(name, walFileExtension), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return height, true
+}
+
+// encodeFrame marshals the entry, and prefixes it by its length
+func encodeFrame(entry protobuf.Message) ([]byte, error) {
+	raw, err := protobuf.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(raw)), uint64(len(raw)))
+
+	return append(frame, raw...), nil
+}
+
+// readFrame reads the next length prefixed entry. It returns io.EOF at the end of the input,
+// and io.ErrUnexpectedEOF if the input ends in the middle of the entry
+func readFrame(reader *bufio.Reader, entry protobuf.Message) error {
+	size, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+
+	if size > maxFrameSize {
+		return errFrameTooLarge
+	}
+
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(reader, raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	return protobuf.Unmarshal(raw, entry)
+}
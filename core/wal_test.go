@@ -0,0 +1,546 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func generateWALEntries(height uint64) []*proto.WALEntry {
+	view := &proto.View{
+		Height: height,
+		Round:  1,
+	}
+
+	return []*proto.WALEntry{
+		{
+			Type: proto.WALEntry_VIEW,
+			View: view,
+		},
+		{
+			Type:    proto.WALEntry_MESSAGE,
+			View:    view,
+			Message: buildBasicPrepareMessage(correctRoundMessage.hash, []byte("node 0"), view),
+		},
+		{
+			Type: proto.WALEntry_PREPARED,
+			View: view,
+			LatestPreparedCertificate: &proto.PreparedCertificate{
+				ProposalMessage: buildBasicPreprepareMessage(
+					correctRoundMessage.proposal.RawProposal,
+					correctRoundMessage.hash,
+					nil,
+					[]byte("node 1"),
+					view,
+				),
+				PrepareMessages: []*proto.Message{
+					buildBasicPrepareMessage(correctRoundMessage.hash, []byte("node 0"), view),
+				},
+			},
+			LatestPreparedProposal: correctRoundMessage.proposal,
+		},
+	}
+}
+
+func assertWALEntries(t *testing.T, expected, actual []*proto.WALEntry) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+
+	for index, entry := range expected {
+		assert.True(t, protobuf.Equal(entry, actual[index]))
+	}
+}
+
+func TestFileWAL_WriteRead(t *testing.T) {
+	t.Parallel()
+
+	wal, err := NewFileWAL(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, wal.Close())
+	})
+
+	entriesHeight1 := generateWALEntries(1)
+	entriesHeight2 := generateWALEntries(2)
+
+	for _, entry := range append(entriesHeight1, entriesHeight2...) {
+		require.NoError(t, wal.Write(entry))
+	}
+
+	readEntries, err := wal.Read(1)
+	require.NoError(t, err)
+	assertWALEntries(t, entriesHeight1, readEntries)
+
+	readEntries, err = wal.Read(2)
+	require.NoError(t, err)
+	assertWALEntries(t, entriesHeight2, readEntries)
+
+	// Make sure unknown heights have no entries
+	readEntries, err = wal.Read(3)
+	require.NoError(t, err)
+	assert.Empty(t, readEntries)
+}
+
+func TestFileWAL_TornWrite(t *testing.T) {
+	t.Parallel()
+
+	wal, err := NewFileWAL(t.TempDir())
+	require.NoError(t, err)
+
+	entries := generateWALEntries(1)
+
+	for _, entry := range entries {
+		require.NoError(t, wal.Write(entry))
+	}
+
+	require.NoError(t, wal.Close())
+
+	// Simulate a crash in the middle of a write
+	file, err := os.OpenFile(wal.filePath(1), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	_, err = file.Write([]byte{100, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	readEntries, err := wal.Read(1)
+	require.NoError(t, err)
+	assertWALEntries(t, entries, readEntries)
+}
+
+func TestFileWAL_TornWriteAppend(t *testing.T) {
+	t.Parallel()
+
+	wal, err := NewFileWAL(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, wal.Close())
+	})
+
+	entries := generateWALEntries(1)
+
+	require.NoError(t, wal.Write(entries[0]))
+	require.NoError(t, wal.Close())
+
+	// Simulate a crash in the middle of a write
+	file, err := os.OpenFile(wal.filePath(1), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	_, err = file.Write([]byte{100, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// Make sure the entries written after the restart are readable
+	wal, err = NewFileWAL(wal.dir)
+	require.NoError(t, err)
+
+	for _, entry := range entries[1:] {
+		require.NoError(t, wal.Write(entry))
+	}
+
+	readEntries, err := wal.Read(1)
+	require.NoError(t, err)
+	assertWALEntries(t, entries, readEntries)
+}
+
+func TestFileWAL_PruneByHeight(t *testing.T) {
+	t.Parallel()
+
+	wal, err := NewFileWAL(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, wal.Close())
+	})
+
+	for height := uint64(1); height <= 3; height++ {
+		for _, entry := range generateWALEntries(height) {
+			require.NoError(t, wal.Write(entry))
+		}
+	}
+
+	require.NoError(t, wal.PruneByHeight(3))
+
+	for height := uint64(1); height <= 2; height++ {
+		readEntries, err := wal.Read(height)
+		require.NoError(t, err)
+		assert.Empty(t, readEntries)
+	}
+
+	readEntries, err := wal.Read(3)
+	require.NoError(t, err)
+	assertWALEntries(t, generateWALEntries(3), readEntries)
+
+	// Make sure the WAL is still writable after pruning
+	require.NoError(t, wal.Write(generateWALEntries(4)[0]))
+}
+
+func TestIBFT_RestoreState(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height  = uint64(1)
+		entries = generateWALEntries(height)
+
+		wal = mockWAL{
+			readFn: func(h uint64) ([]*proto.WALEntry, error) {
+				if h == height {
+					return entries, nil
+				}
+
+				return nil, nil
+			},
+		}
+	)
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+	i.SetWAL(wal)
+	i.state.reset(height)
+
+	require.NoError(t, i.restoreState(height))
+
+	// Make sure the view was restored
+	assert.Equal(t, &proto.View{Height: height, Round: 1}, i.state.getView())
+	assert.Equal(t, newRound, i.state.getStateName())
+
+	// Make sure the lock was restored
+	assert.True(t, protobuf.Equal(entries[2].LatestPreparedCertificate, i.state.getLatestPC()))
+	assert.True(t, protobuf.Equal(entries[2].LatestPreparedProposal, i.state.getLatestPreparedProposal()))
+
+	// Make sure the signed messages were restored
+	assert.Equal(t, entries[1].Message, i.signedMessages.get(proto.MessageType_PREPARE, entries[1].View))
+}
+
+func TestIBFT_RestoreState_Proposal(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{
+		Height: 1,
+		Round:  1,
+	}
+
+	proposalEntry := func(round uint64) *proto.WALEntry {
+		proposalView := &proto.View{Height: view.Height, Round: round}
+
+		return &proto.WALEntry{
+			Type: proto.WALEntry_PROPOSAL,
+			View: proposalView,
+			Message: buildBasicPreprepareMessage(
+				correctRoundMessage.proposal.RawProposal,
+				correctRoundMessage.hash,
+				nil,
+				[]byte("node 1"),
+				proposalView,
+			),
+		}
+	}
+
+	testTable := []struct {
+		name          string
+		entries       []*proto.WALEntry
+		expectedState stateType
+		expectedVote  proto.MessageType
+	}{
+		{
+			"accepted proposal is restored in the prepare state",
+			[]*proto.WALEntry{generateWALEntries(view.Height)[0], proposalEntry(view.Round)},
+			prepare,
+			proto.MessageType_PREPARE,
+		},
+		{
+			"prepared proposal is restored in the commit state",
+			append(generateWALEntries(view.Height), proposalEntry(view.Round)),
+			commit,
+			proto.MessageType_COMMIT,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			multicasted := make([]*proto.Message, 0)
+
+			i := NewIBFT(
+				mockLogger{},
+				mockBackend{
+					buildPrepareMessageFn: func(hash []byte, view *proto.View) *proto.Message {
+						return buildBasicPrepareMessage(hash, []byte("node 0"), view)
+					},
+					buildCommitMessageFn: func(hash []byte, view *proto.View) *proto.Message {
+						return buildBasicCommitMessage(hash, nil, []byte("node 0"), view)
+					},
+				},
+				mockTransport{multicastFn: func(message *proto.Message) {
+					multicasted = append(multicasted, message)
+				}},
+			)
+
+			i.SetWAL(mockWAL{
+				readFn: func(_ uint64) ([]*proto.WALEntry, error) {
+					return testCase.entries, nil
+				},
+			})
+			i.state.reset(view.Height)
+
+			require.NoError(t, i.restoreState(view.Height))
+
+			// Make sure the proposal was restored, and the round is resumed
+			assert.Equal(t, view, i.state.getView())
+			assert.Equal(t, testCase.expectedState, i.state.getStateName())
+			assert.True(t, protobuf.Equal(testCase.entries[len(testCase.entries)-1].Message, i.state.getProposalMessage()))
+
+			// Make sure the vote was re-sent, and the round is not started over
+			require.Len(t, multicasted, 1)
+			assert.Equal(t, testCase.expectedVote, multicasted[0].Type)
+
+			i.state.newRound()
+			assert.Equal(t, testCase.expectedState, i.state.getStateName())
+		})
+	}
+
+	t.Run("proposal of a previous round is not restored", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+		i.SetWAL(mockWAL{
+			readFn: func(_ uint64) ([]*proto.WALEntry, error) {
+				return []*proto.WALEntry{proposalEntry(0), generateWALEntries(view.Height)[0]}, nil
+			},
+		})
+		i.state.reset(view.Height)
+
+		require.NoError(t, i.restoreState(view.Height))
+
+		assert.Equal(t, view, i.state.getView())
+		assert.Equal(t, newRound, i.state.getStateName())
+		assert.Nil(t, i.state.getProposalMessage())
+	})
+}
+
+func TestIBFT_ConflictingVotes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+
+		signedHash      = []byte("signed hash")
+		conflictingHash = []byte("conflicting hash")
+		signedPrepare   = buildBasicPrepareMessage(signedHash, []byte("node 0"), view)
+	)
+
+	setup := func(multicastFn func(*proto.Message), buildPrepareFn buildPrepareMessageDelegate) *IBFT {
+		i := NewIBFT(
+			mockLogger{},
+			mockBackend{
+				buildPrepareMessageFn: buildPrepareFn,
+			},
+			mockTransport{multicastFn: multicastFn},
+		)
+
+		i.state.reset(view.Height)
+		i.signedMessages.add(signedPrepare)
+
+		return i
+	}
+
+	t.Run("conflicting vote is refused", func(t *testing.T) {
+		t.Parallel()
+
+		multicasted := make([]*proto.Message, 0)
+
+		i := setup(
+			func(message *proto.Message) {
+				multicasted = append(multicasted, message)
+			},
+			func(_ []byte, _ *proto.View) *proto.Message {
+				t.Fatal("conflicting vote signed")
+
+				return nil
+			},
+		)
+
+		i.state.setProposalMessage(
+			buildBasicPreprepareMessage(validEthereumBlock, conflictingHash, nil, []byte("node 1"), view),
+		)
+		i.sendPrepareMessage(view)
+
+		assert.Empty(t, multicasted)
+	})
+
+	t.Run("signed vote is re-sent", func(t *testing.T) {
+		t.Parallel()
+
+		multicasted := make([]*proto.Message, 0)
+
+		i := setup(
+			func(message *proto.Message) {
+				multicasted = append(multicasted, message)
+			},
+			func(_ []byte, _ *proto.View) *proto.Message {
+				t.Fatal("vote signed twice")
+
+				return nil
+			},
+		)
+
+		i.state.setProposalMessage(
+			buildBasicPreprepareMessage(validEthereumBlock, signedHash, nil, []byte("node 1"), view),
+		)
+		i.sendPrepareMessage(view)
+
+		require.Len(t, multicasted, 1)
+		assert.Equal(t, signedPrepare, multicasted[0])
+	})
+
+	t.Run("conflicting proposal is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		proposals := []*proto.Message{
+			buildBasicPreprepareMessage(validEthereumBlock, conflictingHash, nil, []byte("node 1"), view),
+			buildBasicPreprepareMessage(validEthereumBlock, signedHash, nil, []byte("node 1"), view),
+		}
+
+		i := setup(nil, nil)
+		i.backend = mockBackend{
+			isProposerFn: func(from []byte, _ uint64, _ uint64) bool {
+				return bytes.Equal(from, []byte("node 1"))
+			},
+		}
+		i.messages = mockMessages{
+			getValidMessagesFn: func(
+				_ *proto.View,
+				_ proto.MessageType,
+				isValid func(message *proto.Message) bool,
+			) []*proto.Message {
+				return filterMessages(proposals, isValid)
+			},
+		}
+
+		// Make sure only the proposal the node voted for is accepted
+		assert.Equal(t, proposals[1], i.handlePrePrepare(view))
+	})
+}
+
+func TestIBFT_WALWrites(t *testing.T) {
+	t.Parallel()
+
+	var (
+		lock    sync.Mutex
+		written = make([]*proto.WALEntry, 0)
+
+		view = &proto.View{
+			Height: 1,
+			Round:  2,
+		}
+		prepare = buildBasicPrepareMessage(correctRoundMessage.hash, []byte("node 0"), view)
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			buildPrepareMessageFn: func(_ []byte, _ *proto.View) *proto.Message {
+				return prepare
+			},
+		},
+		mockTransport{multicastFn: func(message *proto.Message) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			// Make sure the message is persisted before it is sent out
+			require.NotEmpty(t, written)
+			assert.Equal(t, message, written[len(written)-1].Message)
+		}},
+	)
+
+	i.SetWAL(mockWAL{
+		writeFn: func(entry *proto.WALEntry) error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			written = append(written, entry)
+
+			return nil
+		},
+	})
+	i.state.reset(view.Height)
+
+	i.moveToNewRound(view.Round)
+	i.state.setProposalMessage(
+		buildBasicPreprepareMessage(validEthereumBlock, correctRoundMessage.hash, nil, []byte("node 1"), view),
+	)
+	i.sendPrepareMessage(view)
+
+	// Make sure re-sending the vote doesn't write it again
+	i.sendPrepareMessage(view)
+
+	require.Len(t, written, 2)
+	assert.Equal(t, proto.WALEntry_VIEW, written[0].Type)
+	assert.Equal(t, view, written[0].View)
+	assert.Equal(t, proto.WALEntry_MESSAGE, written[1].Type)
+	assert.True(t, bytes.Equal(correctRoundMessage.hash, prepareHash(written[1].Message)))
+}
+
+func TestIBFT_WALWriteFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+		prepare  = buildBasicPrepareMessage(correctRoundMessage.hash, []byte("node 0"), view)
+		sent     = 0
+		writeErr = errors.New("disk full")
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{
+			buildPrepareMessageFn: func(_ []byte, _ *proto.View) *proto.Message {
+				return prepare
+			},
+		},
+		mockTransport{multicastFn: func(_ *proto.Message) {
+			sent++
+		}},
+	)
+
+	i.SetWAL(mockWAL{
+		writeFn: func(_ *proto.WALEntry) error {
+			return writeErr
+		},
+	})
+	i.state.reset(view.Height)
+	i.state.setProposalMessage(
+		buildBasicPreprepareMessage(validEthereumBlock, correctRoundMessage.hash, nil, []byte("node 1"), view),
+	)
+
+	i.sendPrepareMessage(view)
+
+	// Make sure the vote is neither sent nor remembered, if it can't be persisted
+	assert.Zero(t, sent)
+	assert.Nil(t, i.signedMessages.get(proto.MessageType_PREPARE, view))
+}
+
+func prepareHash(message *proto.Message) []byte {
+	prepareData, _ := message.Payload.(*proto.Message_PrepareData)
+
+	return prepareData.PrepareData.ProposalHash
+}
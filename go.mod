@@ -4,19 +4,22 @@ go 1.21
 
 require (
 	github.com/armon/go-metrics v0.4.1
-	github.com/golang/protobuf v1.5.0
 	github.com/google/uuid v1.3.0
+	github.com/kilic/bls12-381 v0.1.0
 	github.com/stretchr/testify v1.8.1
 	go.uber.org/goleak v1.2.0
+	golang.org/x/crypto v0.17.0
 	google.golang.org/protobuf v1.28.1
 	pgregory.net/rapid v0.5.3
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.5.0 // indirect
 	github.com/hashicorp/go-immutable-radix v1.0.0 // indirect
 	github.com/hashicorp/golang-lru v0.5.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -52,6 +52,11 @@ type SubscriptionDetails struct {
 	// being subscribed to
 	MinNumMessages int
 
+	// HasQuorumFn is the voting power threshold of the messages
+	// being subscribed to. It is checked for the messages of the signaled round,
+	// until they reach it, and ignored if not set. The messages must not be modified
+	HasQuorumFn func(round uint64, messages []*proto.Message) bool
+
 	// HasMinRound is the flag indicating if the
 	// round number is a lower bound
 	HasMinRound bool
@@ -103,13 +108,35 @@ func (em *eventManager) close() {
 		subscription.close()
 	}
 
+	em.subscriptions = make(map[SubscriptionID]*eventSubscription)
+
 	atomic.StoreInt64(&em.numSubscriptions, 0)
 }
 
-// signalEvent is a helper method for alerting listeners of a new message event
+// isSubscribed checks if any subscription refers to the message type and view
+func (em *eventManager) isSubscribed(messageType proto.MessageType, view *proto.View) bool {
+	if atomic.LoadInt64(&em.numSubscriptions) == 0 {
+		return false
+	}
+
+	em.subscriptionsLock.RLock()
+	defer em.subscriptionsLock.RUnlock()
+
+	for _, subscription := range em.subscriptions {
+		if subscription.matches(messageType, view) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signalEvent is a helper method for alerting listeners of a new message event.
+// The messages are all the messages of the type for the view, which must not be modified
 func (em *eventManager) signalEvent(
 	messageType proto.MessageType,
 	view *proto.View,
+	messages []*proto.Message,
 ) {
 	if atomic.LoadInt64(&em.numSubscriptions) == 0 {
 		// No reason to lock the subscriptions map
@@ -124,6 +151,7 @@ func (em *eventManager) signalEvent(
 		subscription.pushEvent(
 			messageType,
 			view,
+			messages,
 		)
 	}
 }
@@ -49,7 +49,7 @@ func TestEventManager_SubscribeCancel(t *testing.T) {
 
 	go func() {
 		for {
-			em.signalEvent(baseDetails.MessageType, baseDetails.View)
+			em.signalEvent(baseDetails.MessageType, baseDetails.View, make([]*proto.Message, 1))
 
 			select {
 			case <-quitCh:
@@ -103,3 +103,21 @@ func TestEventManager_SubscribeClose(t *testing.T) {
 		}
 	}
 }
+
+func TestEventManager_CloseTwice(t *testing.T) {
+	t.Parallel()
+
+	em := newEventManager()
+	em.subscribe(SubscriptionDetails{
+		MessageType: proto.MessageType_PREPARE,
+		View: &proto.View{
+			Height: 0,
+			Round:  0,
+		},
+	})
+
+	// Make sure the closed subscriptions are not closed again
+	em.close()
+	assert.NotPanics(t, em.close)
+	assert.Empty(t, em.subscriptions)
+}
@@ -1,6 +1,8 @@
 package messages
 
 import (
+	"sync"
+
 	"github.com/Hydra-Chain/go-ibft/messages/proto"
 )
 
@@ -16,6 +18,12 @@ type eventSubscription struct {
 
 	// notifyCh is the channel for receiving event requests
 	notifyCh chan uint64
+
+	// reached are the rounds whose messages already reached the thresholds.
+	// The thresholds are not evaluated again for them, since the subscriber
+	// checks the messages on each notification anyway
+	reached     map[uint64]struct{}
+	reachedLock sync.Mutex
 }
 
 // close stops the event subscription
@@ -41,11 +49,8 @@ func (es *eventSubscription) runLoop() {
 	}
 }
 
-// eventSupported checks if any notification event needs to be triggered
-func (es *eventSubscription) eventSupported(
-	messageType proto.MessageType,
-	view *proto.View,
-) bool {
+// matches checks if the subscription refers to the message type and view
+func (es *eventSubscription) matches(messageType proto.MessageType, view *proto.View) bool {
 	// The heights must match
 	if view.Height != es.details.View.Height {
 		return false
@@ -68,15 +73,58 @@ func (es *eventSubscription) eventSupported(
 	return messageType == es.details.MessageType
 }
 
+// reachesThresholds checks if the messages of the round reach the subscription thresholds
+func (es *eventSubscription) reachesThresholds(round uint64, messages []*proto.Message) bool {
+	// The number of messages must reach the threshold
+	if len(messages) < es.details.MinNumMessages {
+		return false
+	}
+
+	// The voting power of the messages must reach the threshold
+	return es.details.HasQuorumFn == nil || es.details.HasQuorumFn(round, messages)
+}
+
+// hasReached checks if the messages of the round already reached the thresholds
+func (es *eventSubscription) hasReached(round uint64) bool {
+	es.reachedLock.Lock()
+	defer es.reachedLock.Unlock()
+
+	_, ok := es.reached[round]
+
+	return ok
+}
+
+// markReached notes the messages of the round reached the thresholds
+func (es *eventSubscription) markReached(round uint64) {
+	es.reachedLock.Lock()
+	defer es.reachedLock.Unlock()
+
+	if es.reached == nil {
+		es.reached = make(map[uint64]struct{})
+	}
+
+	es.reached[round] = struct{}{}
+}
+
 // pushEvent sends the event off for processing by the subscription. [NON-BLOCKING]
 func (es *eventSubscription) pushEvent(
 	messageType proto.MessageType,
 	view *proto.View,
+	messages []*proto.Message,
 ) {
-	if !es.eventSupported(messageType, view) {
+	if !es.matches(messageType, view) {
 		return
 	}
 
+	// Once the thresholds are reached, each new message only notifies the subscriber
+	if !es.hasReached(view.Round) {
+		if !es.reachesThresholds(view.Round, messages) {
+			return
+		}
+
+		es.markReached(view.Round)
+	}
+
 	select {
 	case es.notifyCh <- view.Round: // Notify the worker thread
 	default:
@@ -8,7 +8,7 @@ import (
 	"github.com/Hydra-Chain/go-ibft/messages/proto"
 )
 
-func TestEventSubscription_EventSupported(t *testing.T) {
+func TestEventSubscription_PushEvent(t *testing.T) {
 	t.Parallel()
 
 	type signalDetails struct {
@@ -132,6 +132,50 @@ func TestEventSubscription_EventSupported(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"Message count below the threshold",
+			commonDetails,
+			signalDetails{
+				commonDetails.MessageType,
+				commonDetails.View,
+				commonDetails.MinNumMessages - 1,
+			},
+			false,
+		},
+		{
+			"Voting power below the threshold",
+			SubscriptionDetails{
+				MessageType:    commonDetails.MessageType,
+				View:           commonDetails.View,
+				MinNumMessages: commonDetails.MinNumMessages,
+				HasQuorumFn: func(_ uint64, messages []*proto.Message) bool {
+					return len(messages) > commonDetails.MinNumMessages
+				},
+			},
+			signalDetails{
+				commonDetails.MessageType,
+				commonDetails.View,
+				commonDetails.MinNumMessages,
+			},
+			false,
+		},
+		{
+			"Voting power threshold reached",
+			SubscriptionDetails{
+				MessageType:    commonDetails.MessageType,
+				View:           commonDetails.View,
+				MinNumMessages: commonDetails.MinNumMessages,
+				HasQuorumFn: func(round uint64, messages []*proto.Message) bool {
+					return round == commonDetails.View.Round && len(messages) > commonDetails.MinNumMessages
+				},
+			},
+			signalDetails{
+				commonDetails.MessageType,
+				commonDetails.View,
+				commonDetails.MinNumMessages + 1,
+			},
+			true,
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -153,14 +197,64 @@ func TestEventSubscription_EventSupported(t *testing.T) {
 
 			event := testCase.event
 
-			assert.Equal(
-				t,
-				testCase.shouldSupport,
-				subscription.eventSupported(
-					event.messageType,
-					event.view,
-				),
+			subscription.pushEvent(
+				event.messageType,
+				event.view,
+				make([]*proto.Message, event.totalMessages),
 			)
+
+			assert.Equal(t, testCase.shouldSupport, len(subscription.notifyCh) == 1)
 		})
 	}
 }
+
+func TestEventSubscription_PushEvent_Reached(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view      = &proto.View{Height: 1, Round: 2}
+		evaluated = 0
+	)
+
+	subscription := &eventSubscription{
+		details: SubscriptionDetails{
+			MessageType:    proto.MessageType_COMMIT,
+			View:           view,
+			MinNumMessages: 2,
+			HasQuorumFn: func(_ uint64, messages []*proto.Message) bool {
+				evaluated++
+
+				return len(messages) >= 3
+			},
+		},
+		outputCh: make(chan uint64, 1),
+		notifyCh: make(chan uint64, 1),
+		doneCh:   make(chan struct{}),
+	}
+
+	t.Cleanup(func() {
+		subscription.close()
+	})
+
+	// Make sure the subscriber is not notified below the thresholds
+	subscription.pushEvent(proto.MessageType_COMMIT, view, make([]*proto.Message, 1))
+	subscription.pushEvent(proto.MessageType_COMMIT, view, make([]*proto.Message, 2))
+
+	assert.Empty(t, subscription.notifyCh)
+	assert.Equal(t, 1, evaluated)
+
+	// Make sure the subscriber is notified once the thresholds are reached
+	subscription.pushEvent(proto.MessageType_COMMIT, view, make([]*proto.Message, 3))
+
+	assert.Equal(t, view.Round, <-subscription.notifyCh)
+	assert.Equal(t, 2, evaluated)
+
+	// Make sure the thresholds are not evaluated again for the round,
+	// while the messages of the other rounds are ignored
+	subscription.pushEvent(proto.MessageType_COMMIT, view, make([]*proto.Message, 4))
+	subscription.pushEvent(proto.MessageType_COMMIT, &proto.View{Height: 1, Round: 3}, make([]*proto.Message, 4))
+
+	assert.Equal(t, view.Round, <-subscription.notifyCh)
+	assert.Empty(t, subscription.notifyCh)
+	assert.Equal(t, 2, evaluated)
+}
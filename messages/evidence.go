@@ -0,0 +1,63 @@
+package messages
+
+import (
+	"bytes"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// Evidence is the proof that a validator signed two conflicting messages,
+// meaning messages of the same type and view for different proposal hashes
+type Evidence struct {
+	// First is the message that was received first from the validator
+	First *proto.Message
+
+	// Second is the message that conflicts with the first one
+	Second *proto.Message
+}
+
+// Signer returns the address of the validator that signed the conflicting messages
+func (e *Evidence) Signer() []byte {
+	return e.First.From
+}
+
+// Type returns the type of the conflicting messages
+func (e *Evidence) Type() proto.MessageType {
+	return e.First.Type
+}
+
+// View returns the view of the conflicting messages
+func (e *Evidence) View() *proto.View {
+	return e.First.View
+}
+
+// IsValid checks if the evidence consists of conflicting messages.
+// The signatures of the messages are not checked, which is up to the caller
+func (e *Evidence) IsValid() bool {
+	if e.First == nil || e.Second == nil {
+		return false
+	}
+
+	return areConflicting(e.First, e.Second)
+}
+
+// areConflicting checks if the messages are of the same type, view and sender,
+// but for different proposal hashes
+func areConflicting(first, second *proto.Message) bool {
+	if first.Type != second.Type || !bytes.Equal(first.From, second.From) {
+		return false
+	}
+
+	if first.View == nil || second.View == nil ||
+		first.View.Height != second.View.Height ||
+		first.View.Round != second.View.Round {
+		return false
+	}
+
+	firstHash, secondHash := ExtractMessageHash(first), ExtractMessageHash(second)
+	if firstHash == nil || secondHash == nil {
+		return false
+	}
+
+	return !bytes.Equal(firstHash, secondHash)
+}
@@ -0,0 +1,104 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func newPrepareMessage(from string, view *proto.View, hash []byte) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: []byte(from),
+		Type: proto.MessageType_PREPARE,
+		Payload: &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: hash,
+			},
+		},
+	}
+}
+
+func TestEvidence_IsValid(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{
+		Height: 1,
+		Round:  1,
+	}
+
+	tests := []struct {
+		name     string
+		evidence *Evidence
+		expected bool
+	}{
+		{
+			name: "conflicting proposal hashes",
+			evidence: &Evidence{
+				First:  newPrepareMessage("node 0", view, []byte("hash 1")),
+				Second: newPrepareMessage("node 0", view, []byte("hash 2")),
+			},
+			expected: true,
+		},
+		{
+			name: "same proposal hashes",
+			evidence: &Evidence{
+				First:  newPrepareMessage("node 0", view, proposalHash),
+				Second: newPrepareMessage("node 0", view, proposalHash),
+			},
+			expected: false,
+		},
+		{
+			name: "different senders",
+			evidence: &Evidence{
+				First:  newPrepareMessage("node 0", view, []byte("hash 1")),
+				Second: newPrepareMessage("node 1", view, []byte("hash 2")),
+			},
+			expected: false,
+		},
+		{
+			name: "different views",
+			evidence: &Evidence{
+				First:  newPrepareMessage("node 0", view, []byte("hash 1")),
+				Second: newPrepareMessage("node 0", &proto.View{Height: 1, Round: 2}, []byte("hash 2")),
+			},
+			expected: false,
+		},
+		{
+			name: "different types",
+			evidence: &Evidence{
+				First: newPrepareMessage("node 0", view, []byte("hash 1")),
+				Second: &proto.Message{
+					View: view,
+					From: []byte("node 0"),
+					Type: proto.MessageType_COMMIT,
+					Payload: &proto.Message_CommitData{
+						CommitData: &proto.CommitMessage{
+							ProposalHash: []byte("hash 2"),
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "missing message",
+			evidence: &Evidence{
+				First: newPrepareMessage("node 0", view, []byte("hash 1")),
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.evidence.IsValid())
+		})
+	}
+}
@@ -18,6 +18,14 @@ type CommittedSeal struct {
 	Signature []byte
 }
 
+// AggregatedSeal is the proof of the validators signing the same digest, like a committed proposal,
+// with a single aggregated signature. The bit i of the bitmap is set if the validator i,
+// in the order of the validator addresses, signed
+type AggregatedSeal struct {
+	Bitmap    []byte
+	Signature []byte
+}
+
 // ExtractCommittedSeals extracts the committed seals from the passed in messages
 func ExtractCommittedSeals(commitMessages []*proto.Message) ([]*CommittedSeal, error) {
 	committedSeals := make([]*CommittedSeal, 0)
@@ -99,6 +107,38 @@ func ExtractPrepareHash(prepareMessage *proto.Message) []byte {
 	return prepareData.PrepareData.ProposalHash
 }
 
+// ExtractPrepareSeal extracts the aggregatable prepare seal
+// from the passed in PREPREPARE or PREPARE message
+func ExtractPrepareSeal(message *proto.Message) []byte {
+	switch message.Type {
+	case proto.MessageType_PREPREPARE:
+		preprepareData, _ := message.Payload.(*proto.Message_PreprepareData)
+
+		return preprepareData.PreprepareData.PrepareSeal
+	case proto.MessageType_PREPARE:
+		prepareData, _ := message.Payload.(*proto.Message_PrepareData)
+
+		return prepareData.PrepareData.PrepareSeal
+	default:
+		return nil
+	}
+}
+
+// ExtractMessageHash extracts the proposal hash from the passed in
+// PREPREPARE, PREPARE or COMMIT message
+func ExtractMessageHash(message *proto.Message) []byte {
+	switch message.Type {
+	case proto.MessageType_PREPREPARE:
+		return ExtractProposalHash(message)
+	case proto.MessageType_PREPARE:
+		return ExtractPrepareHash(message)
+	case proto.MessageType_COMMIT:
+		return ExtractCommitHash(message)
+	default:
+		return nil
+	}
+}
+
 // ExtractLatestPC extracts the latest PC from the passed in message
 func ExtractLatestPC(roundChangeMessage *proto.Message) *proto.PreparedCertificate {
 	if roundChangeMessage.Type != proto.MessageType_ROUND_CHANGE {
@@ -121,6 +161,17 @@ func ExtractLastPreparedProposal(roundChangeMessage *proto.Message) *proto.Propo
 	return rcData.RoundChangeData.LastPreparedProposal
 }
 
+// ExtractRoundChangeSeal extracts the aggregatable round change seal from the passed in message
+func ExtractRoundChangeSeal(roundChangeMessage *proto.Message) []byte {
+	if roundChangeMessage.Type != proto.MessageType_ROUND_CHANGE {
+		return nil
+	}
+
+	rcData, _ := roundChangeMessage.Payload.(*proto.Message_RoundChangeData)
+
+	return rcData.RoundChangeData.RoundChangeSeal
+}
+
 // HasUniqueSenders checks if the messages have unique senders
 func HasUniqueSenders(messages []*proto.Message) bool {
 	if len(messages) < 1 {
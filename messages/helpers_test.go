@@ -131,6 +131,76 @@ func TestMessages_ExtractCommitHash(t *testing.T) {
 	}
 }
 
+func TestMessages_ExtractMessageHash(t *testing.T) {
+	t.Parallel()
+
+	hash := []byte("proposal hash")
+
+	testTable := []struct {
+		name         string
+		expectedHash []byte
+		message      *proto.Message
+	}{
+		{
+			"preprepare message",
+			hash,
+			&proto.Message{
+				Type: proto.MessageType_PREPREPARE,
+				Payload: &proto.Message_PreprepareData{
+					PreprepareData: &proto.PrePrepareMessage{
+						ProposalHash: hash,
+					},
+				},
+			},
+		},
+		{
+			"prepare message",
+			hash,
+			&proto.Message{
+				Type: proto.MessageType_PREPARE,
+				Payload: &proto.Message_PrepareData{
+					PrepareData: &proto.PrepareMessage{
+						ProposalHash: hash,
+					},
+				},
+			},
+		},
+		{
+			"commit message",
+			hash,
+			&proto.Message{
+				Type: proto.MessageType_COMMIT,
+				Payload: &proto.Message_CommitData{
+					CommitData: &proto.CommitMessage{
+						ProposalHash: hash,
+					},
+				},
+			},
+		},
+		{
+			"round change message",
+			nil,
+			&proto.Message{
+				Type: proto.MessageType_ROUND_CHANGE,
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(
+				t,
+				testCase.expectedHash,
+				ExtractMessageHash(testCase.message),
+			)
+		})
+	}
+}
+
 func TestMessages_ExtractProposal(t *testing.T) {
 	t.Parallel()
 
@@ -317,6 +387,110 @@ func TestMessages_ExtractPrepareHash(t *testing.T) {
 	}
 }
 
+func TestMessages_ExtractPrepareSeal(t *testing.T) {
+	t.Parallel()
+
+	prepareSeal := []byte("prepare seal")
+
+	testTable := []struct {
+		name                string
+		expectedPrepareSeal []byte
+		message             *proto.Message
+	}{
+		{
+			"valid prepare message",
+			prepareSeal,
+			&proto.Message{
+				Type: proto.MessageType_PREPARE,
+				Payload: &proto.Message_PrepareData{
+					PrepareData: &proto.PrepareMessage{
+						PrepareSeal: prepareSeal,
+					},
+				},
+			},
+		},
+		{
+			"valid proposal message",
+			prepareSeal,
+			&proto.Message{
+				Type: proto.MessageType_PREPREPARE,
+				Payload: &proto.Message_PreprepareData{
+					PreprepareData: &proto.PrePrepareMessage{
+						PrepareSeal: prepareSeal,
+					},
+				},
+			},
+		},
+		{
+			"invalid message",
+			nil,
+			&proto.Message{
+				Type: proto.MessageType_COMMIT,
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(
+				t,
+				testCase.expectedPrepareSeal,
+				ExtractPrepareSeal(testCase.message),
+			)
+		})
+	}
+}
+
+func TestMessages_ExtractRoundChangeSeal(t *testing.T) {
+	t.Parallel()
+
+	roundChangeSeal := []byte("round change seal")
+
+	testTable := []struct {
+		name                    string
+		expectedRoundChangeSeal []byte
+		message                 *proto.Message
+	}{
+		{
+			"valid message",
+			roundChangeSeal,
+			&proto.Message{
+				Type: proto.MessageType_ROUND_CHANGE,
+				Payload: &proto.Message_RoundChangeData{
+					RoundChangeData: &proto.RoundChangeMessage{
+						RoundChangeSeal: roundChangeSeal,
+					},
+				},
+			},
+		},
+		{
+			"invalid message",
+			nil,
+			&proto.Message{
+				Type: proto.MessageType_PREPARE,
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(
+				t,
+				testCase.expectedRoundChangeSeal,
+				ExtractRoundChangeSeal(testCase.message),
+			)
+		})
+	}
+}
+
 func TestMessages_ExtractLatestPC(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,545 @@
+package messages
+
+import (
+	"crypto/sha256"
+	"sort"
+	"sync"
+
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// Limits bound the memory used by the stored messages,
+// so a flooding sender can't exhaust it. The zero value of each limit disables it
+type Limits struct {
+	// MaxHeightLookahead is the number of heights above the current
+	// height the messages are accepted for
+	MaxHeightLookahead uint64
+
+	// MaxRoundLookahead is the number of rounds above the current round
+	// the messages are accepted for. For the future heights,
+	// it is the number of rounds above round 0
+	MaxRoundLookahead uint64
+
+	// MaxMessagesPerSender is the number of messages of all types
+	// which are stored for each sender, per height
+	MaxMessagesPerSender int
+
+	// MaxBytes is the total size of the stored messages
+	MaxBytes int
+}
+
+// LimitCounters are the numbers of the messages rejected or evicted because of the limits
+type LimitCounters struct {
+	// HeightLookahead is the number of messages rejected for being too many heights ahead
+	HeightLookahead uint64
+
+	// RoundLookahead is the number of messages rejected for being too many rounds ahead
+	RoundLookahead uint64
+
+	// SenderLimit is the number of messages rejected since their sender
+	// reached its limit with messages closer to the current view
+	SenderLimit uint64
+
+	// ByteBudget is the number of messages rejected since the byte budget
+	// was used up by messages closer to the current view
+	ByteBudget uint64
+
+	// Evicted is the number of stored messages evicted in favor
+	// of messages closer to the current view
+	Evicted uint64
+}
+
+// limiter keeps track of the usage of the message store. Its lock
+// must be acquired after the locks of the message types
+type limiter struct {
+	sync.Mutex
+
+	limits Limits
+
+	// height and round are the current view of the state machine
+	height uint64
+	round  uint64
+
+	// bytes is the total size of the stored messages
+	bytes int
+
+	// views are the views of the stored messages, sorted by height and round,
+	// so the messages farthest from the current view are found without a scan
+	views []*storedView
+
+	// senders maps the height -> sender -> stored messages
+	senders map[uint64]map[string][]storedMessage
+
+	counters LimitCounters
+}
+
+// storedMessage is the location of a stored message
+type storedMessage struct {
+	messageType proto.MessageType
+	height      uint64
+	round       uint64
+	sender      string
+}
+
+// storedView are the stored messages of a view
+type storedView struct {
+	height   uint64
+	round    uint64
+	messages map[storedMessage]struct{}
+}
+
+// evictionRank is the order the messages are evicted in: the higher ranks are evicted first
+type evictionRank [3]uint64
+
+// less compares the ranks lexicographically
+func (r evictionRank) less(other evictionRank) bool {
+	for i := range r {
+		if r[i] != other[i] {
+			return r[i] < other[i]
+		}
+	}
+
+	return false
+}
+
+// NewMessagesWithLimits returns a new Messages wrapper, which bounds the memory used
+// by the stored messages. The messages are rejected if they are too far ahead of the
+// current view, set with SetView. If a limit is reached, the stored messages farthest
+// from the current view are evicted in favor of the new messages closer to it:
+// first the messages of the past heights and rounds, then the messages of the future
+// heights, and then the messages of the future rounds of the current height
+func NewMessagesWithLimits(limits Limits) *Messages {
+	ms := NewMessages()
+	ms.limiter = &limiter{
+		limits:  limits,
+		senders: make(map[uint64]map[string][]storedMessage),
+	}
+
+	return ms
+}
+
+// SetView sets the current view of the state machine, which the limits are relative to
+func (ms *Messages) SetView(view *proto.View) {
+	if ms.limiter == nil {
+		return
+	}
+
+	ms.limiter.Lock()
+	defer ms.limiter.Unlock()
+
+	ms.limiter.height = view.Height
+	ms.limiter.round = view.Round
+}
+
+// LimitCounters returns the numbers of the messages rejected or evicted because of the limits
+func (ms *Messages) LimitCounters() LimitCounters {
+	if ms.limiter == nil {
+		return LimitCounters{}
+	}
+
+	ms.limiter.Lock()
+	defer ms.limiter.Unlock()
+
+	return ms.limiter.counters
+}
+
+// addLimitedMessage adds the message, if it is within the limits. Only the lock
+// of the message type is held, unless stored messages need to be evicted
+// to make room for the message, which can be of any type
+func (ms *Messages) addLimitedMessage(message *proto.Message, digest *[sha256.Size]byte) *Evidence {
+	mux := ms.muxMap[message.Type]
+	mux.Lock()
+
+	evidence, needsRoom := ms.storeLimitedMessage(message, digest, false)
+
+	mux.Unlock()
+
+	if !needsRoom {
+		return evidence
+	}
+
+	ms.lockAll()
+	defer ms.unlockAll()
+
+	evidence, _ = ms.storeLimitedMessage(message, digest, true)
+
+	return evidence
+}
+
+// storeLimitedMessage stores the message, if it is within the limits. If the limits
+// are reached, the stored messages farther from the current view are evicted, or
+// if evicting is not allowed, it returns true without storing the message.
+// The caller must hold the lock of the message type, or all the locks to evict
+func (ms *Messages) storeLimitedMessage(
+	message *proto.Message,
+	digest *[sha256.Size]byte,
+	evict bool,
+) (*Evidence, bool) {
+	l := ms.limiter
+
+	l.Lock()
+	defer l.Unlock()
+
+	if !l.isWithinLookahead(message.View) {
+		return nil, false
+	}
+
+	var (
+		size   = protobuf.Size(message)
+		sender = string(message.From)
+	)
+
+	// The same message can be received more than once,
+	// so a message of the sender for the view is only replaced
+	if existing := ms.getProtoMessages(message.View, message.Type)[sender]; existing != nil {
+		if areConflicting(existing, message) {
+			return ms.addEvidence(&Evidence{
+				First:  existing,
+				Second: message,
+			}), false
+		}
+
+		// The replaced message has the same eviction rank,
+		// so it is never evicted to make room for its replacement
+		growth := size - protobuf.Size(existing)
+		if growth > 0 && !l.hasByteRoom(growth) {
+			if !evict {
+				return nil, true
+			}
+
+			if !ms.makeByteRoom(message.View, growth) {
+				l.counters.ByteBudget++
+
+				return nil, false
+			}
+		}
+
+		ms.digests.remove(existing)
+		ms.getMessageMap(message.Type).getViewMessages(message.View)[sender] = message
+		ms.digests.set(message, digest)
+		l.bytes += growth
+
+		return nil, false
+	}
+
+	if !evict && (!l.hasSenderRoom(message.View.Height, sender) || !l.hasByteRoom(size)) {
+		return nil, true
+	}
+
+	if !ms.makeSenderRoom(message) {
+		l.counters.SenderLimit++
+
+		return nil, false
+	}
+
+	if !ms.makeByteRoom(message.View, size) {
+		l.counters.ByteBudget++
+
+		return nil, false
+	}
+
+	ms.getMessageMap(message.Type).getViewMessages(message.View)[sender] = message
+	ms.digests.set(message, digest)
+	ms.signals[message.Type].add(message)
+	l.add(storedMessage{
+		messageType: message.Type,
+		height:      message.View.Height,
+		round:       message.View.Round,
+		sender:      sender,
+	}, size)
+
+	return nil, false
+}
+
+// makeSenderRoom evicts the message of the sender farthest from the current view,
+// if the sender reached its limit for the height. It returns false if the message
+// is not closer to the current view than the stored messages of the sender.
+// The caller must hold all the locks
+func (ms *Messages) makeSenderRoom(message *proto.Message) bool {
+	var (
+		l      = ms.limiter
+		height = message.View.Height
+		sender = string(message.From)
+	)
+
+	if l.hasSenderRoom(height, sender) {
+		return true
+	}
+
+	victim, found := l.senderVictim(height, sender)
+	if !found || !l.rank(message.View).less(l.rank(&proto.View{Height: victim.height, Round: victim.round})) {
+		return false
+	}
+
+	ms.evict(victim)
+
+	return true
+}
+
+// makeByteRoom evicts the messages farthest from the current view, until the message
+// of the specified size fits in the byte budget. It returns false if the message
+// is not closer to the current view than the stored messages.
+// The caller must hold all the locks
+func (ms *Messages) makeByteRoom(view *proto.View, size int) bool {
+	l := ms.limiter
+
+	if l.limits.MaxBytes != 0 && size > l.limits.MaxBytes {
+		return false
+	}
+
+	for !l.hasByteRoom(size) {
+		victim, found := l.victim()
+		if !found || !l.rank(view).less(l.rank(&proto.View{Height: victim.height, Round: victim.round})) {
+			return false
+		}
+
+		ms.evict(victim)
+	}
+
+	return true
+}
+
+// evict removes the stored message. The caller must hold all the locks
+func (ms *Messages) evict(stored storedMessage) {
+	roundMessages := ms.getMessageMap(stored.messageType)[stored.height]
+	messages := roundMessages[stored.round]
+
+	ms.limiter.remove(stored, protobuf.Size(messages[stored.sender]))
+	ms.limiter.counters.Evicted++
+	ms.digests.remove(messages[stored.sender])
+
+	delete(messages, stored.sender)
+	ms.signals[stored.messageType].invalidate(stored.height, stored.round)
+
+	if len(messages) == 0 {
+		delete(roundMessages, stored.round)
+	}
+
+	if len(roundMessages) == 0 {
+		delete(ms.getMessageMap(stored.messageType), stored.height)
+	}
+}
+
+// removeMessage notes the message was removed from the store, if the store is limited
+func (ms *Messages) removeMessage(message *proto.Message) {
+	if ms.limiter == nil {
+		return
+	}
+
+	ms.limiter.Lock()
+	defer ms.limiter.Unlock()
+
+	ms.limiter.remove(storedMessage{
+		messageType: message.Type,
+		height:      message.View.Height,
+		round:       message.View.Round,
+		sender:      string(message.From),
+	}, protobuf.Size(message))
+}
+
+// removeMessages notes the messages of the rounds were removed from the store
+func (ms *Messages) removeMessages(roundMessages roundMessageMap) {
+	if ms.limiter == nil {
+		return
+	}
+
+	for _, messages := range roundMessages {
+		for _, message := range messages {
+			ms.removeMessage(message)
+		}
+	}
+}
+
+// lockAll acquires the locks of all the message types, in order
+func (ms *Messages) lockAll() {
+	for _, messageType := range allMessageTypes() {
+		ms.muxMap[messageType].Lock()
+	}
+}
+
+// unlockAll releases the locks of all the message types
+func (ms *Messages) unlockAll() {
+	for _, messageType := range allMessageTypes() {
+		ms.muxMap[messageType].Unlock()
+	}
+}
+
+// isWithinLookahead checks if the view is not too far ahead of the
+// current view, and counts the rejection if it is
+func (l *limiter) isWithinLookahead(view *proto.View) bool {
+	if l.limits.MaxHeightLookahead > 0 && view.Height > l.height+l.limits.MaxHeightLookahead {
+		l.counters.HeightLookahead++
+
+		return false
+	}
+
+	baseRound := uint64(0)
+	if view.Height == l.height {
+		baseRound = l.round
+	}
+
+	if l.limits.MaxRoundLookahead > 0 && view.Round > baseRound+l.limits.MaxRoundLookahead {
+		l.counters.RoundLookahead++
+
+		return false
+	}
+
+	return true
+}
+
+// rank returns the eviction rank of the view, relative to the current view
+func (l *limiter) rank(view *proto.View) evictionRank {
+	switch {
+	case view.Height < l.height:
+		// The past heights are evicted first
+		return evictionRank{3, l.height - view.Height, view.Round}
+	case view.Height == l.height && view.Round < l.round:
+		// Then the past rounds of the current height
+		return evictionRank{2, l.round - view.Round, 0}
+	case view.Height > l.height:
+		// Then the future heights, the farthest first
+		return evictionRank{1, view.Height, view.Round}
+	default:
+		// The current and future rounds of the current height are evicted last
+		return evictionRank{0, view.Round, 0}
+	}
+}
+
+// hasSenderRoom checks if the sender can store another message for the height
+func (l *limiter) hasSenderRoom(height uint64, sender string) bool {
+	return l.limits.MaxMessagesPerSender == 0 || len(l.senders[height][sender]) < l.limits.MaxMessagesPerSender
+}
+
+// hasByteRoom checks if the messages of the specified size fit in the byte budget
+func (l *limiter) hasByteRoom(size int) bool {
+	return l.limits.MaxBytes == 0 || l.bytes+size <= l.limits.MaxBytes
+}
+
+// victim returns the stored message farthest from the current view, if any
+func (l *limiter) victim() (storedMessage, bool) {
+	if len(l.views) == 0 {
+		return storedMessage{}, false
+	}
+
+	var (
+		first = l.views[0]
+		view  = l.views[len(l.views)-1]
+	)
+
+	switch {
+	case first.height < l.height:
+		// The highest round of the lowest past height
+		index := sort.Search(len(l.views), func(i int) bool {
+			return l.views[i].height > first.height
+		})
+		view = l.views[index-1]
+	case first.height == l.height && first.round < l.round:
+		// The lowest past round of the current height
+		view = first
+	}
+
+	// Otherwise the highest future height, or the highest round of the current height
+	for stored := range view.messages {
+		return stored, true
+	}
+
+	return storedMessage{}, false
+}
+
+// senderVictim returns the stored message of the sender
+// at the height farthest from the current view, if any
+func (l *limiter) senderVictim(height uint64, sender string) (storedMessage, bool) {
+	var (
+		victim     storedMessage
+		victimRank evictionRank
+		found      bool
+	)
+
+	for _, stored := range l.senders[height][sender] {
+		rank := l.rank(&proto.View{Height: stored.height, Round: stored.round})
+		if !found || victimRank.less(rank) {
+			victim, victimRank, found = stored, rank, true
+		}
+	}
+
+	return victim, found
+}
+
+// findView returns the index of the view in the sorted views,
+// or the index it would be inserted at, if there are no messages for it
+func (l *limiter) findView(height, round uint64) (int, bool) {
+	index := sort.Search(len(l.views), func(i int) bool {
+		view := l.views[i]
+
+		return view.height > height || (view.height == height && view.round >= round)
+	})
+
+	found := index < len(l.views) && l.views[index].height == height && l.views[index].round == round
+
+	return index, found
+}
+
+// add notes the stored message
+func (l *limiter) add(stored storedMessage, size int) {
+	index, found := l.findView(stored.height, stored.round)
+	if !found {
+		l.views = append(l.views, nil)
+		copy(l.views[index+1:], l.views[index:])
+		l.views[index] = &storedView{
+			height:   stored.height,
+			round:    stored.round,
+			messages: make(map[storedMessage]struct{}),
+		}
+	}
+
+	l.views[index].messages[stored] = struct{}{}
+
+	senders, ok := l.senders[stored.height]
+	if !ok {
+		senders = make(map[string][]storedMessage)
+		l.senders[stored.height] = senders
+	}
+
+	senders[stored.sender] = append(senders[stored.sender], stored)
+	l.bytes += size
+}
+
+// remove notes the removed message
+func (l *limiter) remove(stored storedMessage, size int) {
+	l.bytes -= size
+
+	if index, found := l.findView(stored.height, stored.round); found {
+		delete(l.views[index].messages, stored)
+
+		if len(l.views[index].messages) == 0 {
+			l.views = append(l.views[:index], l.views[index+1:]...)
+		}
+	}
+
+	senders := l.senders[stored.height]
+	if senders == nil {
+		return
+	}
+
+	messages := senders[stored.sender]
+	for i := range messages {
+		if messages[i] == stored {
+			messages[i] = messages[len(messages)-1]
+			messages = messages[:len(messages)-1]
+
+			break
+		}
+	}
+
+	senders[stored.sender] = messages
+
+	if len(messages) == 0 {
+		delete(senders, stored.sender)
+	}
+
+	if len(senders) == 0 {
+		delete(l.senders, stored.height)
+	}
+}
@@ -0,0 +1,241 @@
+package messages
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// newViewMessage creates a PREPARE message of the sender for the height and round
+func newViewMessage(sender string, height, round uint64) *proto.Message {
+	return newPrepareMessage(sender, &proto.View{Height: height, Round: round}, []byte("proposal hash"))
+}
+
+// countMessages returns the number of the stored PREPARE messages for the view
+func countMessages(ms *Messages, height, round uint64) int {
+	return ms.numMessages(&proto.View{Height: height, Round: round}, proto.MessageType_PREPARE)
+}
+
+func TestMessages_Limits_Lookahead(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMessagesWithLimits(Limits{
+		MaxHeightLookahead: 10,
+		MaxRoundLookahead:  5,
+	})
+	defer ms.Close()
+
+	ms.SetView(&proto.View{Height: 10, Round: 3})
+
+	ms.AddMessage(newViewMessage("node", 20, 0))
+	ms.AddMessage(newViewMessage("node", 21, 0))
+	ms.AddMessage(newViewMessage("node", 10, 8))
+	ms.AddMessage(newViewMessage("node", 10, 9))
+	ms.AddMessage(newViewMessage("node", 11, 5))
+	ms.AddMessage(newViewMessage("node", 11, 6))
+
+	assert.Equal(t, 1, countMessages(ms, 20, 0))
+	assert.Equal(t, 1, countMessages(ms, 10, 8))
+	assert.Equal(t, 1, countMessages(ms, 11, 5))
+
+	// Make sure the messages too far ahead are rejected, and counted
+	assert.Equal(t, 0, countMessages(ms, 21, 0))
+	assert.Equal(t, 0, countMessages(ms, 10, 9))
+	assert.Equal(t, 0, countMessages(ms, 11, 6))
+	assert.Equal(t, LimitCounters{HeightLookahead: 1, RoundLookahead: 2}, ms.LimitCounters())
+}
+
+func TestMessages_Limits_FloodingSender(t *testing.T) {
+	t.Parallel()
+
+	const limit = 8
+
+	ms := NewMessagesWithLimits(Limits{MaxMessagesPerSender: limit})
+	defer ms.Close()
+
+	ms.SetView(&proto.View{Height: 1, Round: 0})
+
+	// The byzantine sender floods the store with messages for the future rounds
+	for round := uint64(0); round < 10000; round++ {
+		ms.AddMessage(newViewMessage("byzantine", 1, round))
+	}
+
+	// Make sure only the rounds closest to the current one are kept
+	for round := uint64(0); round < 10000; round++ {
+		expected := 0
+		if round < limit {
+			expected = 1
+		}
+
+		require.Equal(t, expected, countMessages(ms, 1, round))
+	}
+
+	assert.Equal(t, LimitCounters{SenderLimit: 10000 - limit}, ms.LimitCounters())
+
+	// Make sure the other senders are not affected
+	ms.AddMessage(newViewMessage("honest", 1, 0))
+	assert.Equal(t, 2, countMessages(ms, 1, 0))
+
+	// Make sure the messages of the past rounds are evicted
+	// once the state machine moves to a higher round
+	ms.SetView(&proto.View{Height: 1, Round: 5})
+	ms.AddMessage(newViewMessage("byzantine", 1, 8))
+
+	assert.Nil(t, ms.getProtoMessages(&proto.View{Height: 1, Round: 0}, proto.MessageType_PREPARE)["byzantine"])
+	assert.Equal(t, 1, countMessages(ms, 1, 8))
+	assert.Equal(t, uint64(1), ms.LimitCounters().Evicted)
+}
+
+func TestMessages_Limits_ByteBudget(t *testing.T) {
+	t.Parallel()
+
+	size := protobuf.Size(newViewMessage("node 0", 1, 0))
+
+	ms := NewMessagesWithLimits(Limits{MaxBytes: 4 * size})
+	defer ms.Close()
+
+	ms.SetView(&proto.View{Height: 1, Round: 0})
+
+	// Fill the budget with the messages of the future heights
+	for height := uint64(2); height < 6; height++ {
+		ms.AddMessage(newViewMessage("node 0", height, 0))
+	}
+
+	// Make sure the messages farther from the current view are rejected
+	ms.AddMessage(newViewMessage("node 0", 6, 0))
+	assert.Equal(t, 0, countMessages(ms, 6, 0))
+	assert.Equal(t, uint64(1), ms.LimitCounters().ByteBudget)
+
+	// Make sure the messages of the current view evict the farthest messages
+	ms.AddMessage(newViewMessage("node 0", 1, 0))
+	ms.AddMessage(newViewMessage("node 1", 1, 0))
+
+	assert.Equal(t, 2, countMessages(ms, 1, 0))
+	assert.Equal(t, 1, countMessages(ms, 2, 0))
+	assert.Equal(t, 1, countMessages(ms, 3, 0))
+	assert.Equal(t, 0, countMessages(ms, 4, 0))
+	assert.Equal(t, 0, countMessages(ms, 5, 0))
+	assert.Equal(t, uint64(2), ms.LimitCounters().Evicted)
+
+	// Make sure the budget is released when the messages are removed
+	ms.GetValidMessages(&proto.View{Height: 1, Round: 0}, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return false
+	})
+	ms.PruneByHeight(10)
+
+	assert.Zero(t, ms.limiter.bytes)
+	assert.Empty(t, ms.limiter.senders)
+}
+
+func TestMessages_Limits_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMessagesWithLimits(Limits{MaxMessagesPerSender: 1})
+	defer ms.Close()
+
+	message := newViewMessage("node", 1, 0)
+	conflicting := newPrepareMessage("node", message.View, []byte("other hash"))
+
+	// Make sure the duplicates don't count towards the limits,
+	// and the conflicting messages are still detected
+	assert.Nil(t, ms.AddMessage(message))
+	assert.Nil(t, ms.AddMessage(message))
+	assert.NotNil(t, ms.AddMessage(conflicting))
+
+	assert.Equal(t, 1, countMessages(ms, 1, 0))
+	assert.Equal(t, LimitCounters{}, ms.LimitCounters())
+}
+
+func TestMessages_Limits_Replace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		message = newViewMessage("node 0", 1, 0)
+		size    = protobuf.Size(message)
+	)
+
+	// withSignature returns the duplicate of the message with the signature of the length
+	withSignature := func(length int) *proto.Message {
+		duplicate, _ := protobuf.Clone(message).(*proto.Message)
+		duplicate.Signature = make([]byte, length)
+
+		return duplicate
+	}
+
+	ms := NewMessagesWithLimits(Limits{MaxBytes: 2*size + 10})
+	defer ms.Close()
+
+	ms.SetView(&proto.View{Height: 1, Round: 0})
+
+	ms.AddMessage(message)
+	ms.AddMessage(newViewMessage("node 1", 3, 0))
+
+	// Make sure the larger duplicate evicts the messages farther from the current view
+	larger := withSignature(20)
+
+	assert.Nil(t, ms.AddMessage(larger))
+	assert.Equal(t, 0, countMessages(ms, 3, 0))
+	assert.Equal(t, larger, ms.getProtoMessages(message.View, proto.MessageType_PREPARE)["node 0"])
+	assert.Equal(t, protobuf.Size(larger), ms.limiter.bytes)
+	assert.Equal(t, uint64(1), ms.LimitCounters().Evicted)
+
+	// Make sure the duplicate is rejected if it doesn't fit in the budget
+	assert.Nil(t, ms.AddMessage(withSignature(2*size)))
+	assert.Equal(t, larger, ms.getProtoMessages(message.View, proto.MessageType_PREPARE)["node 0"])
+	assert.Equal(t, protobuf.Size(larger), ms.limiter.bytes)
+	assert.Equal(t, uint64(1), ms.LimitCounters().ByteBudget)
+
+	ms.PruneByHeight(10)
+
+	assert.Zero(t, ms.limiter.bytes)
+	assert.Empty(t, ms.limiter.views)
+	assert.Empty(t, ms.limiter.senders)
+}
+
+func TestMessages_Limits_EvictionOrder(t *testing.T) {
+	t.Parallel()
+
+	// The rounds are not 0, so all the messages have the same size
+	size := protobuf.Size(newViewMessage("node 0", 1, 1))
+
+	ms := NewMessagesWithLimits(Limits{MaxBytes: 6 * size})
+	defer ms.Close()
+
+	ms.SetView(&proto.View{Height: 5, Round: 2})
+
+	stored := []*proto.View{
+		{Height: 4, Round: 1},
+		{Height: 4, Round: 2},
+		{Height: 5, Round: 1},
+		{Height: 5, Round: 3},
+		{Height: 6, Round: 1},
+		{Height: 7, Round: 1},
+	}
+
+	for _, view := range stored {
+		ms.AddMessage(newViewMessage("node 0", view.Height, view.Round))
+	}
+
+	// Make sure the messages are evicted from the farthest, one per message of the current view
+	expected := []*proto.View{
+		{Height: 4, Round: 2},
+		{Height: 4, Round: 1},
+		{Height: 5, Round: 1},
+		{Height: 7, Round: 1},
+		{Height: 6, Round: 1},
+	}
+
+	for index, view := range expected {
+		ms.AddMessage(newViewMessage(fmt.Sprintf("node %d", index+1), 5, 2))
+
+		require.Equal(t, 0, countMessages(ms, view.Height, view.Round), "view %v", view)
+	}
+
+	assert.Equal(t, 1, countMessages(ms, 5, 3))
+	assert.Equal(t, uint64(len(expected)), ms.LimitCounters().Evicted)
+}
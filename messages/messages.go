@@ -1,7 +1,11 @@
 package messages
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/Hydra-Chain/go-ibft/messages/proto"
 )
@@ -19,11 +23,65 @@ type Messages struct {
 	prepareMessages,
 	commitMessages,
 	roundChangeMessages heightMessageMap
+
+	// evidence maps the height -> proofs of the validators
+	// that signed conflicting messages at that height
+	evidence     map[uint64][]*Evidence
+	evidenceLock sync.RWMutex
+
+	// limiter bounds the stored messages, if the limits are set
+	limiter *limiter
+
+	// validations caches the message validation results, by the validation context
+	validations validationCache
+
+	// digests are the digests of the stored messages, the validation results are cached by
+	digests messageDigests
+
+	// signals are the lists of the messages the subscribers are signaled with,
+	// by the message type. They are protected by the locks of the message types
+	signals map[proto.MessageType]signalLists
 }
 
-// Subscribe creates a new message type subscription
+// Subscribe creates a new message type subscription.
+// The subscriber is notified right away if the stored messages
+// already reach the subscription thresholds
 func (ms *Messages) Subscribe(details SubscriptionDetails) *Subscription {
-	return ms.eventManager.subscribe(details)
+	subscription := ms.eventManager.subscribe(details)
+
+	for _, round := range ms.subscribedRounds(details) {
+		ms.SignalEvent(details.MessageType, &proto.View{
+			Height: details.View.Height,
+			Round:  round,
+		})
+	}
+
+	return subscription
+}
+
+// subscribedRounds returns the rounds of the stored messages the subscription refers to
+func (ms *Messages) subscribedRounds(details SubscriptionDetails) []uint64 {
+	if !details.HasMinRound {
+		return []uint64{details.View.Round}
+	}
+
+	mux := ms.muxMap[details.MessageType]
+	mux.RLock()
+	defer mux.RUnlock()
+
+	rounds := make([]uint64, 0)
+
+	for round := range ms.getMessageMap(details.MessageType)[details.View.Height] {
+		if round >= details.View.Round {
+			rounds = append(rounds, round)
+		}
+	}
+
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i] < rounds[j]
+	})
+
+	return rounds
 }
 
 // Unsubscribe cancels a message type subscription
@@ -39,6 +97,8 @@ func NewMessages() *Messages {
 		commitMessages:      make(heightMessageMap),
 		roundChangeMessages: make(heightMessageMap),
 
+		evidence: make(map[uint64][]*Evidence),
+
 		eventManager: newEventManager(),
 
 		muxMap: map[proto.MessageType]*sync.RWMutex{
@@ -47,11 +107,46 @@ func NewMessages() *Messages {
 			proto.MessageType_COMMIT:       {},
 			proto.MessageType_ROUND_CHANGE: {},
 		},
+
+		signals: map[proto.MessageType]signalLists{
+			proto.MessageType_PREPREPARE:   {},
+			proto.MessageType_PREPARE:      {},
+			proto.MessageType_COMMIT:       {},
+			proto.MessageType_ROUND_CHANGE: {},
+		},
 	}
 }
 
-// AddMessage adds a new message to the message queue
-func (ms *Messages) AddMessage(message *proto.Message) {
+// AddMessage adds a new message to the message queue.
+// If the sender already sent a conflicting message for the same view,
+// the first message is kept, and the evidence of the conflict is returned.
+// The evidence is returned only once per sender, type and view.
+// The subscribers whose thresholds are reached by the message are notified
+func (ms *Messages) AddMessage(message *proto.Message) *Evidence {
+	var (
+		evidence *Evidence
+
+		// The digest is computed once, instead of on each cached validation of the message
+		digest *[sha256.Size]byte
+	)
+
+	if messageDigest, err := Digest(message); err == nil {
+		digest = &messageDigest
+	}
+
+	if ms.limiter != nil {
+		evidence = ms.addLimitedMessage(message, digest)
+	} else {
+		evidence = ms.addMessage(message, digest)
+	}
+
+	ms.SignalEvent(message.Type, message.View)
+
+	return evidence
+}
+
+// addMessage adds a new message to the message queue, without the limits
+func (ms *Messages) addMessage(message *proto.Message, digest *[sha256.Size]byte) *Evidence {
 	mux := ms.muxMap[message.Type]
 	mux.Lock()
 	defer mux.Unlock()
@@ -61,14 +156,112 @@ func (ms *Messages) AddMessage(message *proto.Message) {
 
 	// Append the message to the appropriate queue
 	messages := heightMsgMap.getViewMessages(message.View)
+
+	// Make sure the sender didn't already sign a conflicting message
+	existing, ok := messages[string(message.From)]
+	if ok && areConflicting(existing, message) {
+		return ms.addEvidence(&Evidence{
+			First:  existing,
+			Second: message,
+		})
+	}
+
+	if ok {
+		ms.digests.remove(existing)
+	}
+
 	messages[string(message.From)] = message
+	ms.digests.set(message, digest)
+
+	if !ok {
+		ms.signals[message.Type].add(message)
+	}
+
+	return nil
+}
+
+// addEvidence stores the evidence, and returns it
+// if there is no evidence for the same sender, type and view
+func (ms *Messages) addEvidence(evidence *Evidence) *Evidence {
+	ms.evidenceLock.Lock()
+	defer ms.evidenceLock.Unlock()
+
+	height := evidence.View().Height
+
+	for _, existing := range ms.evidence[height] {
+		if existing.Type() == evidence.Type() &&
+			existing.View().Round == evidence.View().Round &&
+			bytes.Equal(existing.Signer(), evidence.Signer()) {
+			return nil
+		}
+	}
+
+	ms.evidence[height] = append(ms.evidence[height], evidence)
+
+	return evidence
+}
+
+// GetEvidence returns the proofs of the validators
+// that signed conflicting messages at the specified height
+func (ms *Messages) GetEvidence(height uint64) []*Evidence {
+	ms.evidenceLock.RLock()
+	defer ms.evidenceLock.RUnlock()
+
+	evidence := make([]*Evidence, len(ms.evidence[height]))
+	copy(evidence, ms.evidence[height])
+
+	return evidence
+}
+
+// NumSubscriptions returns the number of active subscriptions
+func (ms *Messages) NumSubscriptions() int64 {
+	return atomic.LoadInt64(&ms.eventManager.numSubscriptions)
 }
 
-// SignalEvent signals event
+// SignalEvent notifies the subscribers of the message type and view,
+// if the stored messages reach their thresholds. It is called by AddMessage,
+// so it only needs to be called when the thresholds could be reached otherwise
 func (ms *Messages) SignalEvent(messageType proto.MessageType, view *proto.View) {
-	ms.eventManager.signalEvent(messageType, &proto.View{
-		Height: view.Height,
-		Round:  view.Round})
+	if !ms.eventManager.isSubscribed(messageType, view) {
+		// No reason to collect the messages
+		// if no subscriptions refer to them
+		return
+	}
+
+	ms.eventManager.signalEvent(
+		messageType,
+		&proto.View{
+			Height: view.Height,
+			Round:  view.Round,
+		},
+		ms.getSignalMessages(view, messageType),
+	)
+}
+
+// getSignalMessages returns all the stored messages of the type for the view.
+// The messages are collected only once for the view, and the added ones are appended,
+// so signaling each new message doesn't copy the messages of the view
+func (ms *Messages) getSignalMessages(view *proto.View, messageType proto.MessageType) []*proto.Message {
+	mux := ms.muxMap[messageType]
+	mux.Lock()
+	defer mux.Unlock()
+
+	signals := ms.signals[messageType]
+
+	if messages, ok := signals.get(view); ok {
+		return messages
+	}
+
+	stored := ms.getProtoMessages(view, messageType)
+
+	messages := make([]*proto.Message, 0, len(stored))
+	for _, message := range stored {
+		messages = append(messages, message)
+	}
+
+	signals.set(view, messages)
+
+	return messages[:len(messages):len(messages)]
 }
 
 // Close closes event manager
@@ -118,18 +311,21 @@ func (ms *Messages) numMessages(
 	return len(messages)
 }
 
-// PruneByHeight prunes out all old messages from the message queues
-// by the specified height in the view
-func (ms *Messages) PruneByHeight(height uint64) {
-	possibleMaps := []proto.MessageType{
+// allMessageTypes returns the message types, in the order their locks are acquired
+func allMessageTypes() []proto.MessageType {
+	return []proto.MessageType{
 		proto.MessageType_PREPREPARE,
 		proto.MessageType_PREPARE,
 		proto.MessageType_COMMIT,
 		proto.MessageType_ROUND_CHANGE,
 	}
+}
 
+// PruneByHeight prunes out all old messages from the message queues
+// by the specified height in the view
+func (ms *Messages) PruneByHeight(height uint64) {
 	// Prune out the views from all possible message types
-	for _, messageType := range possibleMaps {
+	for _, messageType := range allMessageTypes() {
 		mux := ms.muxMap[messageType]
 		mux.Lock()
 
@@ -137,14 +333,29 @@ func (ms *Messages) PruneByHeight(height uint64) {
 
 		// Delete all height maps up until the specified
 		// view height
-		for msgHeight := range messageMap {
+		for msgHeight, roundMessages := range messageMap {
 			if msgHeight < height {
+				ms.removeMessages(roundMessages)
 				delete(messageMap, msgHeight)
 			}
 		}
 
+		ms.signals[messageType].prune(height)
+
 		mux.Unlock()
 	}
+
+	ms.evidenceLock.Lock()
+	defer ms.evidenceLock.Unlock()
+
+	for evidenceHeight := range ms.evidence {
+		if evidenceHeight < height {
+			delete(ms.evidence, evidenceHeight)
+		}
+	}
+
+	ms.validations.prune(height)
+	ms.digests.prune(height)
 }
 
 // getProtoMessages fetches the underlying proto messages for the specified view
@@ -192,9 +403,15 @@ func (ms *Messages) GetValidMessages(
 
 	// Prune out invalid messages
 	for _, key := range invalidMessageKeys {
+		ms.removeMessage(messages[key])
+		ms.digests.remove(messages[key])
 		delete(messages, key)
 	}
 
+	if len(invalidMessageKeys) > 0 {
+		ms.signals[messageType].invalidate(view.Height, view.Round)
+	}
+
 	return validMessages
 }
 
@@ -245,7 +462,8 @@ func (ms *Messages) GetExtendedRCC(
 }
 
 // GetMostRoundChangeMessages fetches most round change messages
-// for the minimum round and above
+// for the minimum round and above. In case multiple rounds have
+// the same number of messages, the lowest round is picked
 func (ms *Messages) GetMostRoundChangeMessages(minRound, height uint64) []*proto.Message {
 	messageType := proto.MessageType_ROUND_CHANGE
 
@@ -266,13 +484,13 @@ func (ms *Messages) GetMostRoundChangeMessages(minRound, height uint64) []*proto
 		}
 
 		size := len(msgs)
-		if size > bestRoundMessagesCount {
+		if size > bestRoundMessagesCount || (size == bestRoundMessagesCount && round < bestRound) {
 			bestRound = round
 			bestRoundMessagesCount = size
 		}
 	}
 
-	if bestRound == 0 {
+	if bestRoundMessagesCount == 0 {
 		//	no messages found
 		return nil
 	}
@@ -285,6 +503,83 @@ func (ms *Messages) GetMostRoundChangeMessages(minRound, height uint64) []*proto
 	return messages
 }
 
+// GetRoundChangeMessages fetches the round change messages
+// for the minimum round and above, sorted by their rounds
+func (ms *Messages) GetRoundChangeMessages(minRound, height uint64) []*proto.Message {
+	messageType := proto.MessageType_ROUND_CHANGE
+
+	mux := ms.muxMap[messageType]
+	mux.RLock()
+	defer mux.RUnlock()
+
+	messages := make([]*proto.Message, 0)
+
+	for round, msgs := range ms.getMessageMap(messageType)[height] {
+		if round < minRound {
+			continue
+		}
+
+		for _, msg := range msgs {
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].View.Round < messages[j].View.Round
+	})
+
+	return messages
+}
+
+// signalLists maps the height -> round -> messages the subscribers are signaled with,
+// in the order they were added. The lists are only appended to, so the lists the subscribers
+// were signaled with never change. The lists are collected again once a message is removed
+type signalLists map[uint64]map[uint64][]*proto.Message
+
+// get returns the list of the view, if it is collected
+func (s signalLists) get(view *proto.View) ([]*proto.Message, bool) {
+	messages, ok := s[view.Height][view.Round]
+
+	// The capacity is limited, so the appends of the subscribers don't modify the list
+	return messages[:len(messages):len(messages)], ok
+}
+
+// set sets the collected list of the view
+func (s signalLists) set(view *proto.View, messages []*proto.Message) {
+	rounds, ok := s[view.Height]
+	if !ok {
+		rounds = make(map[uint64][]*proto.Message)
+		s[view.Height] = rounds
+	}
+
+	rounds[view.Round] = messages
+}
+
+// add appends the message to the list of its view, if it is collected
+func (s signalLists) add(message *proto.Message) {
+	if messages, ok := s[message.View.Height][message.View.Round]; ok {
+		s[message.View.Height][message.View.Round] = append(messages, message)
+	}
+}
+
+// invalidate drops the list of the view, after a message of the view is removed
+func (s signalLists) invalidate(height, round uint64) {
+	delete(s[height], round)
+
+	if len(s[height]) == 0 {
+		delete(s, height)
+	}
+}
+
+// prune drops the lists of the heights lower than the specified one
+func (s signalLists) prune(height uint64) {
+	for listHeight := range s {
+		if listHeight < height {
+			delete(s, listHeight)
+		}
+	}
+}
+
 // heightMessageMap maps the height number -> round message map
 type heightMessageMap map[uint64]roundMessageMap
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 
 	"github.com/Hydra-Chain/go-ibft/messages/proto"
@@ -127,6 +128,54 @@ func TestMessages_AddDuplicates(t *testing.T) {
 	assert.Equal(t, 1, messages.numMessages(initialView, commonType))
 }
 
+// TestMessages_AddConflicting tests that conflicting messages
+// from the same sender are detected, and the first message is kept
+func TestMessages_AddConflicting(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{
+		Height: 1,
+		Round:  1,
+	}
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	var (
+		first  = newPrepareMessage("node 0", view, []byte("hash 1"))
+		second = newPrepareMessage("node 0", view, []byte("hash 2"))
+		third  = newPrepareMessage("node 0", view, []byte("hash 3"))
+	)
+
+	assert.Nil(t, messages.AddMessage(first))
+
+	// Make sure re-sending the same message is not a conflict
+	assert.Nil(t, messages.AddMessage(newPrepareMessage("node 0", view, []byte("hash 1"))))
+
+	evidence := messages.AddMessage(second)
+	if evidence == nil {
+		t.Fatalf("evidence not returned")
+	}
+
+	assert.Equal(t, first, evidence.First)
+	assert.Equal(t, second, evidence.Second)
+	assert.True(t, evidence.IsValid())
+
+	// Make sure the evidence is returned only once per sender and view
+	assert.Nil(t, messages.AddMessage(third))
+	assert.Equal(t, []*Evidence{evidence}, messages.GetEvidence(view.Height))
+
+	// Make sure the first message is kept
+	validMessages := messages.GetValidMessages(view, proto.MessageType_PREPARE, func(_ *proto.Message) bool {
+		return true
+	})
+	assert.Equal(t, []*proto.Message{first}, validMessages)
+
+	// Make sure the evidence is pruned with the messages
+	messages.PruneByHeight(view.Height + 1)
+	assert.Empty(t, messages.GetEvidence(view.Height))
+}
+
 // TestMessages_Prune tests if pruning of certain messages works
 func TestMessages_Prune(t *testing.T) {
 	t.Parallel()
@@ -372,6 +421,67 @@ func TestMessages_GetMostRoundChangeMessages(t *testing.T) {
 	assert.Equal(t, mostMessagesRound, roundChangeMessages[0].View.Round)
 }
 
+// TestMessages_GetMostRoundChangeMessages_Tie makes sure
+// the lowest round is picked when multiple rounds
+// have the same number of round change messages
+func TestMessages_GetMostRoundChangeMessages_Tie(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	messageCount := 2
+	rounds := []uint64{5, 3, 4}
+
+	for _, round := range rounds {
+		for _, message := range generateRandomMessages(messageCount, &proto.View{
+			Height: 0,
+			Round:  round,
+		}, proto.MessageType_ROUND_CHANGE) {
+			messages.AddMessage(message)
+		}
+	}
+
+	roundChangeMessages := messages.GetMostRoundChangeMessages(4, 0)
+
+	if len(roundChangeMessages) != messageCount {
+		t.Fatalf("Invalid number of round change messages, %d", len(roundChangeMessages))
+	}
+
+	assert.Equal(t, uint64(4), roundChangeMessages[0].View.Round)
+
+	// Make sure no messages are returned for rounds without them
+	assert.Nil(t, messages.GetMostRoundChangeMessages(6, 0))
+}
+
+// TestMessages_GetRoundChangeMessages makes sure the round change
+// messages of all the rounds above the minimum are fetched, sorted by their rounds
+func TestMessages_GetRoundChangeMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	for _, round := range []uint64{5, 2, 4} {
+		for _, message := range generateRandomMessages(2, &proto.View{
+			Height: 0,
+			Round:  round,
+		}, proto.MessageType_ROUND_CHANGE) {
+			messages.AddMessage(message)
+		}
+	}
+
+	roundChangeMessages := messages.GetRoundChangeMessages(3, 0)
+	require.Len(t, roundChangeMessages, 4)
+
+	for index, round := range []uint64{4, 4, 5, 5} {
+		assert.Equal(t, round, roundChangeMessages[index].View.Round)
+	}
+
+	// Make sure no messages are returned for rounds without them
+	assert.Empty(t, messages.GetRoundChangeMessages(6, 0))
+}
+
 // TestMessages_EventManager checks that the event manager
 // behaves correctly when new messages appear
 func TestMessages_EventManager(t *testing.T) {
@@ -411,3 +521,128 @@ func TestMessages_EventManager(t *testing.T) {
 	// Make sure the number of messages is actually accurate
 	assert.Equal(t, numMessages, messages.numMessages(baseView, messageType))
 }
+
+// TestMessages_EventManager_Thresholds makes sure the subscribers
+// are notified only once the stored messages reach the thresholds
+func TestMessages_EventManager_Thresholds(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	var (
+		messageType = proto.MessageType_PREPARE
+		baseView    = &proto.View{
+			Height: 0,
+			Round:  0,
+		}
+		higherView = &proto.View{
+			Height: 0,
+			Round:  1,
+		}
+	)
+
+	// Store a message before subscribing
+	messages.AddMessage(newPrepareMessage("node 0", higherView, []byte("proposal hash")))
+
+	subscription := messages.Subscribe(SubscriptionDetails{
+		MessageType:    messageType,
+		View:           baseView,
+		MinNumMessages: 2,
+		HasQuorumFn: func(_ uint64, msgs []*proto.Message) bool {
+			return len(msgs) >= 3
+		},
+		HasMinRound: true,
+	})
+
+	defer messages.Unsubscribe(subscription.ID)
+
+	assertNotified := func(expected bool) {
+		t.Helper()
+
+		select {
+		case round := <-subscription.SubCh:
+			assert.True(t, expected, "unexpected notification for round %d", round)
+		case <-time.After(100 * time.Millisecond):
+			assert.False(t, expected, "notification expected")
+		}
+	}
+
+	// Make sure the subscriber is not notified below the thresholds
+	messages.AddMessage(newPrepareMessage("node 1", higherView, []byte("proposal hash")))
+	assertNotified(false)
+
+	// Make sure the subscriber is notified once the thresholds are reached
+	messages.AddMessage(newPrepareMessage("node 2", higherView, []byte("proposal hash")))
+	assertNotified(true)
+
+	// Make sure a new subscription is notified of the stored messages right away
+	stored := messages.Subscribe(SubscriptionDetails{
+		MessageType:    messageType,
+		View:           higherView,
+		MinNumMessages: 3,
+	})
+
+	defer messages.Unsubscribe(stored.ID)
+
+	select {
+	case round := <-stored.SubCh:
+		assert.Equal(t, higherView.Round, round)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification expected")
+	}
+}
+
+// TestMessages_EventManager_Incremental makes sure the signaled messages are not
+// collected again for each new message, and the thresholds are not evaluated
+// again once they are reached
+func TestMessages_EventManager_Incremental(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	var (
+		view        = &proto.View{Height: 0, Round: 0}
+		evaluations []int
+	)
+
+	subscription := messages.Subscribe(SubscriptionDetails{
+		MessageType: proto.MessageType_PREPARE,
+		View:        view,
+		HasQuorumFn: func(_ uint64, msgs []*proto.Message) bool {
+			evaluations = append(evaluations, len(msgs))
+
+			return len(msgs) >= 2
+		},
+	})
+
+	defer messages.Unsubscribe(subscription.ID)
+
+	for _, sender := range []string{"node 0", "node 1", "node 2"} {
+		messages.AddMessage(newPrepareMessage(sender, view, []byte("proposal hash")))
+	}
+
+	// The empty round is evaluated on subscribing
+	assert.Equal(t, []int{0, 1, 2}, evaluations)
+
+	// Make sure the signaled list is appended to, and the duplicates are not added to it
+	messages.AddMessage(newPrepareMessage("node 0", view, []byte("proposal hash")))
+
+	signaled := messages.getSignalMessages(view, proto.MessageType_PREPARE)
+	assert.Len(t, signaled, 3)
+	assert.Equal(t, len(signaled), cap(signaled))
+
+	// Make sure the list is collected again once a message is removed
+	messages.GetValidMessages(view, proto.MessageType_PREPARE, func(message *proto.Message) bool {
+		return string(message.From) != "node 1"
+	})
+
+	_, collected := messages.signals[proto.MessageType_PREPARE].get(view)
+	assert.False(t, collected)
+	assert.Len(t, messages.getSignalMessages(view, proto.MessageType_PREPARE), 2)
+
+	// Make sure the lists of the pruned heights are dropped
+	messages.PruneByHeight(1)
+	assert.Empty(t, messages.signals[proto.MessageType_PREPARE])
+}
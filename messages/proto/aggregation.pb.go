@@ -0,0 +1,447 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.9
+// source: messages/proto/aggregation.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AggregatedSeal is the single seal aggregated
+// from the seals of the validators for the same digest
+type AggregatedSeal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// bitmap marks the signers, where the bit i is set
+	// if the validator i, in the order of the addresses, signed
+	Bitmap []byte `protobuf:"bytes,1,opt,name=bitmap,proto3" json:"bitmap,omitempty"`
+	// signature is the aggregated signature of the signers
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *AggregatedSeal) Reset() {
+	*x = AggregatedSeal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_aggregation_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AggregatedSeal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregatedSeal) ProtoMessage() {}
+
+func (x *AggregatedSeal) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_aggregation_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregatedSeal.ProtoReflect.Descriptor instead.
+func (*AggregatedSeal) Descriptor() ([]byte, []int) {
+	return file_messages_proto_aggregation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AggregatedSeal) GetBitmap() []byte {
+	if x != nil {
+		return x.Bitmap
+	}
+	return nil
+}
+
+func (x *AggregatedSeal) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// CompactPreparedCertificate is the PreparedCertificate carrying the aggregated
+// prepare seals instead of the PREPREPARE and the PREPARE messages
+type CompactPreparedCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// view is the view the proposal was prepared in
+	View *View `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	// proposalHash is the hash of the prepared proposal
+	ProposalHash []byte `protobuf:"bytes,2,opt,name=proposalHash,proto3" json:"proposalHash,omitempty"`
+	// prepareSeal is the aggregated prepare seal of the proposer
+	// and the validators which sent the PREPARE messages
+	PrepareSeal *AggregatedSeal `protobuf:"bytes,3,opt,name=prepareSeal,proto3" json:"prepareSeal,omitempty"`
+}
+
+func (x *CompactPreparedCertificate) Reset() {
+	*x = CompactPreparedCertificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_aggregation_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactPreparedCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactPreparedCertificate) ProtoMessage() {}
+
+func (x *CompactPreparedCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_aggregation_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactPreparedCertificate.ProtoReflect.Descriptor instead.
+func (*CompactPreparedCertificate) Descriptor() ([]byte, []int) {
+	return file_messages_proto_aggregation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CompactPreparedCertificate) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *CompactPreparedCertificate) GetProposalHash() []byte {
+	if x != nil {
+		return x.ProposalHash
+	}
+	return nil
+}
+
+func (x *CompactPreparedCertificate) GetPrepareSeal() *AggregatedSeal {
+	if x != nil {
+		return x.PrepareSeal
+	}
+	return nil
+}
+
+// RoundChangeSeal is the aggregated round change seal
+// of the validators with the same latest prepared proposal
+type RoundChangeSeal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// preparedRound is the round of the latest prepared proposal
+	PreparedRound uint64 `protobuf:"varint,1,opt,name=preparedRound,proto3" json:"preparedRound,omitempty"`
+	// preparedProposalHash is the hash of the latest
+	// prepared proposal, or empty if none was prepared
+	PreparedProposalHash []byte `protobuf:"bytes,2,opt,name=preparedProposalHash,proto3" json:"preparedProposalHash,omitempty"`
+	// seal is the aggregated round change seal of the validators
+	Seal *AggregatedSeal `protobuf:"bytes,3,opt,name=seal,proto3" json:"seal,omitempty"`
+}
+
+func (x *RoundChangeSeal) Reset() {
+	*x = RoundChangeSeal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_aggregation_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundChangeSeal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundChangeSeal) ProtoMessage() {}
+
+func (x *RoundChangeSeal) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_aggregation_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundChangeSeal.ProtoReflect.Descriptor instead.
+func (*RoundChangeSeal) Descriptor() ([]byte, []int) {
+	return file_messages_proto_aggregation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RoundChangeSeal) GetPreparedRound() uint64 {
+	if x != nil {
+		return x.PreparedRound
+	}
+	return 0
+}
+
+func (x *RoundChangeSeal) GetPreparedProposalHash() []byte {
+	if x != nil {
+		return x.PreparedProposalHash
+	}
+	return nil
+}
+
+func (x *RoundChangeSeal) GetSeal() *AggregatedSeal {
+	if x != nil {
+		return x.Seal
+	}
+	return nil
+}
+
+// CompactRoundChangeCertificate is the RoundChangeCertificate carrying
+// the aggregated round change seals instead of the ROUND CHANGE messages.
+// Only the PC of the highest prepared proposal is included
+type CompactRoundChangeCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// view is the view of the round change
+	View *View `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	// roundChangeSeals are the round change seals,
+	// grouped by the latest prepared proposal of the signers
+	RoundChangeSeals []*RoundChangeSeal `protobuf:"bytes,2,rep,name=roundChangeSeals,proto3" json:"roundChangeSeals,omitempty"`
+	// latestPreparedCertificate is the compact PC
+	// of the highest prepared proposal, if any
+	LatestPreparedCertificate *CompactPreparedCertificate `protobuf:"bytes,3,opt,name=latestPreparedCertificate,proto3" json:"latestPreparedCertificate,omitempty"`
+}
+
+func (x *CompactRoundChangeCertificate) Reset() {
+	*x = CompactRoundChangeCertificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_aggregation_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactRoundChangeCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactRoundChangeCertificate) ProtoMessage() {}
+
+func (x *CompactRoundChangeCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_aggregation_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactRoundChangeCertificate.ProtoReflect.Descriptor instead.
+func (*CompactRoundChangeCertificate) Descriptor() ([]byte, []int) {
+	return file_messages_proto_aggregation_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CompactRoundChangeCertificate) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *CompactRoundChangeCertificate) GetRoundChangeSeals() []*RoundChangeSeal {
+	if x != nil {
+		return x.RoundChangeSeals
+	}
+	return nil
+}
+
+func (x *CompactRoundChangeCertificate) GetLatestPreparedCertificate() *CompactPreparedCertificate {
+	if x != nil {
+		return x.LatestPreparedCertificate
+	}
+	return nil
+}
+
+var File_messages_proto_aggregation_proto protoreflect.FileDescriptor
+
+var file_messages_proto_aggregation_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0x46, 0x0a, 0x0e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x53,
+	0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x8e, 0x01, 0x0a, 0x1a, 0x43, 0x6f,
+	0x6d, 0x70, 0x61, 0x63, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x52, 0x04, 0x76,
+	0x69, 0x65, 0x77, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48,
+	0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x31, 0x0a, 0x0b, 0x70, 0x72, 0x65, 0x70, 0x61,
+	0x72, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x41,
+	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x0b, 0x70,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x22, 0x90, 0x01, 0x0a, 0x0f, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x24,
+	0x0a, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x12, 0x32, 0x0a, 0x14, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x14, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x23, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61,
+	0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x04, 0x73, 0x65, 0x61, 0x6c, 0x22, 0xd3, 0x01,
+	0x0a, 0x1d, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12,
+	0x19, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e,
+	0x56, 0x69, 0x65, 0x77, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x12, 0x3c, 0x0a, 0x10, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x10, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x59, 0x0a, 0x19, 0x6c, 0x61, 0x74, 0x65,
+	0x73, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x43, 0x6f,
+	0x6d, 0x70, 0x61, 0x63, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x19, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74,
+	0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x42, 0x11, 0x5a, 0x0f, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_messages_proto_aggregation_proto_rawDescOnce sync.Once
+	file_messages_proto_aggregation_proto_rawDescData = file_messages_proto_aggregation_proto_rawDesc
+)
+
+func file_messages_proto_aggregation_proto_rawDescGZIP() []byte {
+	file_messages_proto_aggregation_proto_rawDescOnce.Do(func() {
+		file_messages_proto_aggregation_proto_rawDescData = protoimpl.X.CompressGZIP(file_messages_proto_aggregation_proto_rawDescData)
+	})
+	return file_messages_proto_aggregation_proto_rawDescData
+}
+
+var file_messages_proto_aggregation_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_messages_proto_aggregation_proto_goTypes = []interface{}{
+	(*AggregatedSeal)(nil),                // 0: AggregatedSeal
+	(*CompactPreparedCertificate)(nil),    // 1: CompactPreparedCertificate
+	(*RoundChangeSeal)(nil),               // 2: RoundChangeSeal
+	(*CompactRoundChangeCertificate)(nil), // 3: CompactRoundChangeCertificate
+	(*View)(nil),                          // 4: View
+}
+var file_messages_proto_aggregation_proto_depIdxs = []int32{
+	4, // 0: CompactPreparedCertificate.view:type_name -> View
+	0, // 1: CompactPreparedCertificate.prepareSeal:type_name -> AggregatedSeal
+	0, // 2: RoundChangeSeal.seal:type_name -> AggregatedSeal
+	4, // 3: CompactRoundChangeCertificate.view:type_name -> View
+	2, // 4: CompactRoundChangeCertificate.roundChangeSeals:type_name -> RoundChangeSeal
+	1, // 5: CompactRoundChangeCertificate.latestPreparedCertificate:type_name -> CompactPreparedCertificate
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_messages_proto_aggregation_proto_init() }
+func file_messages_proto_aggregation_proto_init() {
+	if File_messages_proto_aggregation_proto != nil {
+		return
+	}
+	file_messages_proto_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_messages_proto_aggregation_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AggregatedSeal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_aggregation_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactPreparedCertificate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_aggregation_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundChangeSeal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_aggregation_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactRoundChangeCertificate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_messages_proto_aggregation_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_messages_proto_aggregation_proto_goTypes,
+		DependencyIndexes: file_messages_proto_aggregation_proto_depIdxs,
+		MessageInfos:      file_messages_proto_aggregation_proto_msgTypes,
+	}.Build()
+	File_messages_proto_aggregation_proto = out.File
+	file_messages_proto_aggregation_proto_rawDesc = nil
+	file_messages_proto_aggregation_proto_goTypes = nil
+	file_messages_proto_aggregation_proto_depIdxs = nil
+}
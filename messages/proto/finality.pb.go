@@ -0,0 +1,262 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.9
+// source: messages/proto/finality.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// FinalityCertificate proves that a proposal was finalized for a height.
+// It is self-contained, so it can be verified without running the consensus
+type FinalityCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// view is the height and the round the proposal was finalized in
+	View *View `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	// proposalHash is the hash of the finalized proposal
+	ProposalHash []byte `protobuf:"bytes,2,opt,name=proposalHash,proto3" json:"proposalHash,omitempty"`
+	// seals are the committed seals of the validators for the proposal hash
+	Seals []*CommittedSeal `protobuf:"bytes,3,rep,name=seals,proto3" json:"seals,omitempty"`
+	// validatorSetHash references the validator set at the height
+	ValidatorSetHash []byte `protobuf:"bytes,4,opt,name=validatorSetHash,proto3" json:"validatorSetHash,omitempty"`
+}
+
+func (x *FinalityCertificate) Reset() {
+	*x = FinalityCertificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_finality_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FinalityCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalityCertificate) ProtoMessage() {}
+
+func (x *FinalityCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_finality_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalityCertificate.ProtoReflect.Descriptor instead.
+func (*FinalityCertificate) Descriptor() ([]byte, []int) {
+	return file_messages_proto_finality_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FinalityCertificate) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *FinalityCertificate) GetProposalHash() []byte {
+	if x != nil {
+		return x.ProposalHash
+	}
+	return nil
+}
+
+func (x *FinalityCertificate) GetSeals() []*CommittedSeal {
+	if x != nil {
+		return x.Seals
+	}
+	return nil
+}
+
+func (x *FinalityCertificate) GetValidatorSetHash() []byte {
+	if x != nil {
+		return x.ValidatorSetHash
+	}
+	return nil
+}
+
+// CommittedSeal is the proof of a validator signing the finalized proposal
+type CommittedSeal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// signer is the address of the validator
+	Signer []byte `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"`
+	// signature is the signature of the validator for the proposal hash
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *CommittedSeal) Reset() {
+	*x = CommittedSeal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_finality_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommittedSeal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommittedSeal) ProtoMessage() {}
+
+func (x *CommittedSeal) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_finality_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommittedSeal.ProtoReflect.Descriptor instead.
+func (*CommittedSeal) Descriptor() ([]byte, []int) {
+	return file_messages_proto_finality_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CommittedSeal) GetSigner() []byte {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+func (x *CommittedSeal) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+var File_messages_proto_finality_proto protoreflect.FileDescriptor
+
+var file_messages_proto_finality_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x1d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa6,
+	0x01, 0x0a, 0x13, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x52, 0x04, 0x76, 0x69, 0x65,
+	0x77, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
+	0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x24, 0x0a, 0x05, 0x73, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64,
+	0x53, 0x65, 0x61, 0x6c, 0x52, 0x05, 0x73, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x74, 0x48, 0x61, 0x73, 0x68, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x53, 0x65, 0x74, 0x48, 0x61, 0x73, 0x68, 0x22, 0x45, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x11,
+	0x5a, 0x0f, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_messages_proto_finality_proto_rawDescOnce sync.Once
+	file_messages_proto_finality_proto_rawDescData = file_messages_proto_finality_proto_rawDesc
+)
+
+func file_messages_proto_finality_proto_rawDescGZIP() []byte {
+	file_messages_proto_finality_proto_rawDescOnce.Do(func() {
+		file_messages_proto_finality_proto_rawDescData = protoimpl.X.CompressGZIP(file_messages_proto_finality_proto_rawDescData)
+	})
+	return file_messages_proto_finality_proto_rawDescData
+}
+
+var file_messages_proto_finality_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_messages_proto_finality_proto_goTypes = []interface{}{
+	(*FinalityCertificate)(nil), // 0: FinalityCertificate
+	(*CommittedSeal)(nil),       // 1: CommittedSeal
+	(*View)(nil),                // 2: View
+}
+var file_messages_proto_finality_proto_depIdxs = []int32{
+	2, // 0: FinalityCertificate.view:type_name -> View
+	1, // 1: FinalityCertificate.seals:type_name -> CommittedSeal
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_messages_proto_finality_proto_init() }
+func file_messages_proto_finality_proto_init() {
+	if File_messages_proto_finality_proto != nil {
+		return
+	}
+	file_messages_proto_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_messages_proto_finality_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FinalityCertificate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_finality_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommittedSeal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_messages_proto_finality_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_messages_proto_finality_proto_goTypes,
+		DependencyIndexes: file_messages_proto_finality_proto_depIdxs,
+		MessageInfos:      file_messages_proto_finality_proto_msgTypes,
+	}.Build()
+	File_messages_proto_finality_proto = out.File
+	file_messages_proto_finality_proto_rawDesc = nil
+	file_messages_proto_finality_proto_goTypes = nil
+	file_messages_proto_finality_proto_depIdxs = nil
+}
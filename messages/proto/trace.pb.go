@@ -0,0 +1,633 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.9
+// source: messages/proto/trace.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type defines the types of message trace records
+type TraceEntry_Type int32
+
+const (
+	// SEQUENCE is recorded when the node starts running a sequence
+	TraceEntry_SEQUENCE TraceEntry_Type = 0
+	// RECEIVED is recorded when a message is added to the node
+	TraceEntry_RECEIVED TraceEntry_Type = 1
+	// SENT is recorded when the node multicasts a message
+	TraceEntry_SENT TraceEntry_Type = 2
+	// TIMER_STARTED is recorded when the node starts a timer
+	TraceEntry_TIMER_STARTED TraceEntry_Type = 3
+	// TIMER_EXPIRED is recorded when a timer of the node expires
+	TraceEntry_TIMER_EXPIRED TraceEntry_Type = 4
+	// BACKEND is recorded when the node calls the backend
+	TraceEntry_BACKEND TraceEntry_Type = 5
+	// SEQUENCE_DONE is recorded when the node returns from a sequence
+	TraceEntry_SEQUENCE_DONE TraceEntry_Type = 6
+)
+
+// Enum value maps for TraceEntry_Type.
+var (
+	TraceEntry_Type_name = map[int32]string{
+		0: "SEQUENCE",
+		1: "RECEIVED",
+		2: "SENT",
+		3: "TIMER_STARTED",
+		4: "TIMER_EXPIRED",
+		5: "BACKEND",
+		6: "SEQUENCE_DONE",
+	}
+	TraceEntry_Type_value = map[string]int32{
+		"SEQUENCE":      0,
+		"RECEIVED":      1,
+		"SENT":          2,
+		"TIMER_STARTED": 3,
+		"TIMER_EXPIRED": 4,
+		"BACKEND":       5,
+		"SEQUENCE_DONE": 6,
+	}
+)
+
+func (x TraceEntry_Type) Enum() *TraceEntry_Type {
+	p := new(TraceEntry_Type)
+	*p = x
+	return p
+}
+
+func (x TraceEntry_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TraceEntry_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_messages_proto_trace_proto_enumTypes[0].Descriptor()
+}
+
+func (TraceEntry_Type) Type() protoreflect.EnumType {
+	return &file_messages_proto_trace_proto_enumTypes[0]
+}
+
+func (x TraceEntry_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TraceEntry_Type.Descriptor instead.
+func (TraceEntry_Type) EnumDescriptor() ([]byte, []int) {
+	return file_messages_proto_trace_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// TraceEntry defines a single record of the message trace
+type TraceEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// type defines the record type
+	Type TraceEntry_Type `protobuf:"varint,1,opt,name=type,proto3,enum=TraceEntry_Type" json:"type,omitempty"`
+	// offset is the time elapsed since the start of the trace, in nanoseconds
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// height is the height of the started or the returned sequence
+	Height uint64 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	// message is the received or the sent message
+	Message *Message `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// duration is the duration of the timer, in nanoseconds
+	Duration int64 `protobuf:"varint,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	// call is the call of the backend
+	Call *BackendCall `protobuf:"bytes,6,opt,name=call,proto3" json:"call,omitempty"`
+}
+
+func (x *TraceEntry) Reset() {
+	*x = TraceEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_trace_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TraceEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceEntry) ProtoMessage() {}
+
+func (x *TraceEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_trace_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceEntry.ProtoReflect.Descriptor instead.
+func (*TraceEntry) Descriptor() ([]byte, []int) {
+	return file_messages_proto_trace_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TraceEntry) GetType() TraceEntry_Type {
+	if x != nil {
+		return x.Type
+	}
+	return TraceEntry_SEQUENCE
+}
+
+func (x *TraceEntry) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *TraceEntry) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *TraceEntry) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *TraceEntry) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *TraceEntry) GetCall() *BackendCall {
+	if x != nil {
+		return x.Call
+	}
+	return nil
+}
+
+// BackendCall defines a call of a backend method, with its arguments and results
+type BackendCall struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// method is the name of the called method
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// arguments are the arguments the method was called with
+	Arguments *BackendArguments `protobuf:"bytes,2,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// valid is the result of the verification methods
+	Valid bool `protobuf:"varint,3,opt,name=valid,proto3" json:"valid,omitempty"`
+	// data is the raw proposal built by the backend, or the ID of the node
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// message is the message built by the backend
+	Message *Message `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	// error is the error returned by the backend, if any
+	Error string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	// votingPowers are the voting powers of the validators, by the address
+	VotingPowers map[string][]byte `protobuf:"bytes,7,rep,name=votingPowers,proto3" json:"votingPowers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *BackendCall) Reset() {
+	*x = BackendCall{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_trace_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendCall) ProtoMessage() {}
+
+func (x *BackendCall) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_trace_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendCall.ProtoReflect.Descriptor instead.
+func (*BackendCall) Descriptor() ([]byte, []int) {
+	return file_messages_proto_trace_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BackendCall) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *BackendCall) GetArguments() *BackendArguments {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *BackendCall) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *BackendCall) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *BackendCall) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *BackendCall) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BackendCall) GetVotingPowers() map[string][]byte {
+	if x != nil {
+		return x.VotingPowers
+	}
+	return nil
+}
+
+// BackendArguments defines the arguments of a backend method call
+type BackendArguments struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// view is the view argument
+	View *View `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	// rawProposal is the raw proposal argument
+	RawProposal []byte `protobuf:"bytes,2,opt,name=rawProposal,proto3" json:"rawProposal,omitempty"`
+	// proposalHash is the proposal hash argument
+	ProposalHash []byte `protobuf:"bytes,3,opt,name=proposalHash,proto3" json:"proposalHash,omitempty"`
+	// proposal is the proposal argument
+	Proposal *Proposal `protobuf:"bytes,4,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	// id is the validator ID argument
+	Id []byte `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	// height is the height argument
+	Height uint64 `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	// round is the round argument
+	Round uint64 `protobuf:"varint,7,opt,name=round,proto3" json:"round,omitempty"`
+	// message is the message argument
+	Message *Message `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`
+	// roundChangeCertificate is the round change certificate argument
+	RoundChangeCertificate *RoundChangeCertificate `protobuf:"bytes,9,opt,name=roundChangeCertificate,proto3" json:"roundChangeCertificate,omitempty"`
+	// preparedCertificate is the prepared certificate argument
+	PreparedCertificate *PreparedCertificate `protobuf:"bytes,10,opt,name=preparedCertificate,proto3" json:"preparedCertificate,omitempty"`
+	// committedSeals are the committed seals arguments
+	CommittedSeals []*CommittedSeal `protobuf:"bytes,11,rep,name=committedSeals,proto3" json:"committedSeals,omitempty"`
+	// messages are the conflicting messages of the reported equivocation
+	Messages []*Message `protobuf:"bytes,12,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *BackendArguments) Reset() {
+	*x = BackendArguments{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_trace_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendArguments) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendArguments) ProtoMessage() {}
+
+func (x *BackendArguments) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_trace_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendArguments.ProtoReflect.Descriptor instead.
+func (*BackendArguments) Descriptor() ([]byte, []int) {
+	return file_messages_proto_trace_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BackendArguments) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetRawProposal() []byte {
+	if x != nil {
+		return x.RawProposal
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetProposalHash() []byte {
+	if x != nil {
+		return x.ProposalHash
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetProposal() *Proposal {
+	if x != nil {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BackendArguments) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *BackendArguments) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetRoundChangeCertificate() *RoundChangeCertificate {
+	if x != nil {
+		return x.RoundChangeCertificate
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetPreparedCertificate() *PreparedCertificate {
+	if x != nil {
+		return x.PreparedCertificate
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetCommittedSeals() []*CommittedSeal {
+	if x != nil {
+		return x.CommittedSeals
+	}
+	return nil
+}
+
+func (x *BackendArguments) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+var File_messages_proto_trace_proto protoreflect.FileDescriptor
+
+var file_messages_proto_trace_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x74, 0x72, 0x61, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x69, 0x6e, 0x61,
+	0x6c, 0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb8, 0x02, 0x0a, 0x0a, 0x54,
+	0x72, 0x61, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x24, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12,
+	0x22, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x20, 0x0a, 0x04, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x43, 0x61, 0x6c, 0x6c, 0x52, 0x04, 0x63, 0x61, 0x6c,
+	0x6c, 0x22, 0x72, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x45, 0x51,
+	0x55, 0x45, 0x4e, 0x43, 0x45, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x45, 0x43, 0x45, 0x49,
+	0x56, 0x45, 0x44, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x45, 0x4e, 0x54, 0x10, 0x02, 0x12,
+	0x11, 0x0a, 0x0d, 0x54, 0x49, 0x4d, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x52, 0x54, 0x45, 0x44,
+	0x10, 0x03, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x49, 0x4d, 0x45, 0x52, 0x5f, 0x45, 0x58, 0x50, 0x49,
+	0x52, 0x45, 0x44, 0x10, 0x04, 0x12, 0x0b, 0x0a, 0x07, 0x42, 0x41, 0x43, 0x4b, 0x45, 0x4e, 0x44,
+	0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x43, 0x45, 0x5f, 0x44,
+	0x4f, 0x4e, 0x45, 0x10, 0x06, 0x22, 0xbf, 0x02, 0x0a, 0x0b, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x43, 0x61, 0x6c, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x2f, 0x0a,
+	0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x41, 0x72, 0x67, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x52, 0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x22, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x42, 0x0a, 0x0c, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x77, 0x65,
+	0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x43, 0x61, 0x6c, 0x6c, 0x2e, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x77,
+	0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67,
+	0x50, 0x6f, 0x77, 0x65, 0x72, 0x73, 0x1a, 0x3f, 0x0a, 0x11, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67,
+	0x50, 0x6f, 0x77, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xf3, 0x03, 0x0a, 0x10, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x41, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x04,
+	0x76, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x69, 0x65,
+	0x77, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x61, 0x77, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x61,
+	0x77, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x25, 0x0a,
+	0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x09, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x12, 0x22, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x4f, 0x0a, 0x16, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52,
+	0x16, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x43, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x46, 0x0a, 0x13, 0x70, 0x72, 0x65, 0x70, 0x61,
+	0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43,
+	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x13, 0x70, 0x72, 0x65, 0x70,
+	0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12,
+	0x36, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c,
+	0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74,
+	0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x24, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x42, 0x11, 0x5a,
+	0x0f, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_messages_proto_trace_proto_rawDescOnce sync.Once
+	file_messages_proto_trace_proto_rawDescData = file_messages_proto_trace_proto_rawDesc
+)
+
+func file_messages_proto_trace_proto_rawDescGZIP() []byte {
+	file_messages_proto_trace_proto_rawDescOnce.Do(func() {
+		file_messages_proto_trace_proto_rawDescData = protoimpl.X.CompressGZIP(file_messages_proto_trace_proto_rawDescData)
+	})
+	return file_messages_proto_trace_proto_rawDescData
+}
+
+var file_messages_proto_trace_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_messages_proto_trace_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_messages_proto_trace_proto_goTypes = []interface{}{
+	(TraceEntry_Type)(0),           // 0: TraceEntry.Type
+	(*TraceEntry)(nil),             // 1: TraceEntry
+	(*BackendCall)(nil),            // 2: BackendCall
+	(*BackendArguments)(nil),       // 3: BackendArguments
+	nil,                            // 4: BackendCall.VotingPowersEntry
+	(*Message)(nil),                // 5: Message
+	(*View)(nil),                   // 6: View
+	(*Proposal)(nil),               // 7: Proposal
+	(*RoundChangeCertificate)(nil), // 8: RoundChangeCertificate
+	(*PreparedCertificate)(nil),    // 9: PreparedCertificate
+	(*CommittedSeal)(nil),          // 10: CommittedSeal
+}
+var file_messages_proto_trace_proto_depIdxs = []int32{
+	0,  // 0: TraceEntry.type:type_name -> TraceEntry.Type
+	5,  // 1: TraceEntry.message:type_name -> Message
+	2,  // 2: TraceEntry.call:type_name -> BackendCall
+	3,  // 3: BackendCall.arguments:type_name -> BackendArguments
+	5,  // 4: BackendCall.message:type_name -> Message
+	4,  // 5: BackendCall.votingPowers:type_name -> BackendCall.VotingPowersEntry
+	6,  // 6: BackendArguments.view:type_name -> View
+	7,  // 7: BackendArguments.proposal:type_name -> Proposal
+	5,  // 8: BackendArguments.message:type_name -> Message
+	8,  // 9: BackendArguments.roundChangeCertificate:type_name -> RoundChangeCertificate
+	9,  // 10: BackendArguments.preparedCertificate:type_name -> PreparedCertificate
+	10, // 11: BackendArguments.committedSeals:type_name -> CommittedSeal
+	5,  // 12: BackendArguments.messages:type_name -> Message
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_messages_proto_trace_proto_init() }
+func file_messages_proto_trace_proto_init() {
+	if File_messages_proto_trace_proto != nil {
+		return
+	}
+	file_messages_proto_messages_proto_init()
+	file_messages_proto_finality_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_messages_proto_trace_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TraceEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_trace_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendCall); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_trace_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendArguments); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_messages_proto_trace_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_messages_proto_trace_proto_goTypes,
+		DependencyIndexes: file_messages_proto_trace_proto_depIdxs,
+		EnumInfos:         file_messages_proto_trace_proto_enumTypes,
+		MessageInfos:      file_messages_proto_trace_proto_msgTypes,
+	}.Build()
+	File_messages_proto_trace_proto = out.File
+	file_messages_proto_trace_proto_rawDesc = nil
+	file_messages_proto_trace_proto_goTypes = nil
+	file_messages_proto_trace_proto_depIdxs = nil
+}
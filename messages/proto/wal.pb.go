@@ -0,0 +1,270 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.9
+// source: messages/proto/wal.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type defines the types of write-ahead log records
+type WALEntry_Type int32
+
+const (
+	// VIEW is written when the node moves to a new view
+	WALEntry_VIEW WALEntry_Type = 0
+	// PREPARED is written when the node locks on a proposal
+	WALEntry_PREPARED WALEntry_Type = 1
+	// MESSAGE is written before the node multicasts a message
+	WALEntry_MESSAGE WALEntry_Type = 2
+	// PROPOSAL is written when the node accepts the proposal of the round
+	WALEntry_PROPOSAL WALEntry_Type = 3
+)
+
+// Enum value maps for WALEntry_Type.
+var (
+	WALEntry_Type_name = map[int32]string{
+		0: "VIEW",
+		1: "PREPARED",
+		2: "MESSAGE",
+		3: "PROPOSAL",
+	}
+	WALEntry_Type_value = map[string]int32{
+		"VIEW":     0,
+		"PREPARED": 1,
+		"MESSAGE":  2,
+		"PROPOSAL": 3,
+	}
+)
+
+func (x WALEntry_Type) Enum() *WALEntry_Type {
+	p := new(WALEntry_Type)
+	*p = x
+	return p
+}
+
+func (x WALEntry_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WALEntry_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_messages_proto_wal_proto_enumTypes[0].Descriptor()
+}
+
+func (WALEntry_Type) Type() protoreflect.EnumType {
+	return &file_messages_proto_wal_proto_enumTypes[0]
+}
+
+func (x WALEntry_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WALEntry_Type.Descriptor instead.
+func (WALEntry_Type) EnumDescriptor() ([]byte, []int) {
+	return file_messages_proto_wal_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// WALEntry defines a single record of the write-ahead log
+type WALEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// type defines the record type
+	Type WALEntry_Type `protobuf:"varint,1,opt,name=type,proto3,enum=WALEntry_Type" json:"type,omitempty"`
+	// view is the view the record belongs to
+	View *View `protobuf:"bytes,2,opt,name=view,proto3" json:"view,omitempty"`
+	// message is the message signed by the node, or the accepted proposal message, if any
+	Message *Message `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// latestPreparedCertificate is the PC the node locked on, if any
+	LatestPreparedCertificate *PreparedCertificate `protobuf:"bytes,4,opt,name=latestPreparedCertificate,proto3" json:"latestPreparedCertificate,omitempty"`
+	// latestPreparedProposal is the proposal the node locked on, if any
+	LatestPreparedProposal *Proposal `protobuf:"bytes,5,opt,name=latestPreparedProposal,proto3" json:"latestPreparedProposal,omitempty"`
+}
+
+func (x *WALEntry) Reset() {
+	*x = WALEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_wal_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WALEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WALEntry) ProtoMessage() {}
+
+func (x *WALEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_wal_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WALEntry.ProtoReflect.Descriptor instead.
+func (*WALEntry) Descriptor() ([]byte, []int) {
+	return file_messages_proto_wal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WALEntry) GetType() WALEntry_Type {
+	if x != nil {
+		return x.Type
+	}
+	return WALEntry_VIEW
+}
+
+func (x *WALEntry) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *WALEntry) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *WALEntry) GetLatestPreparedCertificate() *PreparedCertificate {
+	if x != nil {
+		return x.LatestPreparedCertificate
+	}
+	return nil
+}
+
+func (x *WALEntry) GetLatestPreparedProposal() *Proposal {
+	if x != nil {
+		return x.LatestPreparedProposal
+	}
+	return nil
+}
+
+var File_messages_proto_wal_proto protoreflect.FileDescriptor
+
+var file_messages_proto_wal_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x77, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xbf, 0x02, 0x0a, 0x08, 0x57, 0x41,
+	0x4c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x57, 0x41, 0x4c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x76, 0x69,
+	0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x52,
+	0x04, 0x76, 0x69, 0x65, 0x77, 0x12, 0x22, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x52, 0x0a, 0x19, 0x6c, 0x61, 0x74,
+	0x65, 0x73, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x65, 0x52, 0x19, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72,
+	0x65, 0x64, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x41, 0x0a,
+	0x16, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x16, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74,
+	0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
+	0x22, 0x39, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x56, 0x49, 0x45, 0x57,
+	0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x44, 0x10, 0x01,
+	0x12, 0x0b, 0x0a, 0x07, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x10, 0x02, 0x12, 0x0c, 0x0a,
+	0x08, 0x50, 0x52, 0x4f, 0x50, 0x4f, 0x53, 0x41, 0x4c, 0x10, 0x03, 0x42, 0x11, 0x5a, 0x0f, 0x2f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_messages_proto_wal_proto_rawDescOnce sync.Once
+	file_messages_proto_wal_proto_rawDescData = file_messages_proto_wal_proto_rawDesc
+)
+
+func file_messages_proto_wal_proto_rawDescGZIP() []byte {
+	file_messages_proto_wal_proto_rawDescOnce.Do(func() {
+		file_messages_proto_wal_proto_rawDescData = protoimpl.X.CompressGZIP(file_messages_proto_wal_proto_rawDescData)
+	})
+	return file_messages_proto_wal_proto_rawDescData
+}
+
+var file_messages_proto_wal_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_messages_proto_wal_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_messages_proto_wal_proto_goTypes = []interface{}{
+	(WALEntry_Type)(0),          // 0: WALEntry.Type
+	(*WALEntry)(nil),            // 1: WALEntry
+	(*View)(nil),                // 2: View
+	(*Message)(nil),             // 3: Message
+	(*PreparedCertificate)(nil), // 4: PreparedCertificate
+	(*Proposal)(nil),            // 5: Proposal
+}
+var file_messages_proto_wal_proto_depIdxs = []int32{
+	0, // 0: WALEntry.type:type_name -> WALEntry.Type
+	2, // 1: WALEntry.view:type_name -> View
+	3, // 2: WALEntry.message:type_name -> Message
+	4, // 3: WALEntry.latestPreparedCertificate:type_name -> PreparedCertificate
+	5, // 4: WALEntry.latestPreparedProposal:type_name -> Proposal
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_messages_proto_wal_proto_init() }
+func file_messages_proto_wal_proto_init() {
+	if File_messages_proto_wal_proto != nil {
+		return
+	}
+	file_messages_proto_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_messages_proto_wal_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WALEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_messages_proto_wal_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_messages_proto_wal_proto_goTypes,
+		DependencyIndexes: file_messages_proto_wal_proto_depIdxs,
+		EnumInfos:         file_messages_proto_wal_proto_enumTypes,
+		MessageInfos:      file_messages_proto_wal_proto_msgTypes,
+	}.Build()
+	File_messages_proto_wal_proto = out.File
+	file_messages_proto_wal_proto_rawDesc = nil
+	file_messages_proto_wal_proto_goTypes = nil
+	file_messages_proto_wal_proto_depIdxs = nil
+}
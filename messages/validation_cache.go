@@ -0,0 +1,193 @@
+package messages
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// ValidationContext is what a message is validated against. The validation
+// results are only reused for the messages validated in the same context
+type ValidationContext struct {
+	// MessageType is the type of the validated messages
+	MessageType proto.MessageType
+
+	// Round is the round of the view the messages are validated in
+	Round uint64
+
+	// ProposalHash is the hash of the proposal the messages are validated against, if any
+	ProposalHash []byte
+}
+
+// validationKey identifies the validation of a message in a context
+type validationKey struct {
+	digest       [sha256.Size]byte
+	messageType  proto.MessageType
+	round        uint64
+	proposalHash string
+}
+
+// validationCache keeps the validation results, by the message height
+type validationCache struct {
+	sync.Mutex
+
+	results map[uint64]map[validationKey]bool
+}
+
+// get returns the cached validation result, if any
+func (c *validationCache) get(height uint64, key validationKey) (bool, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	result, ok := c.results[height][key]
+
+	return result, ok
+}
+
+// set caches the validation result
+func (c *validationCache) set(height uint64, key validationKey, result bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.results == nil {
+		c.results = make(map[uint64]map[validationKey]bool)
+	}
+
+	results, ok := c.results[height]
+	if !ok {
+		results = make(map[validationKey]bool)
+		c.results[height] = results
+	}
+
+	results[key] = result
+}
+
+// prune removes the validation results of the heights lower than the specified one
+func (c *validationCache) prune(height uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	for resultHeight := range c.results {
+		if resultHeight < height {
+			delete(c.results, resultHeight)
+		}
+	}
+}
+
+// messageDigests keeps the digests of the stored messages, by the message height.
+// The digest of a message is computed once, when it is added, instead of on each validation
+type messageDigests struct {
+	sync.RWMutex
+
+	digests map[uint64]map[*proto.Message][sha256.Size]byte
+}
+
+// get returns the digest of the stored message, if it is kept
+func (d *messageDigests) get(message *proto.Message) ([sha256.Size]byte, bool) {
+	d.RLock()
+	defer d.RUnlock()
+
+	digest, ok := d.digests[message.View.Height][message]
+
+	return digest, ok
+}
+
+// set keeps the digest of the stored message, if it is computed
+func (d *messageDigests) set(message *proto.Message, digest *[sha256.Size]byte) {
+	if digest == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.digests == nil {
+		d.digests = make(map[uint64]map[*proto.Message][sha256.Size]byte)
+	}
+
+	digests, ok := d.digests[message.View.Height]
+	if !ok {
+		digests = make(map[*proto.Message][sha256.Size]byte)
+		d.digests[message.View.Height] = digests
+	}
+
+	digests[message] = *digest
+}
+
+// remove drops the digest of the message, once it is removed from the store
+func (d *messageDigests) remove(message *proto.Message) {
+	d.Lock()
+	defer d.Unlock()
+
+	delete(d.digests[message.View.Height], message)
+
+	if len(d.digests[message.View.Height]) == 0 {
+		delete(d.digests, message.View.Height)
+	}
+}
+
+// prune drops the digests of the heights lower than the specified one
+func (d *messageDigests) prune(height uint64) {
+	d.Lock()
+	defer d.Unlock()
+
+	for digestHeight := range d.digests {
+		if digestHeight < height {
+			delete(d.digests, digestHeight)
+		}
+	}
+}
+
+// Digest returns the hash of the whole message, including its signature
+func Digest(message *proto.Message) ([sha256.Size]byte, error) {
+	raw, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(raw), nil
+}
+
+// CachedValidation returns the validation function, which caches the results of isValid
+// by the message digest and the validation context. That way each message is validated
+// at most once per context, instead of on each new message arrival. The digests of the stored
+// messages are computed when they are added, so only the other messages are hashed again.
+// The results are kept until the height of the messages is pruned
+func (ms *Messages) CachedValidation(
+	context ValidationContext,
+	isValid func(message *proto.Message) bool,
+) func(message *proto.Message) bool {
+	return func(message *proto.Message) bool {
+		if message.View == nil {
+			return isValid(message)
+		}
+
+		digest, ok := ms.digests.get(message)
+		if !ok {
+			var err error
+
+			if digest, err = Digest(message); err != nil {
+				return isValid(message)
+			}
+		}
+
+		key := validationKey{
+			digest:       digest,
+			messageType:  context.MessageType,
+			round:        context.Round,
+			proposalHash: string(context.ProposalHash),
+		}
+
+		if result, ok := ms.validations.get(message.View.Height, key); ok {
+			return result
+		}
+
+		result := isValid(message)
+		ms.validations.set(message.View.Height, key, result)
+
+		return result
+	}
+}
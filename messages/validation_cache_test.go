@@ -0,0 +1,108 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestMessages_CachedValidation(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMessages()
+	defer ms.Close()
+
+	view := &proto.View{Height: 1, Round: 0}
+
+	for _, sender := range []string{"node 1", "node 2", "node 3"} {
+		message := newPrepareMessage(sender, view, []byte("proposal hash"))
+		ms.AddMessage(message)
+
+		// Make sure the digest is computed when the message is added
+		expected, err := Digest(message)
+		require.NoError(t, err)
+
+		digest, ok := ms.digests.get(message)
+		assert.True(t, ok)
+		assert.Equal(t, expected, digest)
+	}
+
+	validations := 0
+	isValid := func(message *proto.Message) bool {
+		validations++
+
+		return string(message.From) != "node 3"
+	}
+
+	context := ValidationContext{
+		MessageType:  proto.MessageType_PREPARE,
+		Round:        view.Round,
+		ProposalHash: []byte("proposal hash"),
+	}
+
+	// Make sure each message is validated only once per context
+	for i := 0; i < 3; i++ {
+		msgs := ms.GetValidMessages(view, proto.MessageType_PREPARE, ms.CachedValidation(context, isValid))
+
+		assert.Len(t, msgs, 2)
+	}
+
+	assert.Equal(t, 3, validations)
+
+	// Make sure the digest of the pruned invalid message is dropped
+	assert.Len(t, ms.digests.digests[view.Height], 2)
+
+	// Make sure the messages are validated again in a different context
+	context.ProposalHash = []byte("other proposal hash")
+
+	msgs := ms.GetValidMessages(view, proto.MessageType_PREPARE, ms.CachedValidation(context, isValid))
+
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, 5, validations)
+
+	// Make sure the results are dropped with the pruned height
+	ms.PruneByHeight(2)
+
+	result, ok := ms.validations.get(view.Height, validationKey{})
+	assert.False(t, result)
+	assert.False(t, ok)
+	assert.Empty(t, ms.validations.results)
+	assert.Empty(t, ms.digests.digests)
+}
+
+func TestMessages_Digests_Replaced(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMessagesWithLimits(Limits{MaxMessagesPerSender: 1})
+	defer ms.Close()
+
+	var (
+		view   = &proto.View{Height: 1, Round: 1}
+		first  = newPrepareMessage("node 1", view, []byte("proposal hash"))
+		second = newPrepareMessage("node 1", view, []byte("proposal hash"))
+		next   = newPrepareMessage("node 1", &proto.View{Height: 1, Round: 0}, []byte("proposal hash"))
+	)
+
+	// Make sure the digest of the replaced message is dropped
+	ms.AddMessage(first)
+	ms.AddMessage(second)
+
+	_, ok := ms.digests.get(first)
+	assert.False(t, ok)
+
+	_, ok = ms.digests.get(second)
+	assert.True(t, ok)
+
+	// Make sure the digest of the evicted message is dropped
+	ms.SetView(&proto.View{Height: 1, Round: 0})
+	ms.AddMessage(next)
+
+	_, ok = ms.digests.get(second)
+	assert.False(t, ok)
+
+	_, ok = ms.digests.get(next)
+	assert.True(t, ok)
+}
@@ -0,0 +1,193 @@
+// Package proposer implements the selection of the proposer for each height and round
+package proposer
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+)
+
+// Validator is a validator with its voting power
+type Validator struct {
+	Address     []byte
+	VotingPower *big.Int
+}
+
+// Validators is the validator set, sorted by the validator addresses
+type Validators []Validator
+
+// NewValidators creates the validator set from the map of the validator addresses
+// on their voting power, as returned by the ValidatorBackend.
+// The validators without voting power are left out
+func NewValidators(votingPowers map[string]*big.Int) Validators {
+	validators := make(Validators, 0, len(votingPowers))
+
+	for address, votingPower := range votingPowers {
+		if votingPower == nil || votingPower.Sign() <= 0 {
+			continue
+		}
+
+		validators = append(validators, Validator{
+			Address:     []byte(address),
+			VotingPower: new(big.Int).Set(votingPower),
+		})
+	}
+
+	sort.Slice(validators, func(i, j int) bool {
+		return bytes.Compare(validators[i].Address, validators[j].Address) < 0
+	})
+
+	return validators
+}
+
+// TotalVotingPower returns the sum of the voting powers
+func (v Validators) TotalVotingPower() *big.Int {
+	total := big.NewInt(0)
+
+	for _, validator := range v {
+		total.Add(total, validator.VotingPower)
+	}
+
+	return total
+}
+
+// index returns the index of the validator with the address, or the index
+// the address would be inserted at, if the validator is not in the set
+func (v Validators) index(address []byte) int {
+	return sort.Search(len(v), func(i int) bool {
+		return bytes.Compare(v[i].Address, address) >= 0
+	})
+}
+
+// Finalization is the chain data of a finalized proposal, which the selectors derive the proposers
+// of the next height from. The backend persists it with the finalized proposal (see Adapter.Finalize),
+// so all the nodes select the same proposers, whichever finalizations they observed themselves
+type Finalization struct {
+	// Proposer is the address of the proposer of the finalized proposal, if any
+	Proposer []byte
+
+	// Priorities are the proposer priorities of the validators accumulated up to the next height,
+	// keyed by the validator addresses, if the selector accumulates them (see Accumulator)
+	Priorities map[string]*big.Int
+}
+
+// Selector selects the proposer from the validator set.
+// Implementations must be safe for concurrent use
+type Selector interface {
+	// Proposer returns the address of the proposer for the height and round, following
+	// the parent, which is the proposal finalized for the previous height.
+	// Nil is returned if the validator set is empty
+	Proposer(validators Validators, parent Finalization, height, round uint64) []byte
+}
+
+// Accumulator is implemented by the selectors which accumulate
+// the priorities of the validators over the heights
+type Accumulator interface {
+	// Accumulate returns the priorities of the validators for the height following the parent one
+	Accumulate(validators Validators, parent Finalization) map[string]*big.Int
+}
+
+// ChainBackend is implemented by the validator backends which persist the finalizations
+// with the finalized proposals. The selectors get the zero Finalization as the parent
+// if the validator backend doesn't implement it
+type ChainBackend interface {
+	// GetFinalization returns the finalization persisted with the proposal finalized for the height,
+	// or the zero Finalization if there is none, since the height precedes the first proposal
+	GetFinalization(height uint64) (Finalization, error)
+}
+
+// Adapter implements the IsProposer method of the core.Backend
+// on top of a selector. The validator set for each height is
+// fetched from the ValidatorBackend, like in the core.ValidatorManager
+type Adapter struct {
+	backend  core.ValidatorBackend
+	selector Selector
+
+	lock sync.Mutex
+
+	// height, validators and parent are the last fetched validator set,
+	// and the finalization of the previous height
+	height     uint64
+	validators Validators
+	parent     Finalization
+}
+
+// NewAdapter creates a new adapter for the selector
+func NewAdapter(backend core.ValidatorBackend, selector Selector) *Adapter {
+	return &Adapter{
+		backend:  backend,
+		selector: selector,
+	}
+}
+
+// Proposer returns the address of the proposer for the height and round
+func (a *Adapter) Proposer(height, round uint64) ([]byte, error) {
+	validators, parent, err := a.getChain(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.selector.Proposer(validators, parent, height, round), nil
+}
+
+// IsProposer checks if the passed in ID is the proposer for the height and round
+func (a *Adapter) IsProposer(id []byte, height, round uint64) bool {
+	proposer, err := a.Proposer(height, round)
+	if err != nil || proposer == nil {
+		return false
+	}
+
+	return bytes.Equal(id, proposer)
+}
+
+// Finalize returns the finalization of the proposal finalized for the height and round,
+// which the backend persists with the proposal, and returns through the ChainBackend
+func (a *Adapter) Finalize(height, round uint64) (Finalization, error) {
+	validators, parent, err := a.getChain(height)
+	if err != nil {
+		return Finalization{}, err
+	}
+
+	finalization := Finalization{
+		Proposer: a.selector.Proposer(validators, parent, height, round),
+	}
+
+	if accumulator, ok := a.selector.(Accumulator); ok {
+		finalization.Priorities = accumulator.Accumulate(validators, parent)
+	}
+
+	return finalization, nil
+}
+
+// getChain returns the validator set for the height, and the finalization
+// of the previous height, if the validator backend persists them
+func (a *Adapter) getChain(height uint64) (Validators, Finalization, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.validators != nil && a.height == height {
+		return a.validators, a.parent, nil
+	}
+
+	votingPowers, err := a.backend.GetVotingPowers(height)
+	if err != nil {
+		return nil, Finalization{}, err
+	}
+
+	var parent Finalization
+
+	if chain, ok := a.backend.(ChainBackend); ok && height > 0 {
+		if parent, err = chain.GetFinalization(height - 1); err != nil {
+			return nil, Finalization{}, err
+		}
+	}
+
+	a.height = height
+	a.validators = NewValidators(votingPowers)
+	a.parent = parent
+
+	return a.validators, a.parent, nil
+}
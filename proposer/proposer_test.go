@@ -0,0 +1,168 @@
+package proposer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBackend = errors.New("backend error")
+
+type mockValidatorBackend struct {
+	calls           int
+	getVotingPowers func(height uint64) (map[string]*big.Int, error)
+}
+
+func (m *mockValidatorBackend) GetVotingPowers(height uint64) (map[string]*big.Int, error) {
+	m.calls++
+
+	return m.getVotingPowers(height)
+}
+
+// mockChainBackend persists the finalizations of the heights
+type mockChainBackend struct {
+	mockValidatorBackend
+
+	finalizations map[uint64]Finalization
+}
+
+func (m *mockChainBackend) GetFinalization(height uint64) (Finalization, error) {
+	finalization, ok := m.finalizations[height]
+	if !ok && height > 0 {
+		return Finalization{}, errBackend
+	}
+
+	return finalization, nil
+}
+
+// newValidators creates the validator set with the passed in addresses and voting powers
+func newValidators(votingPowers map[string]int64) Validators {
+	powers := make(map[string]*big.Int, len(votingPowers))
+	for address, power := range votingPowers {
+		powers[address] = big.NewInt(power)
+	}
+
+	return NewValidators(powers)
+}
+
+// addresses returns the addresses of the proposers for the height and the rounds up to count
+func addresses(selector Selector, validators Validators, parent Finalization, height, count uint64) []string {
+	proposers := make([]string, 0, count)
+
+	for round := uint64(0); round < count; round++ {
+		proposers = append(proposers, string(selector.Proposer(validators, parent, height, round)))
+	}
+
+	return proposers
+}
+
+func TestNewValidators(t *testing.T) {
+	t.Parallel()
+
+	validators := NewValidators(map[string]*big.Int{
+		"C": big.NewInt(3),
+		"A": big.NewInt(1),
+		"D": big.NewInt(0),
+		"B": big.NewInt(2),
+		"E": nil,
+	})
+
+	require.Len(t, validators, 3)
+	assert.Equal(t, []byte("A"), validators[0].Address)
+	assert.Equal(t, []byte("B"), validators[1].Address)
+	assert.Equal(t, []byte("C"), validators[2].Address)
+	assert.Equal(t, big.NewInt(6), validators.TotalVotingPower())
+}
+
+func TestAdapter_IsProposer(t *testing.T) {
+	t.Parallel()
+
+	backend := &mockValidatorBackend{
+		getVotingPowers: func(height uint64) (map[string]*big.Int, error) {
+			if height == 0 {
+				return nil, errBackend
+			}
+
+			return map[string]*big.Int{
+				"A": big.NewInt(1),
+				"B": big.NewInt(1),
+			}, nil
+		},
+	}
+	adapter := NewAdapter(backend, RoundRobin{})
+
+	assert.True(t, adapter.IsProposer([]byte("B"), 1, 0))
+	assert.True(t, adapter.IsProposer([]byte("A"), 1, 1))
+	assert.False(t, adapter.IsProposer([]byte("A"), 1, 0))
+	assert.False(t, adapter.IsProposer([]byte("C"), 1, 0))
+
+	// Make sure the validator set is fetched once per height
+	assert.Equal(t, 1, backend.calls)
+
+	// Make sure there is no proposer if the validator set is unknown
+	assert.False(t, adapter.IsProposer([]byte("A"), 0, 0))
+	assert.False(t, adapter.IsProposer([]byte("B"), 0, 0))
+
+	_, err := adapter.Proposer(0, 0)
+	assert.ErrorIs(t, err, errBackend)
+}
+
+func TestAdapter_Finalize(t *testing.T) {
+	t.Parallel()
+
+	votingPowers := func(_ uint64) (map[string]*big.Int, error) {
+		return map[string]*big.Int{
+			"A": big.NewInt(1),
+			"B": big.NewInt(2),
+			"C": big.NewInt(3),
+		}, nil
+	}
+
+	// Make sure the parent is the zero finalization if the backend doesn't persist them
+	finalization, err := NewAdapter(&mockValidatorBackend{getVotingPowers: votingPowers}, Sticky{}).Finalize(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Finalization{Proposer: []byte("B")}, finalization)
+
+	backend := &mockChainBackend{
+		mockValidatorBackend: mockValidatorBackend{getVotingPowers: votingPowers},
+		finalizations:        make(map[uint64]Finalization),
+	}
+
+	// The proposal of B is finalized in round 1, so B is kept as the proposer
+	sticky := NewAdapter(backend, Sticky{})
+	assert.True(t, sticky.IsProposer([]byte("A"), 1, 0))
+
+	finalization, err = sticky.Finalize(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Finalization{Proposer: []byte("B")}, finalization)
+
+	backend.finalizations[1] = finalization
+
+	assert.True(t, sticky.IsProposer([]byte("B"), 2, 0))
+	assert.True(t, sticky.IsProposer([]byte("C"), 2, 1))
+
+	// Make sure the priorities are accumulated, if the selector accumulates them
+	weighted := NewAdapter(backend, WeightedRoundRobin{})
+
+	finalization, err = weighted.Finalize(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("C"), finalization.Proposer)
+	assert.Equal(
+		t,
+		map[string]*big.Int{"A": big.NewInt(1), "B": big.NewInt(2), "C": big.NewInt(-3)},
+		finalization.Priorities,
+	)
+
+	backend.finalizations[2] = finalization
+
+	assert.True(t, weighted.IsProposer([]byte("B"), 3, 0))
+
+	// Make sure there is no proposer if the parent finalization is unknown
+	assert.False(t, weighted.IsProposer([]byte("C"), 5, 0))
+
+	_, err = weighted.Finalize(5, 0)
+	assert.ErrorIs(t, err, errBackend)
+}
@@ -0,0 +1,14 @@
+package proposer
+
+// RoundRobin selects the validators in turns, moving
+// to the next validator on each height and round
+type RoundRobin struct{}
+
+// Proposer returns the address of the round robin proposer for the height and round
+func (RoundRobin) Proposer(validators Validators, _ Finalization, height, round uint64) []byte {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	return validators[(height+round)%uint64(len(validators))].Address
+}
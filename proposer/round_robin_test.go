@@ -0,0 +1,17 @@
+package proposer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobin_Proposer(t *testing.T) {
+	t.Parallel()
+
+	validators := newValidators(map[string]int64{"A": 1, "B": 10, "C": 100})
+
+	assert.Equal(t, []string{"A", "B", "C", "A"}, addresses(RoundRobin{}, validators, Finalization{}, 0, 4))
+	assert.Equal(t, []string{"B", "C", "A", "B"}, addresses(RoundRobin{}, validators, Finalization{}, 1, 4))
+	assert.Nil(t, RoundRobin{}.Proposer(nil, Finalization{}, 0, 0))
+}
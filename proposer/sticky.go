@@ -0,0 +1,19 @@
+package proposer
+
+// Sticky keeps the proposer of the parent proposal for the next height,
+// and moves to the next validator only on each failed round.
+// The first validator is the proposer until a proposal is finalized
+type Sticky struct{}
+
+// Proposer returns the address of the sticky proposer for the height and round
+func (Sticky) Proposer(validators Validators, parent Finalization, _, round uint64) []byte {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	// If the parent proposer is not a validator anymore,
+	// the next one in the order takes its place
+	start := uint64(validators.index(parent.Proposer))
+
+	return validators[(start+round)%uint64(len(validators))].Address
+}
@@ -0,0 +1,33 @@
+package proposer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSticky_Proposer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sticky     = Sticky{}
+		validators = newValidators(map[string]int64{"A": 1, "B": 1, "C": 1})
+	)
+
+	assert.Nil(t, sticky.Proposer(nil, Finalization{}, 0, 0))
+
+	// Make sure the first validator is the proposer until a proposal is finalized
+	assert.Equal(t, []string{"A", "B", "C", "A"}, addresses(sticky, validators, Finalization{}, 1, 4))
+
+	// Make sure the proposer of the parent proposal is kept for the next height
+	parent := Finalization{Proposer: []byte("C")}
+	assert.Equal(t, []string{"C", "A", "B"}, addresses(sticky, validators, parent, 2, 3))
+	assert.Equal(t, []string{"C", "A"}, addresses(sticky, validators, parent, 3, 2))
+
+	// Make sure the next validator takes the place of a removed proposer
+	validators = newValidators(map[string]int64{"A": 1, "B": 1, "D": 1})
+	assert.Equal(t, []string{"D", "A"}, addresses(sticky, validators, parent, 2, 2))
+
+	validators = newValidators(map[string]int64{"A": 1, "B": 1})
+	assert.Equal(t, []string{"A", "B"}, addresses(sticky, validators, parent, 2, 2))
+}
@@ -0,0 +1,90 @@
+package proposer
+
+import (
+	"math/big"
+)
+
+// WeightedRoundRobin selects the validators in proportion to their voting power,
+// with the priority accumulation of Tendermint: on each height and round the priority
+// of each validator grows by its voting power, the validator with the highest priority
+// is selected, and its priority is decreased by the total voting power.
+//
+// The priorities accumulated up to each height are persisted with the parent proposal
+// (see Accumulator), so only the rounds of the height are accumulated for.
+// Each height accumulates the priorities once, whichever round its proposal
+// was finalized in, like in Tendermint. The validators without accumulated
+// priorities, like the ones joining the validator set, start with zero priority
+type WeightedRoundRobin struct{}
+
+var _ Accumulator = WeightedRoundRobin{}
+
+// Proposer returns the address of the weighted round robin proposer for the height and round
+func (WeightedRoundRobin) Proposer(validators Validators, parent Finalization, _, round uint64) []byte {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	var (
+		priorities = parentPriorities(validators, parent)
+		total      = validators.TotalVotingPower()
+		proposer   int
+	)
+
+	for slot := uint64(0); slot <= round; slot++ {
+		proposer = accumulate(validators, priorities, total)
+	}
+
+	return validators[proposer].Address
+}
+
+// Accumulate returns the priorities accumulated up to the height following the parent one
+func (WeightedRoundRobin) Accumulate(validators Validators, parent Finalization) map[string]*big.Int {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	priorities := parentPriorities(validators, parent)
+	accumulate(validators, priorities, validators.TotalVotingPower())
+
+	accumulated := make(map[string]*big.Int, len(validators))
+	for index, validator := range validators {
+		accumulated[string(validator.Address)] = priorities[index]
+	}
+
+	return accumulated
+}
+
+// parentPriorities returns a copy of the priorities accumulated up to the height,
+// in the order of the validators
+func parentPriorities(validators Validators, parent Finalization) []*big.Int {
+	priorities := make([]*big.Int, len(validators))
+
+	for index, validator := range validators {
+		priorities[index] = new(big.Int)
+
+		if priority, ok := parent.Priorities[string(validator.Address)]; ok && priority != nil {
+			priorities[index].Set(priority)
+		}
+	}
+
+	return priorities
+}
+
+// accumulate accumulates the priorities for the next slot,
+// and returns the index of the selected validator
+func accumulate(validators Validators, priorities []*big.Int, total *big.Int) int {
+	selected := 0
+
+	for index, priority := range priorities {
+		priority.Add(priority, validators[index].VotingPower)
+
+		// Ties are won by the lower address
+		if priority.Cmp(priorities[selected]) > 0 {
+			selected = index
+		}
+	}
+
+	priorities[selected].Sub(priorities[selected], total)
+
+	return selected
+}
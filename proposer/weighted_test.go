@@ -0,0 +1,109 @@
+package proposer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedRoundRobin_Proposer(t *testing.T) {
+	t.Parallel()
+
+	weighted := WeightedRoundRobin{}
+
+	assert.Nil(t, weighted.Proposer(nil, Finalization{}, 0, 0))
+	assert.Nil(t, weighted.Accumulate(nil, Finalization{}))
+
+	// Make sure the equal voting powers are selected in turns
+	validators := newValidators(map[string]int64{"A": 10, "B": 10, "C": 10})
+	assert.Equal(t, []string{"A", "B", "C", "A"}, addresses(weighted, validators, Finalization{}, 0, 4))
+
+	// Make sure the selections are spread in proportion to the voting power
+	validators = newValidators(map[string]int64{"A": 1, "B": 2, "C": 3})
+	assert.Equal(
+		t,
+		[]string{"C", "B", "A", "C", "B", "C", "C"},
+		addresses(weighted, validators, Finalization{}, 0, 7),
+	)
+
+	// Make sure the rounds continue from the priorities of the parent
+	parent := Finalization{Priorities: map[string]*big.Int{"A": big.NewInt(1), "B": big.NewInt(2), "C": big.NewInt(-3)}}
+	assert.Equal(t, []string{"B", "A", "C"}, addresses(weighted, validators, parent, 1, 3))
+}
+
+func TestWeightedRoundRobin_Accumulate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		weighted   = WeightedRoundRobin{}
+		validators = newValidators(map[string]int64{"A": 5, "B": 3, "C": 1, "D": 7})
+		parents    = make([]Finalization, 64)
+		expected   = make([][]byte, len(parents))
+		counts     = make(map[string]int)
+	)
+
+	for height := range parents {
+		if height > 0 {
+			parents[height] = Finalization{Priorities: weighted.Accumulate(validators, parents[height-1])}
+		}
+
+		expected[height] = weighted.Proposer(validators, parents[height], uint64(height), 0)
+		counts[string(expected[height])]++
+	}
+
+	// Make sure each period selects the validators as many times as their voting power
+	assert.Equal(t, map[string]int{"A": 20, "B": 12, "C": 4, "D": 28}, counts)
+
+	// Make sure the rounds of a height select the proposers of the next heights
+	for round := uint64(0); round < 16; round++ {
+		assert.Equal(t, expected[17+round], weighted.Proposer(validators, parents[17], 17, round))
+	}
+
+	// Make sure the joining validators start with zero priority, and the leaving ones are dropped
+	var (
+		changed = newValidators(map[string]int64{"A": 5, "B": 3, "E": 2})
+		parent  = Finalization{Priorities: map[string]*big.Int{"A": big.NewInt(1), "B": big.NewInt(2), "D": big.NewInt(-3)}}
+	)
+
+	assert.Equal(
+		t,
+		map[string]*big.Int{"A": big.NewInt(-4), "B": big.NewInt(5), "E": big.NewInt(2)},
+		weighted.Accumulate(changed, parent),
+	)
+}
+
+func TestWeightedRoundRobin_LargeVotingPower(t *testing.T) {
+	t.Parallel()
+
+	power, _ := new(big.Int).SetString("1000000000000000000000", 10)
+
+	validators := NewValidators(map[string]*big.Int{
+		"A": power,
+		"B": new(big.Int).Mul(power, big.NewInt(2)),
+	})
+
+	// Make sure the large voting powers are accumulated without overflows
+	assert.Equal(
+		t,
+		[]string{"B", "A", "B", "B"},
+		addresses(WeightedRoundRobin{}, validators, Finalization{}, 1<<62, 4),
+	)
+}
+
+func BenchmarkWeightedRoundRobin_Height(b *testing.B) {
+	var (
+		validators = newValidators(map[string]int64{"A": 1000003, "B": 1000033, "C": 1000037, "D": 1000039})
+		weighted   = WeightedRoundRobin{}
+		parent     = Finalization{}
+	)
+
+	for n := 0; n < b.N; n++ {
+		// Each height goes through a few rounds, and accumulates the priorities for the next one
+		for round := uint64(0); round < 4; round++ {
+			weighted.Proposer(validators, parent, uint64(n), round)
+		}
+
+		parent.Priorities = weighted.Accumulate(validators, parent)
+	}
+}
@@ -0,0 +1,225 @@
+package simulator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+	"github.com/Hydra-Chain/go-ibft/proposer"
+)
+
+// NodeAddress returns the address of the node with the index
+func NodeAddress(index int) []byte {
+	return []byte(fmt.Sprintf("node %d", index))
+}
+
+// ProposalHash returns the hash of the proposal, which commits to its round
+func ProposalHash(proposal *proto.Proposal) []byte {
+	hash := sha256.New()
+
+	hash.Write(proposal.GetRawProposal())
+	_ = binary.Write(hash, binary.BigEndian, proposal.GetRound())
+
+	return hash.Sum(nil)
+}
+
+// committedSeal returns the seal of the signer for the proposal hash.
+// The simulated nodes are trusted, so the seal is not an actual signature
+func committedSeal(signer, proposalHash []byte) []byte {
+	seal := sha256.Sum256(append(append([]byte{}, signer...), proposalHash...))
+
+	return seal[:]
+}
+
+// backend is the backend of a simulated node. All nodes
+// have the same voting power, and the proposers are picked in turns
+type backend struct {
+	*proposer.Adapter
+
+	index int
+	nodes int
+
+	// insertFn is called with the proposals finalized by the node
+	insertFn func(index int, proposal *proto.Proposal, committedSeals []*messages.CommittedSeal)
+}
+
+var _ core.Backend = &backend{}
+
+// newBackend creates the backend of the node with the index
+func newBackend(
+	index, nodes int,
+	insertFn func(index int, proposal *proto.Proposal, committedSeals []*messages.CommittedSeal),
+) *backend {
+	b := &backend{
+		index:    index,
+		nodes:    nodes,
+		insertFn: insertFn,
+	}
+
+	b.Adapter = proposer.NewAdapter(b, proposer.RoundRobin{})
+
+	return b
+}
+
+// ID returns the address of the node
+func (b *backend) ID() []byte {
+	return NodeAddress(b.index)
+}
+
+// GetVotingPowers returns the same voting power for all nodes
+func (b *backend) GetVotingPowers(_ uint64) (map[string]*big.Int, error) {
+	votingPowers := make(map[string]*big.Int, b.nodes)
+
+	for index := 0; index < b.nodes; index++ {
+		votingPowers[string(NodeAddress(index))] = big.NewInt(1)
+	}
+
+	return votingPowers, nil
+}
+
+// StartRound is a no-op, since the simulated nodes have no round specific state
+func (b *backend) StartRound(_ *proto.View) error {
+	return nil
+}
+
+// BuildProposal builds a proposal unique to the view and the proposer
+func (b *backend) BuildProposal(view *proto.View) []byte {
+	return []byte(fmt.Sprintf("block %d by %s in round %d", view.Height, b.ID(), view.Round))
+}
+
+// InsertProposal passes the finalized proposal to the simulator
+func (b *backend) InsertProposal(proposal *proto.Proposal, committedSeals []*messages.CommittedSeal) error {
+	b.insertFn(b.index, proposal, committedSeals)
+
+	return nil
+}
+
+// ReportEquivocation is a no-op, since the simulated nodes are honest
+func (b *backend) ReportEquivocation(_ *messages.Evidence) {}
+
+// IsValidProposal checks if the proposal is set
+func (b *backend) IsValidProposal(rawProposal []byte) bool {
+	return len(rawProposal) > 0
+}
+
+// IsValidValidator checks if the sender is one of the nodes
+func (b *backend) IsValidValidator(msg *proto.Message) bool {
+	for index := 0; index < b.nodes; index++ {
+		if bytes.Equal(msg.From, NodeAddress(index)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValidProposalHash checks if the hash matches the proposal
+func (b *backend) IsValidProposalHash(proposal *proto.Proposal, hash []byte) bool {
+	return proposal != nil && bytes.Equal(ProposalHash(proposal), hash)
+}
+
+// IsValidCommittedSeal checks if the seal was created by the signer for the proposal hash
+func (b *backend) IsValidCommittedSeal(proposalHash []byte, seal *messages.CommittedSeal) bool {
+	return bytes.Equal(seal.Signature, committedSeal(seal.Signer, proposalHash))
+}
+
+// BuildPrePrepareMessage builds a PREPREPARE message for the proposal
+func (b *backend) BuildPrePrepareMessage(
+	rawProposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	proposal := &proto.Proposal{
+		RawProposal: rawProposal,
+		Round:       view.Round,
+	}
+
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     proposal,
+				ProposalHash: ProposalHash(proposal),
+				Certificate:  certificate,
+			},
+		},
+	}
+}
+
+// BuildPrepareMessage builds a PREPARE message for the proposal hash
+func (b *backend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPARE,
+		Payload: &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: proposalHash,
+			},
+		},
+	}
+}
+
+// BuildCommitMessage builds a COMMIT message with the committed seal of the node
+func (b *backend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_COMMIT,
+		Payload: &proto.Message_CommitData{
+			CommitData: &proto.CommitMessage{
+				ProposalHash:  proposalHash,
+				CommittedSeal: committedSeal(b.ID(), proposalHash),
+			},
+		},
+	}
+}
+
+// BuildRoundChangeMessage builds a ROUND_CHANGE message
+// with the latest prepared proposal and certificate
+func (b *backend) BuildRoundChangeMessage(
+	proposal *proto.Proposal,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_ROUND_CHANGE,
+		Payload: &proto.Message_RoundChangeData{
+			RoundChangeData: &proto.RoundChangeMessage{
+				LastPreparedProposal:      proposal,
+				LatestPreparedCertificate: certificate,
+			},
+		},
+	}
+}
+
+// transport multicasts the messages of a node through the simulated network
+type transport struct {
+	index int
+
+	// multicastFn sends the message of the node to all nodes
+	multicastFn func(from int, message *proto.Message)
+}
+
+// Multicast sends the message to all nodes through the network
+func (t *transport) Multicast(message *proto.Message) {
+	t.multicastFn(t.index, message)
+}
+
+// nopLogger drops the log messages
+type nopLogger struct{}
+
+func (nopLogger) Info(_ string, _ ...any) {}
+
+func (nopLogger) Debug(_ string, _ ...any) {}
+
+func (nopLogger) Error(_ string, _ ...any) {}
@@ -0,0 +1,558 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// Invariant is an IBFT invariant checked by the Checker
+type Invariant string
+
+const (
+	// InvariantAgreement is violated when different proposals are finalized at the same height
+	InvariantAgreement Invariant = "agreement"
+
+	// InvariantNoEquivocation is violated when an honest node sends
+	// conflicting PREPARE or COMMIT messages for the same view
+	InvariantNoEquivocation Invariant = "no-equivocation"
+
+	// InvariantLock is violated when an honest node proposes or prepares a different proposal
+	// in a later round than the one a quorum of nodes prepared in an earlier round of the height
+	InvariantLock Invariant = "lock"
+
+	// InvariantLiveness is violated when an honest node doesn't finalize
+	// the height within the liveness bound after the GST
+	InvariantLiveness Invariant = "liveness"
+)
+
+// EventType is the type of the event observed by the Checker
+type EventType string
+
+const (
+	// EventHeightStarted is the start of a height
+	EventHeightStarted EventType = "height-started"
+
+	// EventMessageSent is a message multicasted by a node
+	EventMessageSent EventType = "message-sent"
+
+	// EventProposalInserted is a proposal finalized by a node
+	EventProposalInserted EventType = "proposal-inserted"
+)
+
+// Event is an event observed by the Checker
+type Event struct {
+	// Type is the type of the event
+	Type EventType
+
+	// Index is the position of the event in the trace
+	Index int
+
+	// Offset is the time of the event, relative to the first observed event
+	Offset time.Duration
+
+	// Node is the index of the node the event belongs to.
+	// It is -1 for the height starts
+	Node int
+
+	// Height is the height of the event
+	Height uint64
+
+	// Message is the message multicasted by the node, if any
+	Message *proto.Message
+
+	// Proposal is the proposal finalized by the node, if any
+	Proposal *proto.Proposal
+}
+
+// String returns the description of the event
+func (e Event) String() string {
+	switch e.Type {
+	case EventMessageSent:
+		return fmt.Sprintf(
+			"%s node %d sent %s for height %d, round %d",
+			e.Offset, e.Node, e.Message.Type, e.Message.GetView().GetHeight(), e.Message.GetView().GetRound(),
+		)
+	case EventProposalInserted:
+		return fmt.Sprintf("%s node %d finalized height %d in round %d", e.Offset, e.Node, e.Height, e.Proposal.GetRound())
+	default:
+		return fmt.Sprintf("%s height %d started", e.Offset, e.Height)
+	}
+}
+
+// Violation is the violation of an invariant, with the minimal trace of the events proving it
+type Violation struct {
+	// Invariant is the violated invariant
+	Invariant Invariant
+
+	// Height is the height the invariant was violated at
+	Height uint64
+
+	// Description is the description of the violation
+	Description string
+
+	// Trace are the observed events which prove the violation, in the order they were observed
+	Trace []Event
+}
+
+// String returns the description of the violation, followed by its trace
+func (v Violation) String() string {
+	description := fmt.Sprintf("%s violated at height %d: %s", v.Invariant, v.Height, v.Description)
+
+	for _, event := range v.Trace {
+		description += "\n\t" + event.String()
+	}
+
+	return description
+}
+
+// CheckerConfig is the configuration of the Checker
+type CheckerConfig struct {
+	// Nodes is the number of nodes in the cluster
+	Nodes int
+
+	// Byzantine are the indexes of the nodes which are not expected to follow the protocol
+	Byzantine []int
+
+	// GST is the global stabilization time, relative to the start of the first height.
+	// The network is expected to deliver the messages in a timely manner after it
+	GST time.Duration
+
+	// LivenessBound is the time the honest nodes need to finalize a height
+	// after the height start, or after the GST. The liveness is not checked if it is not set
+	LivenessBound time.Duration
+}
+
+// viewVote identifies the vote of a node in a view
+type viewVote struct {
+	node        int
+	height      uint64
+	round       uint64
+	messageType proto.MessageType
+}
+
+// proposalVote identifies the votes for a proposal in a view
+type proposalVote struct {
+	height       uint64
+	round        uint64
+	proposalHash string
+}
+
+// lock is the proposal a quorum of nodes prepared in a round.
+// The nodes which observe the prepared certificate lock on it
+type lock struct {
+	round       uint64
+	rawProposal []byte
+
+	// trace are the PREPREPARE and PREPARE events which established the lock
+	trace []Event
+}
+
+// Checker observes the simulation runs, and checks the IBFT invariants:
+// the agreement on the finalized proposals, the absence of the conflicting votes of the honest nodes,
+// the respect of the locked proposals across the rounds, and the liveness after the GST.
+// It is added to the observers of the simulation configuration
+type Checker struct {
+	sync.Mutex
+
+	config     CheckerConfig
+	byzantine  map[int]struct{}
+	validators *core.ValidatorManager
+
+	started time.Time
+	trace   []Event
+
+	// heightStarts are the height start events, by the height
+	heightStarts map[uint64]Event
+
+	// inserted are the finalization events, by the height and the node index
+	inserted map[uint64]map[int]Event
+
+	// votes are the PREPARE and COMMIT events of the nodes, by the vote
+	votes map[viewVote]Event
+
+	// preprepares are the PREPREPARE events, by the proposal they propose
+	preprepares map[proposalVote]Event
+
+	// prepares are the PREPARE events for each proposal, by the sender address
+	prepares map[proposalVote]map[string]Event
+
+	// proposals are the raw proposals of the PREPREPARE messages, by the proposal hash
+	proposals map[string][]byte
+
+	// votesByHeight are the PREPREPARE and PREPARE events of the honest nodes, by the height
+	votesByHeight map[uint64][]Event
+
+	// locks are the locks of the rounds which reached the prepare quorum, by the height
+	locks map[uint64]map[uint64]*lock
+
+	violations []Violation
+}
+
+var _ Observer = &Checker{}
+
+// NewChecker creates the checker of the cluster
+func NewChecker(config CheckerConfig) (*Checker, error) {
+	validators := core.NewValidatorManager(newBackend(0, config.Nodes, nil), nopLogger{})
+
+	// All nodes have the same voting power at each height
+	if err := validators.Init(0); err != nil {
+		return nil, err
+	}
+
+	byzantine := make(map[int]struct{}, len(config.Byzantine))
+	for _, node := range config.Byzantine {
+		byzantine[node] = struct{}{}
+	}
+
+	return &Checker{
+		config:        config,
+		byzantine:     byzantine,
+		validators:    validators,
+		heightStarts:  make(map[uint64]Event),
+		inserted:      make(map[uint64]map[int]Event),
+		votes:         make(map[viewVote]Event),
+		preprepares:   make(map[proposalVote]Event),
+		prepares:      make(map[proposalVote]map[string]Event),
+		proposals:     make(map[string][]byte),
+		votesByHeight: make(map[uint64][]Event),
+		locks:         make(map[uint64]map[uint64]*lock),
+	}, nil
+}
+
+// Violations returns the violations found so far
+func (c *Checker) Violations() []Violation {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]Violation{}, c.violations...)
+}
+
+// Trace returns all observed events, in the order they were observed
+func (c *Checker) Trace() []Event {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]Event{}, c.trace...)
+}
+
+// HeightStarted records the start of the height
+func (c *Checker) HeightStarted(height uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	event := c.record(Event{
+		Type:   EventHeightStarted,
+		Node:   -1,
+		Height: height,
+	})
+
+	c.heightStarts[height] = event
+}
+
+// MessageSent checks the message for the conflicting votes, and the violations of the locks
+func (c *Checker) MessageSent(node int, message *proto.Message) {
+	c.Lock()
+	defer c.Unlock()
+
+	event := c.record(Event{
+		Type:    EventMessageSent,
+		Node:    node,
+		Height:  message.GetView().GetHeight(),
+		Message: message,
+	})
+
+	switch message.Type {
+	case proto.MessageType_PREPREPARE:
+		preprepare := message.GetPreprepareData()
+
+		c.proposals[string(preprepare.GetProposalHash())] = preprepare.GetProposal().GetRawProposal()
+
+		c.checkLockedVote(event)
+		c.addPreprepare(event)
+	case proto.MessageType_PREPARE:
+		c.checkEquivocation(event)
+		c.checkLockedVote(event)
+		c.addPrepare(event)
+	case proto.MessageType_COMMIT:
+		c.checkEquivocation(event)
+	case proto.MessageType_ROUND_CHANGE:
+		return
+	}
+}
+
+// ProposalInserted checks the finalized proposal against the ones finalized by the other nodes
+func (c *Checker) ProposalInserted(node int, height uint64, proposal *proto.Proposal) {
+	c.Lock()
+	defer c.Unlock()
+
+	event := c.record(Event{
+		Type:     EventProposalInserted,
+		Node:     node,
+		Height:   height,
+		Proposal: proposal,
+	})
+
+	if c.inserted[height] == nil {
+		c.inserted[height] = make(map[int]Event)
+	}
+
+	for _, other := range c.inserted[height] {
+		if string(other.Proposal.GetRawProposal()) != string(proposal.GetRawProposal()) {
+			c.violate(InvariantAgreement, height, fmt.Sprintf(
+				"nodes %d and %d finalized different proposals", other.Node, node,
+			), other, event)
+
+			break
+		}
+	}
+
+	c.inserted[height][node] = event
+}
+
+// HeightFinished checks if the honest nodes finalized the height within the liveness bound
+func (c *Checker) HeightFinished(report HeightReport) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.config.LivenessBound == 0 {
+		return
+	}
+
+	start, ok := c.heightStarts[report.Height]
+	if !ok {
+		return
+	}
+
+	deadline := start.Offset
+	if deadline < c.config.GST {
+		deadline = c.config.GST
+	}
+
+	deadline += c.config.LivenessBound
+
+	for node := 0; node < c.config.Nodes; node++ {
+		if c.isByzantine(node) {
+			continue
+		}
+
+		inserted, ok := c.inserted[report.Height][node]
+
+		switch {
+		case !ok:
+			c.violate(InvariantLiveness, report.Height, fmt.Sprintf(
+				"node %d didn't finalize the height by %s", node, deadline,
+			), start)
+		case inserted.Offset > deadline:
+			c.violate(InvariantLiveness, report.Height, fmt.Sprintf(
+				"node %d finalized the height after %s", node, deadline,
+			), start, inserted)
+		}
+	}
+}
+
+// record adds the event to the trace.
+// The caller must hold the lock
+func (c *Checker) record(event Event) Event {
+	if c.started.IsZero() {
+		c.started = time.Now()
+	}
+
+	event.Index = len(c.trace)
+	event.Offset = time.Since(c.started)
+	c.trace = append(c.trace, event)
+
+	return event
+}
+
+// violate records the violation of the invariant.
+// The caller must hold the lock
+func (c *Checker) violate(invariant Invariant, height uint64, description string, trace ...Event) {
+	c.violations = append(c.violations, Violation{
+		Invariant:   invariant,
+		Height:      height,
+		Description: description,
+		Trace:       trace,
+	})
+}
+
+// isByzantine checks if the node is not expected to follow the protocol
+func (c *Checker) isByzantine(node int) bool {
+	_, ok := c.byzantine[node]
+
+	return ok
+}
+
+// checkEquivocation checks if the honest node already voted for a different proposal in the view.
+// The caller must hold the lock
+func (c *Checker) checkEquivocation(event Event) {
+	if c.isByzantine(event.Node) {
+		return
+	}
+
+	vote := viewVote{
+		node:        event.Node,
+		height:      event.Height,
+		round:       event.Message.GetView().GetRound(),
+		messageType: event.Message.Type,
+	}
+
+	previous, ok := c.votes[vote]
+	if !ok {
+		c.votes[vote] = event
+
+		return
+	}
+
+	if string(messages.ExtractMessageHash(previous.Message)) != string(messages.ExtractMessageHash(event.Message)) {
+		c.violate(InvariantNoEquivocation, event.Height, fmt.Sprintf(
+			"node %d sent conflicting %s messages in round %d", event.Node, vote.messageType, vote.round,
+		), previous, event)
+	}
+}
+
+// newProposalVote returns the proposal the PREPREPARE or PREPARE of the event votes for
+func newProposalVote(event Event) proposalVote {
+	return proposalVote{
+		height:       event.Height,
+		round:        event.Message.GetView().GetRound(),
+		proposalHash: string(messages.ExtractMessageHash(event.Message)),
+	}
+}
+
+// addPreprepare adds the PREPREPARE to the proposals of the round,
+// and checks if the PREPARE messages already observed for it prepared the proposal.
+// The caller must hold the lock
+func (c *Checker) addPreprepare(event Event) {
+	vote := newProposalVote(event)
+
+	if _, ok := c.preprepares[vote]; ok {
+		return
+	}
+
+	c.preprepares[vote] = event
+
+	c.checkPrepared(vote)
+}
+
+// addPrepare adds the PREPARE to the prepares for its proposal,
+// and checks if the proposal was prepared.
+// The caller must hold the lock
+func (c *Checker) addPrepare(event Event) {
+	vote := newProposalVote(event)
+
+	if c.prepares[vote] == nil {
+		c.prepares[vote] = make(map[string]Event)
+	}
+
+	c.prepares[vote][string(event.Message.From)] = event
+
+	c.checkPrepared(vote)
+}
+
+// checkPrepared locks the proposal in its round, once the PREPREPARE and the PREPARE messages
+// for it reached the quorum, the same way the nodes build the prepared certificate.
+// The caller must hold the lock
+func (c *Checker) checkPrepared(vote proposalVote) {
+	if _, locked := c.locks[vote.height][vote.round]; locked {
+		return
+	}
+
+	preprepare, ok := c.preprepares[vote]
+	if !ok {
+		return
+	}
+
+	rawProposal, ok := c.proposals[vote.proposalHash]
+	if !ok {
+		return
+	}
+
+	// The proposer doesn't send a PREPARE, its PREPREPARE counts towards the quorum instead
+	senders := map[string]struct{}{
+		string(preprepare.Message.From): {},
+	}
+
+	for sender := range c.prepares[vote] {
+		senders[sender] = struct{}{}
+	}
+
+	if !c.validators.HasQuorum(senders) {
+		return
+	}
+
+	l := &lock{
+		round:       vote.round,
+		rawProposal: rawProposal,
+		trace:       make([]Event, 0, len(senders)),
+	}
+
+	l.trace = append(l.trace, preprepare)
+
+	for sender, prepare := range c.prepares[vote] {
+		if sender != string(preprepare.Message.From) {
+			l.trace = append(l.trace, prepare)
+		}
+	}
+
+	sortEvents(l.trace)
+
+	if c.locks[vote.height] == nil {
+		c.locks[vote.height] = make(map[uint64]*lock)
+	}
+
+	c.locks[vote.height][vote.round] = l
+
+	// The votes of the later rounds may have been observed before the lock was established
+	for _, previous := range c.votesByHeight[vote.height] {
+		c.checkLock(l, previous)
+	}
+}
+
+// checkLockedVote checks the PREPREPARE or PREPARE of the honest node
+// against the locks of the earlier rounds at the height, and keeps it for the locks established later.
+// The caller must hold the lock
+func (c *Checker) checkLockedVote(event Event) {
+	if c.isByzantine(event.Node) {
+		return
+	}
+
+	c.votesByHeight[event.Height] = append(c.votesByHeight[event.Height], event)
+
+	for _, l := range c.locks[event.Height] {
+		c.checkLock(l, event)
+	}
+}
+
+// checkLock checks if the vote of a later round than the lock is for the locked proposal.
+// The caller must hold the lock
+func (c *Checker) checkLock(l *lock, event Event) {
+	round := event.Message.GetView().GetRound()
+	if round <= l.round {
+		return
+	}
+
+	rawProposal, ok := c.proposals[string(messages.ExtractMessageHash(event.Message))]
+	if !ok || string(rawProposal) == string(l.rawProposal) {
+		return
+	}
+
+	trace := append(append(make([]Event, 0, len(l.trace)+1), l.trace...), event)
+
+	c.violate(InvariantLock, event.Height, fmt.Sprintf(
+		"node %d sent %s for a different proposal in round %d than the one locked in round %d",
+		event.Node, event.Message.Type, round, l.round,
+	), trace...)
+}
+
+// sortEvents sorts the events in the order they were observed
+func sortEvents(events []Event) {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Index < events[j].Index
+	})
+}
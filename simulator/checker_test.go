@@ -0,0 +1,432 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// newTestChecker creates the checker of a cluster of 4 nodes
+func newTestChecker(t *testing.T, config CheckerConfig) *Checker {
+	t.Helper()
+
+	config.Nodes = 4
+
+	checker, err := NewChecker(config)
+	require.NoError(t, err)
+
+	return checker
+}
+
+// buildPrePrepare builds the PREPREPARE of the node for the raw proposal in the round
+func buildPrePrepare(node int, rawProposal string, round uint64) *proto.Message {
+	return newBackend(node, 4, nil).BuildPrePrepareMessage(
+		[]byte(rawProposal),
+		nil,
+		&proto.View{Height: 1, Round: round},
+	)
+}
+
+// buildVote builds the PREPARE or COMMIT of the node for the proposal of the PREPREPARE
+func buildVote(node int, messageType proto.MessageType, preprepare *proto.Message) *proto.Message {
+	var (
+		b            = newBackend(node, 4, nil)
+		proposalHash = preprepare.GetPreprepareData().GetProposalHash()
+	)
+
+	if messageType == proto.MessageType_PREPARE {
+		return b.BuildPrepareMessage(proposalHash, preprepare.View)
+	}
+
+	return b.BuildCommitMessage(proposalHash, preprepare.View)
+}
+
+func TestChecker_Agreement(t *testing.T) {
+	t.Parallel()
+
+	checker := newTestChecker(t, CheckerConfig{})
+
+	checker.ProposalInserted(0, 1, &proto.Proposal{RawProposal: []byte("block")})
+	checker.ProposalInserted(1, 1, &proto.Proposal{RawProposal: []byte("block")})
+	assert.Empty(t, checker.Violations())
+
+	checker.ProposalInserted(2, 1, &proto.Proposal{RawProposal: []byte("other block")})
+
+	violations := checker.Violations()
+	require.Len(t, violations, 1)
+
+	assert.Equal(t, InvariantAgreement, violations[0].Invariant)
+	assert.Equal(t, uint64(1), violations[0].Height)
+
+	// Make sure the trace contains only the conflicting finalizations
+	require.Len(t, violations[0].Trace, 2)
+	assert.Equal(t, 2, violations[0].Trace[1].Node)
+	assert.Len(t, checker.Trace(), 3)
+}
+
+func TestChecker_NoEquivocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		preprepare      = buildPrePrepare(0, "block", 0)
+		otherPreprepare = buildPrePrepare(0, "other block", 0)
+	)
+
+	testTable := []struct {
+		name      string
+		byzantine []int
+		messages  []*proto.Message
+		violated  bool
+	}{
+		{
+			"same PREPARE sent twice",
+			nil,
+			[]*proto.Message{
+				buildVote(1, proto.MessageType_PREPARE, preprepare),
+				buildVote(1, proto.MessageType_PREPARE, preprepare),
+			},
+			false,
+		},
+		{
+			"PREPARE and COMMIT for the same proposal",
+			nil,
+			[]*proto.Message{
+				buildVote(1, proto.MessageType_PREPARE, preprepare),
+				buildVote(1, proto.MessageType_COMMIT, preprepare),
+			},
+			false,
+		},
+		{
+			"conflicting PREPARE messages",
+			nil,
+			[]*proto.Message{
+				buildVote(1, proto.MessageType_PREPARE, preprepare),
+				buildVote(1, proto.MessageType_PREPARE, otherPreprepare),
+			},
+			true,
+		},
+		{
+			"conflicting COMMIT messages",
+			nil,
+			[]*proto.Message{
+				buildVote(1, proto.MessageType_COMMIT, preprepare),
+				buildVote(1, proto.MessageType_COMMIT, otherPreprepare),
+			},
+			true,
+		},
+		{
+			"conflicting PREPARE messages of a byzantine node",
+			[]int{1},
+			[]*proto.Message{
+				buildVote(1, proto.MessageType_PREPARE, preprepare),
+				buildVote(1, proto.MessageType_PREPARE, otherPreprepare),
+			},
+			false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := newTestChecker(t, CheckerConfig{Byzantine: testCase.byzantine})
+
+			for _, message := range testCase.messages {
+				checker.MessageSent(1, message)
+			}
+
+			violations := checker.Violations()
+			if !testCase.violated {
+				assert.Empty(t, violations)
+
+				return
+			}
+
+			require.Len(t, violations, 1)
+			assert.Equal(t, InvariantNoEquivocation, violations[0].Invariant)
+			assert.Len(t, violations[0].Trace, 2)
+		})
+	}
+}
+
+func TestChecker_Lock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		locked     = buildPrePrepare(0, "block", 0)
+		relocked   = buildPrePrepare(1, "block", 1)
+		conflicted = buildPrePrepare(1, "other block", 1)
+	)
+
+	// prepareQuorum builds the PREPARE messages of the other nodes for the locked proposal,
+	// which reach the quorum with the PREPREPARE of the proposer
+	prepareQuorum := func() []*proto.Message {
+		prepares := make([]*proto.Message, 0, 3)
+
+		for node := 1; node < 4; node++ {
+			prepares = append(prepares, buildVote(node, proto.MessageType_PREPARE, locked))
+		}
+
+		return prepares
+	}
+
+	testTable := []struct {
+		name      string
+		byzantine []int
+		messages  []*proto.Message
+		violated  bool
+	}{
+		{
+			"locked proposal proposed in a later round",
+			nil,
+			append(
+				append([]*proto.Message{locked}, prepareQuorum()...),
+				relocked,
+				buildVote(2, proto.MessageType_PREPARE, relocked),
+			),
+			false,
+		},
+		{
+			"different proposal without the prepare quorum",
+			nil,
+			append(
+				append([]*proto.Message{locked}, prepareQuorum()[:1]...),
+				conflicted,
+			),
+			false,
+		},
+		{
+			"different proposal proposed in a later round",
+			nil,
+			append(
+				append([]*proto.Message{locked}, prepareQuorum()...),
+				conflicted,
+			),
+			true,
+		},
+		{
+			"different proposal observed before the prepare quorum",
+			nil,
+			append(
+				[]*proto.Message{locked, conflicted},
+				prepareQuorum()...,
+			),
+			true,
+		},
+		{
+			"prepare quorum observed before the locked proposal",
+			nil,
+			append(
+				append(prepareQuorum(), conflicted),
+				locked,
+			),
+			true,
+		},
+		{
+			"different proposal of a byzantine proposer prepared in a later round",
+			[]int{1},
+			append(
+				append([]*proto.Message{locked}, prepareQuorum()...),
+				conflicted,
+				buildVote(2, proto.MessageType_PREPARE, conflicted),
+			),
+			true,
+		},
+		{
+			"different proposal of a byzantine proposer",
+			[]int{1},
+			append(
+				append([]*proto.Message{locked}, prepareQuorum()...),
+				conflicted,
+			),
+			false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := newTestChecker(t, CheckerConfig{Byzantine: testCase.byzantine})
+
+			for _, message := range testCase.messages {
+				for node := 0; node < 4; node++ {
+					if string(message.From) == string(NodeAddress(node)) {
+						checker.MessageSent(node, message)
+					}
+				}
+			}
+
+			violations := checker.Violations()
+			if !testCase.violated {
+				assert.Empty(t, violations)
+
+				return
+			}
+
+			require.Len(t, violations, 1)
+			assert.Equal(t, InvariantLock, violations[0].Invariant)
+
+			// Make sure the trace contains the prepared certificate, followed by the conflicting vote
+			trace := violations[0].Trace
+			require.Len(t, trace, 5)
+
+			prepares := 0
+
+			for _, event := range trace[:4] {
+				if event.Message.Type == proto.MessageType_PREPARE {
+					prepares++
+
+					continue
+				}
+
+				assert.Equal(t, locked, event.Message)
+			}
+
+			assert.Equal(t, 3, prepares)
+
+			conflicting := trace[4].Message
+			assert.Equal(t, uint64(1), conflicting.View.Round)
+			assert.Equal(t, []byte("other block"), checker.proposals[string(messages.ExtractMessageHash(conflicting))])
+		})
+	}
+}
+
+func TestChecker_Liveness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("liveness not checked", func(t *testing.T) {
+		t.Parallel()
+
+		checker := newTestChecker(t, CheckerConfig{})
+
+		checker.HeightStarted(1)
+		checker.HeightFinished(HeightReport{Height: 1})
+
+		assert.Empty(t, checker.Violations())
+	})
+
+	t.Run("height finalized by the honest nodes", func(t *testing.T) {
+		t.Parallel()
+
+		checker := newTestChecker(t, CheckerConfig{
+			Byzantine:     []int{3},
+			LivenessBound: time.Minute,
+		})
+
+		checker.HeightStarted(1)
+
+		for node := 0; node < 3; node++ {
+			checker.ProposalInserted(node, 1, &proto.Proposal{RawProposal: []byte("block")})
+		}
+
+		checker.HeightFinished(HeightReport{Height: 1})
+
+		assert.Empty(t, checker.Violations())
+	})
+
+	t.Run("height not finalized", func(t *testing.T) {
+		t.Parallel()
+
+		checker := newTestChecker(t, CheckerConfig{LivenessBound: time.Minute})
+
+		checker.HeightStarted(1)
+		checker.ProposalInserted(0, 1, &proto.Proposal{RawProposal: []byte("block")})
+		checker.HeightFinished(HeightReport{Height: 1})
+
+		violations := checker.Violations()
+		require.Len(t, violations, 3)
+
+		for _, violation := range violations {
+			assert.Equal(t, InvariantLiveness, violation.Invariant)
+			assert.Len(t, violation.Trace, 1)
+		}
+	})
+
+	t.Run("height finalized after the bound", func(t *testing.T) {
+		t.Parallel()
+
+		checker := newTestChecker(t, CheckerConfig{LivenessBound: time.Nanosecond})
+
+		checker.HeightStarted(1)
+		time.Sleep(time.Millisecond)
+
+		for node := 0; node < 4; node++ {
+			checker.ProposalInserted(node, 1, &proto.Proposal{RawProposal: []byte("block")})
+		}
+
+		checker.HeightFinished(HeightReport{Height: 1})
+
+		violations := checker.Violations()
+		require.Len(t, violations, 4)
+		assert.Len(t, violations[0].Trace, 2)
+	})
+}
+
+func TestChecker_Simulation(t *testing.T) {
+	t.Parallel()
+
+	// The nodes can't finalize the height until the partition heals,
+	// which is the GST of the run
+	checker := newTestChecker(t, CheckerConfig{
+		GST:           500 * time.Millisecond,
+		LivenessBound: 2 * time.Second,
+	})
+
+	simulator, err := New(Config{
+		Nodes:            4,
+		Seed:             1,
+		Latency:          UniformLatency{Min: time.Millisecond, Max: 5 * time.Millisecond},
+		Duplication:      0.1,
+		Reordering:       0.1,
+		ReorderWindow:    10 * time.Millisecond,
+		BaseRoundTimeout: 100 * time.Millisecond,
+		Partitions: []Partition{
+			{
+				End:    500 * time.Millisecond,
+				Groups: [][]int{{0, 1}, {2, 3}},
+			},
+		},
+		Observers: []Observer{checker},
+	})
+	require.NoError(t, err)
+
+	defer simulator.Close()
+
+	report, err := simulator.Run(context.Background(), 3)
+	require.NoError(t, err)
+	require.True(t, report.Live())
+
+	for _, violation := range checker.Violations() {
+		t.Error(violation.String())
+	}
+
+	// Make sure the checker observed all events of the run
+	var (
+		sent     = 0
+		inserted = 0
+	)
+
+	for _, event := range checker.Trace() {
+		switch event.Type {
+		case EventMessageSent:
+			sent++
+		case EventProposalInserted:
+			inserted++
+		case EventHeightStarted:
+		}
+	}
+
+	assert.Equal(t, report.Network.Sent, uint64(sent*3))
+	assert.Equal(t, 12, inserted)
+}
@@ -0,0 +1,278 @@
+package simulator
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// LatencyDistribution samples the delivery latency of the messages
+type LatencyDistribution interface {
+	// Sample returns the latency of a single message delivery
+	Sample(rng *rand.Rand) time.Duration
+}
+
+// FixedLatency delivers all messages with the same latency
+type FixedLatency time.Duration
+
+// Sample returns the fixed latency
+func (l FixedLatency) Sample(_ *rand.Rand) time.Duration {
+	return time.Duration(l)
+}
+
+// UniformLatency delivers the messages with a latency
+// uniformly distributed in the [Min, Max] interval
+type UniformLatency struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Sample returns a latency from the [Min, Max] interval
+func (l UniformLatency) Sample(rng *rand.Rand) time.Duration {
+	if l.Max <= l.Min {
+		return l.Min
+	}
+
+	return l.Min + time.Duration(rng.Int63n(int64(l.Max-l.Min)+1))
+}
+
+// NormalLatency delivers the messages with a normally distributed latency.
+// The negative samples are clamped to zero
+type NormalLatency struct {
+	Mean   time.Duration
+	StdDev time.Duration
+}
+
+// Sample returns a normally distributed latency
+func (l NormalLatency) Sample(rng *rand.Rand) time.Duration {
+	latency := time.Duration(rng.NormFloat64()*float64(l.StdDev)) + l.Mean
+	if latency < 0 {
+		return 0
+	}
+
+	return latency
+}
+
+// Partition splits the network into groups of nodes for a period of the run.
+// The messages between the nodes of different groups are dropped,
+// while the nodes which are not in any of the groups are not affected
+type Partition struct {
+	// Start is the offset from the start of the run the partition begins at
+	Start time.Duration
+
+	// End is the offset from the start of the run the partition heals at
+	End time.Duration
+
+	// Groups are the node indexes of each side of the partition
+	Groups [][]int
+}
+
+// isActive checks if the partition is in place at the offset from the start of the run
+func (p Partition) isActive(offset time.Duration) bool {
+	return offset >= p.Start && offset < p.End
+}
+
+// separates checks if the nodes are in different groups of the partition
+func (p Partition) separates(from, to int) bool {
+	fromGroup, toGroup := -1, -1
+
+	for index, group := range p.Groups {
+		for _, node := range group {
+			if node == from {
+				fromGroup = index
+			}
+
+			if node == to {
+				toGroup = index
+			}
+		}
+	}
+
+	return fromGroup != -1 && toGroup != -1 && fromGroup != toGroup
+}
+
+// NetworkStats are the numbers of the messages passed through the network
+type NetworkStats struct {
+	// Sent is the number of messages multicasted by the nodes, per recipient
+	Sent uint64
+
+	// Delivered is the number of messages delivered to the nodes, including the duplicates
+	Delivered uint64
+
+	// Dropped is the number of messages lost, or dropped because of a partition
+	Dropped uint64
+
+	// Duplicated is the number of duplicate messages delivered
+	Duplicated uint64
+
+	// Reordered is the number of messages held back, so later messages can overtake them
+	Reordered uint64
+}
+
+// network is the in-memory network connecting the nodes of the simulation
+type network struct {
+	sync.Mutex
+
+	config Config
+	rng    *rand.Rand
+
+	// deliverFn delivers the message to the node with the index
+	deliverFn func(to int, message *proto.Message)
+
+	// started is the start time of the run the partitions are relative to
+	started time.Time
+
+	// timers are the pending deliveries
+	timers map[*time.Timer]struct{}
+	wg     sync.WaitGroup
+	closed bool
+
+	stats NetworkStats
+}
+
+// newNetwork creates the network for the simulation configuration
+func newNetwork(config Config, deliverFn func(to int, message *proto.Message)) *network {
+	return &network{
+		config: config,
+		//nolint:gosec
+		rng:       rand.New(rand.NewSource(config.Seed)),
+		deliverFn: deliverFn,
+		started:   time.Now(),
+		timers:    make(map[*time.Timer]struct{}),
+	}
+}
+
+// start resets the start time of the run
+func (n *network) start() {
+	n.Lock()
+	defer n.Unlock()
+
+	n.started = time.Now()
+}
+
+// multicast sends the message of the node to all nodes, including itself.
+// The message is delivered to the node itself right away
+func (n *network) multicast(from int, message *proto.Message) {
+	n.Lock()
+
+	if n.closed {
+		n.Unlock()
+
+		return
+	}
+
+	offset := time.Since(n.started)
+
+	for to := 0; to < n.config.Nodes; to++ {
+		if to == from {
+			continue
+		}
+
+		n.stats.Sent++
+
+		if n.isPartitioned(from, to, offset) || n.rng.Float64() < n.config.Loss {
+			n.stats.Dropped++
+
+			continue
+		}
+
+		n.schedule(n.latency(), to, message)
+
+		if n.rng.Float64() < n.config.Duplication {
+			n.stats.Duplicated++
+
+			n.schedule(n.latency(), to, message)
+		}
+	}
+
+	n.Unlock()
+
+	n.deliverFn(from, message)
+}
+
+// latency samples the delivery latency of a message,
+// including the delay of the messages held back for reordering.
+// The caller must hold the lock
+func (n *network) latency() time.Duration {
+	var latency time.Duration
+
+	if n.config.Latency != nil {
+		latency = n.config.Latency.Sample(n.rng)
+	}
+
+	if n.config.ReorderWindow > 0 && n.rng.Float64() < n.config.Reordering {
+		n.stats.Reordered++
+
+		latency += time.Duration(n.rng.Int63n(int64(n.config.ReorderWindow)) + 1)
+	}
+
+	return latency
+}
+
+// isPartitioned checks if any of the partitions separates the nodes at the offset.
+// The caller must hold the lock
+func (n *network) isPartitioned(from, to int, offset time.Duration) bool {
+	for _, partition := range n.config.Partitions {
+		if partition.isActive(offset) && partition.separates(from, to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schedule delivers the message to the node after the delay.
+// The caller must hold the lock
+func (n *network) schedule(delay time.Duration, to int, message *proto.Message) {
+	var timer *time.Timer
+
+	n.wg.Add(1)
+
+	timer = time.AfterFunc(delay, func() {
+		defer n.wg.Done()
+
+		n.Lock()
+		delete(n.timers, timer)
+
+		if n.closed {
+			n.Unlock()
+
+			return
+		}
+
+		n.stats.Delivered++
+		n.Unlock()
+
+		n.deliverFn(to, message)
+	})
+
+	n.timers[timer] = struct{}{}
+}
+
+// getStats returns the numbers of the messages passed through the network
+func (n *network) getStats() NetworkStats {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.stats
+}
+
+// close drops the pending deliveries, and waits for the ongoing ones to finish
+func (n *network) close() {
+	n.Lock()
+
+	n.closed = true
+
+	for timer := range n.timers {
+		if timer.Stop() {
+			n.wg.Done()
+		}
+	}
+
+	n.timers = make(map[*time.Timer]struct{})
+	n.Unlock()
+
+	n.wg.Wait()
+}
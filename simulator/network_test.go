@@ -0,0 +1,74 @@
+package simulator
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestLatencyDistributions(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(0))
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, time.Second, FixedLatency(time.Second).Sample(rng))
+
+		latency := UniformLatency{Min: time.Millisecond, Max: 2 * time.Millisecond}.Sample(rng)
+		assert.GreaterOrEqual(t, latency, time.Millisecond)
+		assert.LessOrEqual(t, latency, 2*time.Millisecond)
+
+		assert.GreaterOrEqual(t, NormalLatency{StdDev: time.Millisecond}.Sample(rng), time.Duration(0))
+	}
+}
+
+func TestPartition_Separates(t *testing.T) {
+	t.Parallel()
+
+	partition := Partition{
+		Start:  time.Second,
+		End:    2 * time.Second,
+		Groups: [][]int{{0, 1}, {2}},
+	}
+
+	assert.False(t, partition.isActive(0))
+	assert.True(t, partition.isActive(time.Second))
+	assert.False(t, partition.isActive(2*time.Second))
+
+	assert.False(t, partition.separates(0, 1))
+	assert.True(t, partition.separates(1, 2))
+
+	// Make sure the nodes out of the partition are not affected
+	assert.False(t, partition.separates(0, 3))
+}
+
+func TestNetwork_Close(t *testing.T) {
+	t.Parallel()
+
+	var (
+		delivered   = make(map[int]int)
+		deliveredMu sync.Mutex
+	)
+
+	n := newNetwork(Config{Nodes: 3, Latency: FixedLatency(time.Hour)}, func(to int, _ *proto.Message) {
+		deliveredMu.Lock()
+		delivered[to]++
+		deliveredMu.Unlock()
+	})
+
+	n.multicast(0, &proto.Message{})
+
+	// Make sure the message is delivered to the sender right away,
+	// and the pending deliveries are dropped once the network is closed
+	n.close()
+
+	n.multicast(0, &proto.Message{})
+
+	assert.Equal(t, map[int]int{0: 1}, delivered)
+	assert.Equal(t, NetworkStats{Sent: 2}, n.getStats())
+}
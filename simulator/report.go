@@ -0,0 +1,110 @@
+package simulator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// Report is the outcome of a simulation run
+type Report struct {
+	// Heights are the outcomes of the heights, in the order they were run
+	Heights []HeightReport
+
+	// Violations are the safety violations found during the run
+	Violations []SafetyViolation
+
+	// Network are the numbers of the messages passed through the network,
+	// since the simulation was created
+	Network NetworkStats
+}
+
+// HeightReport is the outcome of a height
+type HeightReport struct {
+	// Height is the height number
+	Height uint64
+
+	// Duration is the time it took for all nodes to finish the height
+	Duration time.Duration
+
+	// Nodes are the outcomes of the height for each node, by the node index
+	Nodes []NodeResult
+}
+
+// Finalized returns the number of the nodes which finalized the height
+func (r HeightReport) Finalized() int {
+	finalized := 0
+
+	for _, result := range r.Nodes {
+		if result.Err == nil {
+			finalized++
+		}
+	}
+
+	return finalized
+}
+
+// FinalizationTimes returns the times it took the nodes to finalize
+// the height, in the ascending order
+func (r HeightReport) FinalizationTimes() []time.Duration {
+	times := make([]time.Duration, 0, len(r.Nodes))
+
+	for _, result := range r.Nodes {
+		if result.Err == nil {
+			times = append(times, result.Duration)
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+
+	return times
+}
+
+// NodeResult is the outcome of a height for a node
+type NodeResult struct {
+	// Node is the node index
+	Node int
+
+	// Proposal is the proposal finalized by the node
+	Proposal *proto.Proposal
+
+	// Round is the round the proposal was finalized in
+	Round uint64
+
+	// Duration is the time it took the node to finalize the proposal
+	Duration time.Duration
+
+	// Err is the error the node returned, if it didn't finalize the height
+	Err error
+}
+
+// SafetyViolation is the finalization of different proposals at the same height
+type SafetyViolation struct {
+	// Height is the height the proposals were finalized at
+	Height uint64
+
+	// Nodes are the indexes of the nodes which finalized the proposals
+	Nodes [2]int
+
+	// Proposals are the raw proposals finalized by the nodes
+	Proposals [2][]byte
+}
+
+// Live checks if all nodes finalized all heights of the run
+func (r *Report) Live() bool {
+	for _, height := range r.Heights {
+		if height.Finalized() != len(height.Nodes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Safe checks if no safety violations were found during the run
+func (r *Report) Safe() bool {
+	return len(r.Violations) == 0
+}
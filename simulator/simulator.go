@@ -0,0 +1,383 @@
+// Package simulator runs IBFT clusters over an in-memory network,
+// with configurable latencies, message loss, duplication, reordering and partitions
+package simulator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+const (
+	// DefaultBaseRoundTimeout is the base round timeout of the simulated nodes
+	DefaultBaseRoundTimeout = time.Second
+
+	// DefaultHeightTimeout is the time after which the nodes
+	// which didn't finalize a height are moved to the next one
+	DefaultHeightTimeout = time.Minute
+)
+
+var (
+	// ErrInvalidNodes is returned when the simulation has no nodes
+	ErrInvalidNodes = errors.New("at least one node is required")
+
+	// ErrInvalidProbability is returned when a probability is not within [0, 1]
+	ErrInvalidProbability = errors.New("probability must be within [0, 1]")
+
+	// ErrInvalidPartition is returned when a partition refers to unknown nodes,
+	// or it ends before it starts
+	ErrInvalidPartition = errors.New("invalid partition")
+
+	// ErrClosed is returned when running a closed simulation
+	ErrClosed = errors.New("simulation is closed")
+)
+
+// Config is the configuration of the simulation
+type Config struct {
+	// Nodes is the number of nodes in the cluster
+	Nodes int
+
+	// Seed is the seed of the random network behavior
+	Seed int64
+
+	// Latency is the distribution of the message delivery latencies.
+	// The messages are delivered right away if it is not set
+	Latency LatencyDistribution
+
+	// Loss is the probability of a message being lost
+	Loss float64
+
+	// Duplication is the probability of a message being delivered twice
+	Duplication float64
+
+	// Reordering is the probability of a message being held back
+	// for up to ReorderWindow, so the later messages can overtake it
+	Reordering    float64
+	ReorderWindow time.Duration
+
+	// Partitions are the periods of the run the network is partitioned for
+	Partitions []Partition
+
+	// BaseRoundTimeout is the base round timeout of the nodes
+	BaseRoundTimeout time.Duration
+
+	// HeightTimeout is the time after which the nodes which
+	// didn't finalize a height are moved to the next one
+	HeightTimeout time.Duration
+
+	// Logger is the logger of the nodes. The log messages are dropped if it is not set
+	Logger core.Logger
+
+	// Options are the additional options of the nodes
+	Options []core.Option
+
+	// Observers are notified of the events of the runs
+	Observers []Observer
+}
+
+// Observer observes the events of the simulation runs.
+// The methods are called concurrently by the nodes
+type Observer interface {
+	// HeightStarted is called before the nodes start running the height
+	HeightStarted(height uint64)
+
+	// MessageSent is called with each message multicasted by the node
+	MessageSent(node int, message *proto.Message)
+
+	// ProposalInserted is called with each proposal finalized by the node
+	ProposalInserted(node int, height uint64, proposal *proto.Proposal)
+
+	// HeightFinished is called once all nodes finished running the height
+	HeightFinished(report HeightReport)
+}
+
+// validate checks the configuration, and sets the defaults
+func (c *Config) validate() error {
+	if c.Nodes < 1 {
+		return ErrInvalidNodes
+	}
+
+	for _, probability := range []float64{c.Loss, c.Duplication, c.Reordering} {
+		if probability < 0 || probability > 1 {
+			return ErrInvalidProbability
+		}
+	}
+
+	for _, partition := range c.Partitions {
+		if partition.End < partition.Start {
+			return ErrInvalidPartition
+		}
+
+		for _, group := range partition.Groups {
+			for _, node := range group {
+				if node < 0 || node >= c.Nodes {
+					return ErrInvalidPartition
+				}
+			}
+		}
+	}
+
+	if c.BaseRoundTimeout == 0 {
+		c.BaseRoundTimeout = DefaultBaseRoundTimeout
+	}
+
+	if c.HeightTimeout == 0 {
+		c.HeightTimeout = DefaultHeightTimeout
+	}
+
+	if c.Logger == nil {
+		c.Logger = nopLogger{}
+	}
+
+	return nil
+}
+
+// Simulator runs a cluster of IBFT nodes over an in-memory network
+type Simulator struct {
+	config Config
+
+	nodes   []*core.IBFT
+	network *network
+
+	// inserted maps the height -> node index -> finalized proposal.
+	// The nodes run the same height at a time, which is the height
+	// the proposals are inserted for
+	inserted     map[uint64]map[int]*proto.Proposal
+	height       uint64
+	insertedLock sync.Mutex
+
+	// lastHeight is the last height run by the nodes
+	lastHeight uint64
+
+	closed bool
+}
+
+// New creates the simulation of a cluster, with the nodes ready to run
+func New(config Config) (*Simulator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Simulator{
+		config:   config,
+		nodes:    make([]*core.IBFT, config.Nodes),
+		inserted: make(map[uint64]map[int]*proto.Proposal),
+	}
+
+	s.network = newNetwork(config, s.deliver)
+
+	for index := range s.nodes {
+		opts := append([]core.Option{core.WithBaseRoundTimeout(config.BaseRoundTimeout)}, config.Options...)
+
+		node, err := core.NewIBFTWithOptions(
+			config.Logger,
+			newBackend(index, config.Nodes, s.insertProposal),
+			&transport{index: index, multicastFn: s.multicast},
+			opts...,
+		)
+		if err != nil {
+			s.Close()
+
+			return nil, err
+		}
+
+		if err := node.Start(); err != nil {
+			s.Close()
+
+			return nil, err
+		}
+
+		s.nodes[index] = node
+	}
+
+	return s, nil
+}
+
+// Nodes returns the IBFT instances of the cluster, by the node index
+func (s *Simulator) Nodes() []*core.IBFT {
+	return s.nodes
+}
+
+// Run runs the specified number of heights on all nodes, following the heights
+// of the previous runs, and reports the outcome of each height. The nodes run each height
+// until they finalize it, or until the height timeout expires. The partitions are relative
+// to the start of the run. The run is aborted if the context is done
+func (s *Simulator) Run(ctx context.Context, heights uint64) (*Report, error) {
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	report := &Report{
+		Heights: make([]HeightReport, 0, heights),
+	}
+
+	s.network.start()
+
+	for height := s.lastHeight + 1; height <= s.lastHeight+heights; height++ {
+		heightReport := s.runHeight(ctx, height)
+
+		report.Heights = append(report.Heights, heightReport)
+		report.Violations = append(report.Violations, s.checkSafety(height)...)
+
+		if ctx.Err() != nil {
+			s.lastHeight = height
+			report.Network = s.network.getStats()
+
+			return report, ctx.Err()
+		}
+	}
+
+	s.lastHeight += heights
+	report.Network = s.network.getStats()
+
+	return report, nil
+}
+
+// runHeight runs the height on all nodes, and waits for them to finish
+func (s *Simulator) runHeight(ctx context.Context, height uint64) HeightReport {
+	ctx, cancelFn := context.WithTimeout(ctx, s.config.HeightTimeout)
+	defer cancelFn()
+
+	var (
+		started = time.Now()
+		results = make([]NodeResult, len(s.nodes))
+		wg      sync.WaitGroup
+	)
+
+	s.setHeight(height)
+
+	for _, observer := range s.config.Observers {
+		observer.HeightStarted(height)
+	}
+
+	for index, node := range s.nodes {
+		wg.Add(1)
+
+		go func(index int, node *core.IBFT) {
+			defer wg.Done()
+
+			result, err := node.RunSequence(ctx, height)
+
+			results[index] = NodeResult{
+				Node: index,
+				Err:  err,
+			}
+
+			if err == nil {
+				results[index].Proposal = result.Proposal
+				results[index].Round = result.Round
+				results[index].Duration = result.Duration
+			}
+		}(index, node)
+	}
+
+	wg.Wait()
+
+	report := HeightReport{
+		Height:   height,
+		Duration: time.Since(started),
+		Nodes:    results,
+	}
+
+	for _, observer := range s.config.Observers {
+		observer.HeightFinished(report)
+	}
+
+	return report
+}
+
+// multicast notifies the observers of the message, and sends it through the network
+func (s *Simulator) multicast(from int, message *proto.Message) {
+	for _, observer := range s.config.Observers {
+		observer.MessageSent(from, message)
+	}
+
+	s.network.multicast(from, message)
+}
+
+// deliver passes the message to the node with the index
+func (s *Simulator) deliver(to int, message *proto.Message) {
+	s.nodes[to].AddMessage(message)
+}
+
+// insertProposal records the proposal finalized by the node
+func (s *Simulator) insertProposal(index int, proposal *proto.Proposal, _ []*messages.CommittedSeal) {
+	s.insertedLock.Lock()
+	defer s.insertedLock.Unlock()
+
+	if s.inserted[s.height] == nil {
+		s.inserted[s.height] = make(map[int]*proto.Proposal)
+	}
+
+	s.inserted[s.height][index] = proposal
+
+	for _, observer := range s.config.Observers {
+		observer.ProposalInserted(index, s.height, proposal)
+	}
+}
+
+// setHeight sets the height the nodes are running
+func (s *Simulator) setHeight(height uint64) {
+	s.insertedLock.Lock()
+	defer s.insertedLock.Unlock()
+
+	s.height = height
+}
+
+// checkSafety returns the violations of the nodes which
+// finalized a different proposal than the first node at the height
+func (s *Simulator) checkSafety(height uint64) []SafetyViolation {
+	s.insertedLock.Lock()
+	defer s.insertedLock.Unlock()
+
+	var (
+		violations = make([]SafetyViolation, 0)
+		first      = -1
+	)
+
+	for index := range s.nodes {
+		proposal, ok := s.inserted[height][index]
+		if !ok {
+			continue
+		}
+
+		if first == -1 {
+			first = index
+
+			continue
+		}
+
+		expected := s.inserted[height][first]
+		if string(proposal.GetRawProposal()) != string(expected.GetRawProposal()) {
+			violations = append(violations, SafetyViolation{
+				Height:    height,
+				Nodes:     [2]int{first, index},
+				Proposals: [2][]byte{expected.GetRawProposal(), proposal.GetRawProposal()},
+			})
+		}
+	}
+
+	return violations
+}
+
+// Close stops the network and the nodes of the cluster
+func (s *Simulator) Close() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+
+	s.network.close()
+
+	for _, node := range s.nodes {
+		if node != nil {
+			_ = node.Close()
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// runSimulation runs the heights of the simulation, and makes sure the run is safe
+func runSimulation(t *testing.T, config Config, heights uint64) *Report {
+	t.Helper()
+
+	simulator, err := New(config)
+	require.NoError(t, err)
+
+	defer simulator.Close()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFn()
+
+	report, err := simulator.Run(ctx, heights)
+	require.NoError(t, err)
+
+	require.Len(t, report.Heights, int(heights))
+	assert.True(t, report.Safe())
+
+	return report
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name   string
+		config Config
+		err    error
+	}{
+		{
+			"no nodes",
+			Config{},
+			ErrInvalidNodes,
+		},
+		{
+			"invalid loss",
+			Config{Nodes: 4, Loss: 1.5},
+			ErrInvalidProbability,
+		},
+		{
+			"invalid duplication",
+			Config{Nodes: 4, Duplication: -0.1},
+			ErrInvalidProbability,
+		},
+		{
+			"partition of unknown nodes",
+			Config{Nodes: 4, Partitions: []Partition{{End: time.Second, Groups: [][]int{{0, 1}, {4}}}}},
+			ErrInvalidPartition,
+		},
+		{
+			"partition ending before it starts",
+			Config{Nodes: 4, Partitions: []Partition{{Start: time.Second, Groups: [][]int{{0, 1}, {2, 3}}}}},
+			ErrInvalidPartition,
+		},
+		{
+			"valid config",
+			Config{Nodes: 4, Loss: 0.1, Duplication: 0.1, Reordering: 0.1},
+			nil,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, testCase.config.validate(), testCase.err)
+		})
+	}
+}
+
+func TestSimulator_Run(t *testing.T) {
+	t.Parallel()
+
+	report := runSimulation(t, Config{
+		Nodes:   4,
+		Latency: UniformLatency{Min: time.Millisecond, Max: 10 * time.Millisecond},
+	}, 3)
+
+	assert.True(t, report.Live())
+
+	for index, height := range report.Heights {
+		assert.Equal(t, uint64(index+1), height.Height)
+		assert.Len(t, height.FinalizationTimes(), 4)
+
+		// Make sure all nodes finalized the proposal of the first proposer
+		for _, result := range height.Nodes {
+			assert.Equal(t, uint64(0), result.Round)
+			assert.Equal(t, report.Heights[index].Nodes[0].Proposal.RawProposal, result.Proposal.RawProposal)
+		}
+	}
+
+	assert.Zero(t, report.Network.Dropped)
+	assert.Equal(t, report.Network.Sent, report.Network.Delivered)
+}
+
+func TestSimulator_Run_Continues(t *testing.T) {
+	t.Parallel()
+
+	simulator, err := New(Config{Nodes: 4})
+	require.NoError(t, err)
+
+	report, err := simulator.Run(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), report.Heights[1].Height)
+
+	// Make sure the next run follows the heights of the previous one
+	report, err = simulator.Run(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), report.Heights[0].Height)
+	assert.True(t, report.Live())
+
+	simulator.Close()
+
+	_, err = simulator.Run(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestSimulator_Run_FaultyNetwork(t *testing.T) {
+	t.Parallel()
+
+	report := runSimulation(t, Config{
+		Nodes:            4,
+		Seed:             1,
+		Latency:          NormalLatency{Mean: 5 * time.Millisecond, StdDev: 2 * time.Millisecond},
+		Duplication:      0.2,
+		Reordering:       0.2,
+		ReorderWindow:    20 * time.Millisecond,
+		BaseRoundTimeout: 200 * time.Millisecond,
+		HeightTimeout:    5 * time.Second,
+	}, 3)
+
+	assert.True(t, report.Live())
+
+	assert.NotZero(t, report.Network.Duplicated)
+	assert.NotZero(t, report.Network.Reordered)
+}
+
+func TestSimulator_Run_LossyNetwork(t *testing.T) {
+	t.Parallel()
+
+	// The lost messages are not retransmitted, and the nodes
+	// can't catch up without syncing, so only the safety is checked
+	report := runSimulation(t, Config{
+		Nodes:            4,
+		Seed:             1,
+		Loss:             0.1,
+		BaseRoundTimeout: 100 * time.Millisecond,
+		HeightTimeout:    time.Second,
+	}, 3)
+
+	assert.NotZero(t, report.Network.Dropped)
+}
+
+func TestSimulator_Run_Partition(t *testing.T) {
+	t.Parallel()
+
+	report := runSimulation(t, Config{
+		Nodes:            4,
+		BaseRoundTimeout: 100 * time.Millisecond,
+		Partitions: []Partition{
+			{
+				End:    500 * time.Millisecond,
+				Groups: [][]int{{0, 1}, {2, 3}},
+			},
+		},
+	}, 1)
+
+	assert.True(t, report.Live())
+
+	height := report.Heights[0]
+
+	// Make sure the height is finalized only once the partition heals,
+	// since neither side has the quorum
+	assert.Greater(t, height.Duration, 500*time.Millisecond)
+
+	for _, result := range height.Nodes {
+		assert.Positive(t, result.Round)
+	}
+
+	assert.NotZero(t, report.Network.Dropped)
+}
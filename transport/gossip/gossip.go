@@ -0,0 +1,342 @@
+// Package gossip implements a core.Transport for the networks which are not fully connected.
+// The messages are relayed by the nodes to a random subset of their peers, until their TTL
+// runs out, and the messages seen before are dropped, so each message is delivered once
+package gossip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+const (
+	// DefaultFanout is the default number of peers each message is relayed to
+	DefaultFanout = 3
+
+	// DefaultTTL is the default number of hops the messages are relayed for
+	DefaultTTL = 8
+
+	// DefaultCacheSize is the default number of the message IDs remembered for the deduplication
+	DefaultCacheSize = 4096
+)
+
+var (
+	// ErrMissingID is returned when the ID of the node is not set
+	ErrMissingID = errors.New("missing node ID")
+
+	// ErrMissingDeliverFn is returned when the delivery callback is not set
+	ErrMissingDeliverFn = errors.New("missing deliver function")
+
+	// ErrInvalidFanout is returned when the fanout is negative
+	ErrInvalidFanout = errors.New("fanout must not be negative")
+
+	// ErrInvalidCacheSize is returned when the cache size is negative
+	ErrInvalidCacheSize = errors.New("cache size must not be negative")
+)
+
+// MessageID is the identifier of a message, used for the deduplication
+type MessageID [sha256.Size]byte
+
+// NewMessageID returns the hash of the message payload without the signature, and of the signature.
+// The messages with the same payload but different signatures are different messages
+func NewMessageID(message *proto.Message) (MessageID, error) {
+	payload, err := message.PayloadNoSig()
+	if err != nil {
+		return MessageID{}, err
+	}
+
+	hash := sha256.New()
+
+	hash.Write(payload)
+	hash.Write(message.Signature)
+
+	var id MessageID
+
+	copy(id[:], hash.Sum(nil))
+
+	return id, nil
+}
+
+// Envelope is the message relayed between the peers, along with its remaining hops
+type Envelope struct {
+	// Message is the relayed message
+	Message *proto.Message
+
+	// TTL is the number of hops the message can still travel, including the one it is sent over
+	TTL uint32
+}
+
+// Link is the connection to a peer
+type Link interface {
+	// Send sends the envelope to the peer. It must not block
+	Send(envelope *Envelope)
+}
+
+// Config is the configuration of the gossip node
+type Config struct {
+	// ID is the identifier of the node
+	ID []byte
+
+	// Fanout is the number of peers each message is relayed to.
+	// DefaultFanout is used if it is not set
+	Fanout int
+
+	// TTL is the number of hops the messages multicasted by the node are relayed for.
+	// DefaultTTL is used if it is not set
+	TTL uint32
+
+	// CacheSize is the number of the message IDs remembered for the deduplication.
+	// DefaultCacheSize is used if it is not set
+	CacheSize int
+
+	// Seed is the seed of the random selection of the peers
+	Seed int64
+
+	// DeliverFn delivers the messages to the node, for instance to core.IBFT.AddMessage
+	DeliverFn func(message *proto.Message)
+}
+
+// validate checks the configuration, and sets the defaults
+func (c *Config) validate() error {
+	if len(c.ID) == 0 {
+		return ErrMissingID
+	}
+
+	if c.DeliverFn == nil {
+		return ErrMissingDeliverFn
+	}
+
+	if c.Fanout < 0 {
+		return ErrInvalidFanout
+	}
+
+	if c.CacheSize < 0 {
+		return ErrInvalidCacheSize
+	}
+
+	if c.Fanout == 0 {
+		c.Fanout = DefaultFanout
+	}
+
+	if c.TTL == 0 {
+		c.TTL = DefaultTTL
+	}
+
+	if c.CacheSize == 0 {
+		c.CacheSize = DefaultCacheSize
+	}
+
+	return nil
+}
+
+// Stats are the numbers of the messages passed through the node
+type Stats struct {
+	// Delivered is the number of messages delivered to the node
+	Delivered uint64
+
+	// Duplicates is the number of messages dropped, since they were seen before
+	Duplicates uint64
+
+	// Relayed is the number of messages sent to the peers, per peer
+	Relayed uint64
+
+	// Invalid is the number of messages dropped, since they couldn't be identified
+	Invalid uint64
+}
+
+// Gossip is the gossip node, relaying the messages between its peers
+type Gossip struct {
+	sync.Mutex
+
+	config Config
+	rng    *rand.Rand
+
+	// peers are the links of the peers, keyed by their IDs
+	peers map[string]Link
+
+	// seen are the IDs of the messages seen by the node
+	seen *seenCache
+
+	stats Stats
+}
+
+var _ core.Transport = &Gossip{}
+
+// New creates a new gossip node
+func New(config Config) (*Gossip, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Gossip{
+		config: config,
+		//nolint:gosec
+		rng:   rand.New(rand.NewSource(config.Seed)),
+		peers: make(map[string]Link),
+		seen:  newSeenCache(config.CacheSize),
+	}, nil
+}
+
+// ID returns the identifier of the node
+func (g *Gossip) ID() []byte {
+	return bytes.Clone(g.config.ID)
+}
+
+// AddPeer adds the link to the peer, replacing the previous link of the peer, if any
+func (g *Gossip) AddPeer(id []byte, link Link) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.peers[string(id)] = link
+}
+
+// RemovePeer removes the link to the peer
+func (g *Gossip) RemovePeer(id []byte) {
+	g.Lock()
+	defer g.Unlock()
+
+	delete(g.peers, string(id))
+}
+
+// Multicast delivers the message to the node itself, and relays it to the peers
+func (g *Gossip) Multicast(message *proto.Message) {
+	g.handle(nil, message, g.config.TTL)
+}
+
+// Receive handles the envelope received from the peer. The message is delivered to the node,
+// and relayed to the other peers if its TTL allows it, unless the node has seen it before
+func (g *Gossip) Receive(from []byte, envelope *Envelope) {
+	if envelope == nil || envelope.TTL == 0 {
+		return
+	}
+
+	g.handle(from, envelope.Message, envelope.TTL-1)
+}
+
+// Stats returns the numbers of the messages passed through the node
+func (g *Gossip) Stats() Stats {
+	g.Lock()
+	defer g.Unlock()
+
+	return g.stats
+}
+
+// handle delivers the message, and relays it with the TTL, if the message wasn't seen before.
+// The message is not relayed if its TTL ran out
+func (g *Gossip) handle(from []byte, message *proto.Message, ttl uint32) {
+	if message == nil {
+		return
+	}
+
+	id, err := NewMessageID(message)
+
+	g.Lock()
+
+	if err != nil {
+		g.stats.Invalid++
+		g.Unlock()
+
+		return
+	}
+
+	if !g.seen.add(id) {
+		g.stats.Duplicates++
+		g.Unlock()
+
+		return
+	}
+
+	g.stats.Delivered++
+
+	var targets []Link
+
+	if ttl > 0 {
+		targets = g.selectPeers(from)
+		g.stats.Relayed += uint64(len(targets))
+	}
+
+	g.Unlock()
+
+	g.config.DeliverFn(message)
+
+	for _, link := range targets {
+		link.Send(&Envelope{
+			Message: message,
+			TTL:     ttl,
+		})
+	}
+}
+
+// selectPeers returns the links of the random peers the messages are relayed to,
+// leaving out the peer the message was received from. The caller must hold the lock
+func (g *Gossip) selectPeers(from []byte) []Link {
+	ids := make([]string, 0, len(g.peers))
+
+	for id := range g.peers {
+		if id != string(from) {
+			ids = append(ids, id)
+		}
+	}
+
+	// The peers are sorted first, so the selection only depends on the seed
+	sort.Strings(ids)
+
+	if len(ids) > g.config.Fanout {
+		g.rng.Shuffle(len(ids), func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+		})
+
+		ids = ids[:g.config.Fanout]
+	}
+
+	links := make([]Link, 0, len(ids))
+	for _, id := range ids {
+		links = append(links, g.peers[id])
+	}
+
+	return links
+}
+
+// seenCache remembers the IDs of the latest messages, up to its size
+type seenCache struct {
+	ids map[MessageID]struct{}
+
+	// order are the remembered IDs, from the oldest one at the next index
+	order []MessageID
+	next  int
+}
+
+// newSeenCache creates the cache remembering up to the size of IDs
+func newSeenCache(size int) *seenCache {
+	return &seenCache{
+		ids:   make(map[MessageID]struct{}, size),
+		order: make([]MessageID, 0, size),
+	}
+}
+
+// add remembers the ID, evicting the oldest one if the cache is full.
+// False is returned if the ID is already remembered
+func (c *seenCache) add(id MessageID) bool {
+	if _, ok := c.ids[id]; ok {
+		return false
+	}
+
+	if len(c.order) < cap(c.order) {
+		c.order = append(c.order, id)
+	} else {
+		delete(c.ids, c.order[c.next])
+
+		c.order[c.next] = id
+		c.next = (c.next + 1) % len(c.order)
+	}
+
+	c.ids[id] = struct{}{}
+
+	return true
+}
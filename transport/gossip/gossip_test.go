@@ -0,0 +1,205 @@
+package gossip
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// newMessage builds a PREPARE message of the sender, with the signature
+func newMessage(from string, signature string) *proto.Message {
+	return &proto.Message{
+		View: &proto.View{Height: 1, Round: 0},
+		From: []byte(from),
+		Type: proto.MessageType_PREPARE,
+		Payload: &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: []byte("proposal hash"),
+			},
+		},
+		Signature: []byte(signature),
+	}
+}
+
+// recordingLink records the envelopes sent to the peer
+type recordingLink struct {
+	sync.Mutex
+
+	envelopes []*Envelope
+}
+
+// Send records the envelope
+func (l *recordingLink) Send(envelope *Envelope) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.envelopes = append(l.envelopes, envelope)
+}
+
+// sent returns the recorded envelopes
+func (l *recordingLink) sent() []*Envelope {
+	l.Lock()
+	defer l.Unlock()
+
+	return l.envelopes
+}
+
+func TestNewMessageID(t *testing.T) {
+	t.Parallel()
+
+	id, err := NewMessageID(newMessage("node 0", "signature"))
+	require.NoError(t, err)
+
+	same, err := NewMessageID(newMessage("node 0", "signature"))
+	require.NoError(t, err)
+
+	assert.Equal(t, id, same)
+
+	// Make sure the signature is part of the ID
+	otherSignature, err := NewMessageID(newMessage("node 0", "other signature"))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, id, otherSignature)
+
+	otherSender, err := NewMessageID(newMessage("node 1", "signature"))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, id, otherSender)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	t.Parallel()
+
+	deliverFn := func(_ *proto.Message) {}
+
+	testTable := []struct {
+		name   string
+		config Config
+		err    error
+	}{
+		{"missing ID", Config{DeliverFn: deliverFn}, ErrMissingID},
+		{"missing deliver function", Config{ID: []byte("node 0")}, ErrMissingDeliverFn},
+		{"negative fanout", Config{ID: []byte("node 0"), DeliverFn: deliverFn, Fanout: -1}, ErrInvalidFanout},
+		{"negative cache size", Config{ID: []byte("node 0"), DeliverFn: deliverFn, CacheSize: -1}, ErrInvalidCacheSize},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(testCase.config)
+
+			assert.ErrorIs(t, err, testCase.err)
+		})
+	}
+}
+
+func TestGossip_Relay(t *testing.T) {
+	t.Parallel()
+
+	var (
+		delivered []*proto.Message
+		links     = make([]*recordingLink, 5)
+	)
+
+	g, err := New(Config{
+		ID:     []byte("node 0"),
+		Fanout: 2,
+		TTL:    3,
+		DeliverFn: func(message *proto.Message) {
+			delivered = append(delivered, message)
+		},
+	})
+	require.NoError(t, err)
+
+	for index := range links {
+		links[index] = &recordingLink{}
+
+		g.AddPeer([]byte(fmt.Sprintf("peer %d", index)), links[index])
+	}
+
+	// Make sure the multicasted message is delivered to the node itself,
+	// and relayed to the fanout of peers, with the configured TTL
+	message := newMessage("node 0", "signature")
+
+	g.Multicast(message)
+
+	relayed := 0
+
+	for _, link := range links {
+		for _, envelope := range link.sent() {
+			assert.Equal(t, message, envelope.Message)
+			assert.Equal(t, uint32(3), envelope.TTL)
+
+			relayed++
+		}
+	}
+
+	assert.Equal(t, 2, relayed)
+	assert.Equal(t, []*proto.Message{message}, delivered)
+
+	// Make sure the seen messages are dropped
+	g.Receive([]byte("peer 0"), &Envelope{Message: message, TTL: 3})
+
+	assert.Len(t, delivered, 1)
+
+	// Make sure the messages with the last hop are delivered, but not relayed
+	g.Receive([]byte("peer 0"), &Envelope{Message: newMessage("node 1", "signature"), TTL: 1})
+
+	assert.Len(t, delivered, 2)
+
+	// Make sure the received messages are not relayed back to the sender
+	g.RemovePeer([]byte("peer 2"))
+	g.RemovePeer([]byte("peer 3"))
+	g.RemovePeer([]byte("peer 4"))
+
+	g.Receive([]byte("peer 0"), &Envelope{Message: newMessage("node 2", "signature"), TTL: 2})
+
+	require.Len(t, delivered, 3)
+
+	sent := links[1].sent()
+	require.NotEmpty(t, sent)
+
+	assert.Equal(t, []byte("node 2"), sent[len(sent)-1].Message.From)
+	assert.Equal(t, uint32(1), sent[len(sent)-1].TTL)
+
+	for _, envelope := range links[0].sent() {
+		assert.NotEqual(t, []byte("node 2"), envelope.Message.From)
+	}
+
+	assert.Equal(t, Stats{
+		Delivered:  3,
+		Duplicates: 1,
+		Relayed:    3,
+	}, g.Stats())
+}
+
+func TestSeenCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache = newSeenCache(2)
+		ids   = []MessageID{{1}, {2}, {3}}
+	)
+
+	assert.True(t, cache.add(ids[0]))
+	assert.True(t, cache.add(ids[1]))
+	assert.False(t, cache.add(ids[0]))
+
+	// Make sure the oldest ID is evicted once the cache is full
+	assert.True(t, cache.add(ids[2]))
+	assert.True(t, cache.add(ids[0]))
+	assert.False(t, cache.add(ids[2]))
+}
@@ -0,0 +1,202 @@
+package gossip
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultQueueSize is the default number of the envelopes queued for each node of the network
+const DefaultQueueSize = 1024
+
+var (
+	// ErrUnknownNode is returned when connecting the nodes which are not in the network
+	ErrUnknownNode = errors.New("unknown node")
+
+	// ErrDuplicateNode is returned when adding a node with the ID of another node of the network
+	ErrDuplicateNode = errors.New("duplicate node")
+
+	// ErrNetworkClosed is returned when adding a node to the closed network
+	ErrNetworkClosed = errors.New("network is closed")
+)
+
+// Network is the in-memory link layer, connecting the gossip nodes in an arbitrary topology.
+// The envelopes are delivered asynchronously, in the order they are queued for each node.
+// The envelopes sent to the nodes with full queues are dropped
+type Network struct {
+	sync.Mutex
+
+	queueSize int
+
+	// nodes are the nodes of the network, keyed by their IDs
+	nodes map[string]*networkNode
+
+	closed bool
+	wg     sync.WaitGroup
+
+	// dropped is the number of envelopes dropped, because of the full queues
+	dropped uint64
+}
+
+// networkNode is a node of the network, with its queue of the incoming envelopes
+type networkNode struct {
+	gossip *Gossip
+
+	inbox  chan delivery
+	doneCh chan struct{}
+}
+
+// delivery is an envelope queued for a node
+type delivery struct {
+	from     []byte
+	envelope *Envelope
+}
+
+// NewNetwork creates the network, queueing up to the queue size of envelopes
+// for each node. DefaultQueueSize is used if the queue size is not positive
+func NewNetwork(queueSize int) *Network {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	return &Network{
+		queueSize: queueSize,
+		nodes:     make(map[string]*networkNode),
+	}
+}
+
+// Add adds the node to the network, and starts delivering its envelopes.
+// The node is not connected to any of the other nodes
+func (n *Network) Add(node *Gossip) error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.closed {
+		return ErrNetworkClosed
+	}
+
+	id := string(node.config.ID)
+	if _, ok := n.nodes[id]; ok {
+		return ErrDuplicateNode
+	}
+
+	added := &networkNode{
+		gossip: node,
+		inbox:  make(chan delivery, n.queueSize),
+		doneCh: make(chan struct{}),
+	}
+
+	n.nodes[id] = added
+
+	n.wg.Add(1)
+
+	go n.runDeliveries(added)
+
+	return nil
+}
+
+// Connect connects the nodes in both directions
+func (n *Network) Connect(a, b []byte) error {
+	n.Lock()
+	defer n.Unlock()
+
+	nodeA, okA := n.nodes[string(a)]
+	nodeB, okB := n.nodes[string(b)]
+
+	if !okA || !okB {
+		return ErrUnknownNode
+	}
+
+	nodeA.gossip.AddPeer(b, memoryLink{network: n, from: a, to: b})
+	nodeB.gossip.AddPeer(a, memoryLink{network: n, from: b, to: a})
+
+	return nil
+}
+
+// Disconnect disconnects the nodes in both directions.
+// The envelopes already queued are still delivered
+func (n *Network) Disconnect(a, b []byte) {
+	n.Lock()
+	defer n.Unlock()
+
+	if node, ok := n.nodes[string(a)]; ok {
+		node.gossip.RemovePeer(b)
+	}
+
+	if node, ok := n.nodes[string(b)]; ok {
+		node.gossip.RemovePeer(a)
+	}
+}
+
+// Dropped returns the number of envelopes dropped, because of the full queues
+func (n *Network) Dropped() uint64 {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.dropped
+}
+
+// Close stops the deliveries, and waits for the ongoing ones to finish.
+// The queued envelopes are dropped
+func (n *Network) Close() {
+	n.Lock()
+
+	if n.closed {
+		n.Unlock()
+
+		return
+	}
+
+	n.closed = true
+
+	for _, node := range n.nodes {
+		close(node.doneCh)
+	}
+
+	n.Unlock()
+
+	n.wg.Wait()
+}
+
+// send queues the envelope for the node, or drops it if the queue is full
+func (n *Network) send(from, to []byte, envelope *Envelope) {
+	n.Lock()
+	defer n.Unlock()
+
+	node, ok := n.nodes[string(to)]
+	if !ok || n.closed {
+		return
+	}
+
+	select {
+	case node.inbox <- delivery{from: from, envelope: envelope}:
+	default:
+		n.dropped++
+	}
+}
+
+// runDeliveries passes the queued envelopes to the node, until the network is closed
+func (n *Network) runDeliveries(node *networkNode) {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-node.doneCh:
+			return
+		case queued := <-node.inbox:
+			node.gossip.Receive(queued.from, queued.envelope)
+		}
+	}
+}
+
+// memoryLink is the link between the nodes of the network
+type memoryLink struct {
+	network *Network
+
+	from []byte
+	to   []byte
+}
+
+// Send queues the envelope for the peer
+func (l memoryLink) Send(envelope *Envelope) {
+	l.network.send(l.from, l.to, envelope)
+}
@@ -0,0 +1,231 @@
+package gossip
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hydra-Chain/go-ibft/backend"
+	"github.com/Hydra-Chain/go-ibft/core"
+	"github.com/Hydra-Chain/go-ibft/messages/proto"
+)
+
+// messageCounter counts the messages delivered to each node
+type messageCounter struct {
+	sync.Mutex
+
+	counts map[string]int
+}
+
+// deliverFn returns the delivery callback of the node
+func (c *messageCounter) deliverFn(node string) func(*proto.Message) {
+	return func(_ *proto.Message) {
+		c.Lock()
+		defer c.Unlock()
+
+		c.counts[node]++
+	}
+}
+
+// get returns the number of messages delivered to the node
+func (c *messageCounter) get(node string) int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.counts[node]
+}
+
+// newLine creates the network of the nodes connected in a line, node i to node i+1
+func newLine(t *testing.T, nodes int, ttl uint32, counter *messageCounter) (*Network, []*Gossip) {
+	t.Helper()
+
+	var (
+		network = NewNetwork(0)
+		gossips = make([]*Gossip, 0, nodes)
+	)
+
+	for index := 0; index < nodes; index++ {
+		id := fmt.Sprintf("node %d", index)
+
+		g, err := New(Config{
+			ID:        []byte(id),
+			TTL:       ttl,
+			DeliverFn: counter.deliverFn(id),
+		})
+		require.NoError(t, err)
+		require.NoError(t, network.Add(g))
+
+		if index > 0 {
+			require.NoError(t, network.Connect(gossips[index-1].ID(), g.ID()))
+		}
+
+		gossips = append(gossips, g)
+	}
+
+	return network, gossips
+}
+
+func TestNetwork_Line(t *testing.T) {
+	t.Parallel()
+
+	t.Run("messages reach all nodes once", func(t *testing.T) {
+		t.Parallel()
+
+		counter := &messageCounter{counts: make(map[string]int)}
+
+		network, gossips := newLine(t, 6, DefaultTTL, counter)
+		defer network.Close()
+
+		gossips[0].Multicast(newMessage("node 0", "first"))
+		gossips[5].Multicast(newMessage("node 5", "second"))
+
+		require.Eventually(t, func() bool {
+			for index := range gossips {
+				if counter.get(fmt.Sprintf("node %d", index)) != 2 {
+					return false
+				}
+			}
+
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("messages are not relayed past their TTL", func(t *testing.T) {
+		t.Parallel()
+
+		counter := &messageCounter{counts: make(map[string]int)}
+
+		network, gossips := newLine(t, 5, 3, counter)
+		defer network.Close()
+
+		gossips[0].Multicast(newMessage("node 0", "signature"))
+
+		require.Eventually(t, func() bool {
+			return counter.get("node 3") == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// Give the envelope the time to be relayed further, if it was
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, 0, counter.get("node 4"))
+	})
+
+	t.Run("unknown nodes are not connected", func(t *testing.T) {
+		t.Parallel()
+
+		counter := &messageCounter{counts: make(map[string]int)}
+
+		network, gossips := newLine(t, 1, DefaultTTL, counter)
+		defer network.Close()
+
+		assert.ErrorIs(t, network.Connect(gossips[0].ID(), []byte("node 1")), ErrUnknownNode)
+		assert.ErrorIs(t, network.Add(gossips[0]), ErrDuplicateNode)
+	})
+}
+
+// nopLogger drops the log messages
+type nopLogger struct{}
+
+func (nopLogger) Info(_ string, _ ...any) {}
+
+func (nopLogger) Debug(_ string, _ ...any) {}
+
+func (nopLogger) Error(_ string, _ ...any) {}
+
+func TestNetwork_Cluster(t *testing.T) {
+	t.Parallel()
+
+	const (
+		validators = 5
+		heights    = 2
+	)
+
+	var (
+		keys       = make([]ed25519.PrivateKey, 0, validators)
+		publicKeys = make([]ed25519.PublicKey, 0, validators)
+		network    = NewNetwork(0)
+		nodes      = make([]*core.IBFT, validators)
+		gossips    = make([]*Gossip, 0, validators)
+	)
+
+	defer network.Close()
+
+	for index := 0; index < validators; index++ {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(index + 1)
+
+		key := ed25519.NewKeyFromSeed(seed)
+		publicKey, _ := key.Public().(ed25519.PublicKey)
+
+		keys = append(keys, key)
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	for index, key := range keys {
+		b, err := backend.New(backend.Config{
+			Key:        key,
+			Validators: backend.NewStaticValidators(publicKeys...),
+			Store:      backend.NewMemoryBlockStore(),
+		})
+		require.NoError(t, err)
+
+		index := index
+
+		g, err := New(Config{
+			ID:     b.ID(),
+			Fanout: 2,
+			Seed:   int64(index),
+			DeliverFn: func(message *proto.Message) {
+				nodes[index].AddMessage(message)
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, network.Add(g))
+
+		nodes[index], err = core.NewIBFTWithOptions(nopLogger{}, b, g, core.WithBaseRoundTimeout(time.Second))
+		require.NoError(t, err)
+
+		gossips = append(gossips, g)
+	}
+
+	defer func() {
+		for _, node := range nodes {
+			_ = node.Close()
+		}
+	}()
+
+	// Connect the validators in a ring, so none of them reaches all the others directly
+	for index, g := range gossips {
+		require.NoError(t, network.Connect(g.ID(), gossips[(index+1)%validators].ID()))
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelFn()
+
+	for height := uint64(1); height <= heights; height++ {
+		var wg sync.WaitGroup
+
+		wg.Add(validators)
+
+		for _, node := range nodes {
+			go func(node *core.IBFT) {
+				defer wg.Done()
+
+				_, err := node.RunSequence(ctx, height)
+				assert.NoError(t, err)
+			}(node)
+		}
+
+		wg.Wait()
+	}
+
+	for _, g := range gossips {
+		assert.Positive(t, g.Stats().Relayed)
+	}
+}